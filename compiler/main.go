@@ -1,36 +1,50 @@
 package main
 
 import (
-	"bytes"
 	"context"
+	"flag"
 	"fmt"
-	"github.com/rs/zerolog"
-	"github.com/wavesplatform/gowaves/pkg/client"
-	"io"
-	"net/http"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/waves-exchange/contracts/deployer/pkg/compiler"
+	"github.com/waves-exchange/contracts/deployer/pkg/jsonfile"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/proto"
 )
 
-type WavesClient interface {
-	Do(ctx context.Context, req *http.Request, v interface{}) (*client.Response, error)
-	GetOptions() client.Options
-}
+const defaultCacheDir = "../.cache/ride"
 
-type API struct {
-	WavesClient WavesClient
+type network struct {
+	node     string
+	kind     string
+	scheme   proto.Scheme
+	client   node.Node
+	compiler compiler.Compiler
 }
 
-type CompileResult struct {
-	Script               string
-	Complexity           int
-	VerifierComplexity   int
-	CallableComplexities interface{}
-	ExtraFee             int
+type job struct {
+	file    string
+	network *network
 }
 
 func main() {
+	baselineFile := flag.String("baseline", "baseline.json", "committed reports to compare complexity with")
+	budgetsFile := flag.String("budgets", "budgets.json", "complexity budgets and growth threshold")
+	writeBaseline := flag.Bool("write-baseline", false, "write reports to baseline instead of comparing")
+	requireBaseline := flag.Bool("require-baseline", false, "fail if there is no baseline, reports are still written")
+	reportFile := flag.String("report", "", "write reports as JSON to the file")
+	summaryFile := flag.String("summary", "", "write results with errors as JSON to the file")
+	junitFile := flag.String("junit", "", "write results as JUnit XML to the file")
+	filter := flag.String("filter", "", "comma-separated glob patterns of files to compile, e.g. 'lp*.ride,factory_v2.ride'")
+	parallel := flag.Int("parallel", 8, "max number of concurrent compilations")
+	flag.Parse()
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
 	defer cancel()
 
@@ -42,6 +56,10 @@ func main() {
 		Caller().
 		Logger()
 
+	if *parallel < 1 {
+		panic("parallel must be positive")
+	}
+
 	testnetNode, ok := os.LookupEnv("TESTNETNODE")
 	if !ok {
 		panic("no env TESTNETNODE")
@@ -51,86 +69,273 @@ func main() {
 		panic("no env MAINNETNODE")
 	}
 
-	dir, err := os.ReadDir(path.Join("..", "ride"))
+	files, err := listFiles(path.Join("..", "ride"), *filter)
 	if err != nil {
-		panic(err)
+		panic(fmt.Errorf("listFiles: %w", err))
 	}
+	if len(files) == 0 {
+		panic("no files match filter " + *filter)
+	}
+
+	baseline, err := loadBaseline(*baselineFile)
+	if err != nil {
+		panic(fmt.Errorf("loadBaseline: %w", err))
+	}
+	missingBaseline := len(baseline) == 0 && !*writeBaseline
+	if missingBaseline && !*requireBaseline {
+		log.Warn().Str("baseline", *baselineFile).Msg("no baseline, complexity growth isn't checked")
+	}
+	if *writeBaseline && *filter != "" {
+		panic("baseline can't be written for filtered files")
+	}
+
+	budgets, err := loadBudgets(*budgetsFile)
+	if err != nil {
+		panic(fmt.Errorf("loadBudgets: %w", err))
+	}
+
+	networks := []*network{{
+		node:   testnetNode,
+		kind:   "testnet",
+		scheme: proto.TestNetScheme,
+	}, {
+		node:   mainnetNode,
+		kind:   "mainnet",
+		scheme: proto.MainNetScheme,
+	}}
 
-	type cfg struct {
-		node string
-		kind string
+	var jobs []job
+	for _, n := range networks {
+		n.client, err = node.NewClient(n.node, n.scheme)
+		if err != nil {
+			panic(fmt.Errorf("node.NewClient: %w", err))
+		}
+		n.compiler, err = newCompiler(n.client)
+		if err != nil {
+			panic(fmt.Errorf("newCompiler: %w", err))
+		}
+		for _, file := range files {
+			jobs = append(jobs, job{file: file, network: n})
+		}
 	}
 
+	results := compileAll(ctx, log, jobs, *parallel)
+
 	exitCode := 0
-	for _, _file := range dir {
-		file := _file
-		for _, c := range []cfg{{
-			node: testnetNode,
-			kind: "testnet",
-		}, {
-			node: mainnetNode,
-			kind: "mainnet",
-		}} {
-			_, e := compile(ctx, path.Join("..", "ride", file.Name()), c.node)
+	var reports Baseline
+	for i, res := range results {
+		if res.Report == nil {
+			exitCode = 1
+			continue
+		}
+		reports = append(reports, *res.Report)
+
+		if *writeBaseline {
+			continue
+		}
+		var base *Report
+		if b, ok := baseline.find(res.File, res.Network); ok {
+			base = &b
+		}
+		results[i].Violations = budgets.check(*res.Report, base)
+		for _, violation := range results[i].Violations {
+			log.Error().
+				Str("file", res.File).
+				Str("kind", res.Network).
+				Msg(violation)
+			exitCode = 1
+		}
+	}
+
+	// reports are written in baseline order, so the file may be committed as the baseline
+	if *reportFile != "" {
+		e := reports.save(*reportFile)
+		if e != nil {
+			panic(fmt.Errorf("reports.save: %w", e))
+		}
+	}
+
+	if *summaryFile != "" {
+		e := jsonfile.Save(*summaryFile, results)
+		if e != nil {
+			panic(fmt.Errorf("jsonfile.Save: %w", e))
+		}
+	}
+
+	if *junitFile != "" {
+		e := writeJUnit(*junitFile, results)
+		if e != nil {
+			panic(fmt.Errorf("writeJUnit: %w", e))
+		}
+	}
+
+	if missingBaseline && *requireBaseline {
+		log.Error().Str("baseline", *baselineFile).Str("report", *reportFile).
+			Msg("no baseline, run go run . -write-baseline or commit the report as baseline")
+		exitCode = 1
+	}
+
+	if *writeBaseline {
+		if exitCode != 0 {
+			log.Error().Msg("baseline isn't written, some scripts failed to compile")
+		} else {
+			e := reports.save(*baselineFile)
 			if e != nil {
-				log.Error().
-					Str("file", file.Name()).
-					Str("node", c.node).
-					Str("kind", c.kind).
-					Err(e).
-					Msg("compilation failed")
-				exitCode = 1
+				panic(fmt.Errorf("reports.save: %w", e))
 			}
-			log.Info().
-				Str("file", file.Name()).
-				Str("node", c.node).
-				Str("kind", c.kind).
-				Msg("compiled")
+			log.Info().Str("baseline", *baselineFile).Msg("baseline written")
 		}
 	}
+
 	os.Exit(exitCode)
 }
 
-func NewAPI(wavesClient WavesClient) *API {
-	return &API{
-		WavesClient: wavesClient,
+// listFiles returns names of files in dir matching any of comma-separated patterns, all files if filter is empty
+func listFiles(dir, filter string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadDir: %w", err)
+	}
+
+	var patterns []string
+	for _, p := range strings.Split(filter, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		_, e := filepath.Match(p, "")
+		if e != nil {
+			return nil, fmt.Errorf("filepath.Match: %s: %w", p, e)
+		}
+		patterns = append(patterns, p)
+	}
+
+	var res []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if len(patterns) == 0 {
+			res = append(res, entry.Name())
+			continue
+		}
+		for _, p := range patterns {
+			if ok, _ := filepath.Match(p, entry.Name()); ok {
+				res = append(res, entry.Name())
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+// compileAll compiles jobs by at most parallel workers, results are in the order of jobs
+func compileAll(ctx context.Context, log zerolog.Logger, jobs []job, parallel int) []Result {
+	results := make([]Result, len(jobs))
+
+	indexes := make(chan int)
+	wg := &sync.WaitGroup{}
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = compileJob(ctx, log, jobs[i])
+			}
+		}()
 	}
+
+	for i := range jobs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
 }
 
-func compile(ctx context.Context, path, node string) (string, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return "", fmt.Errorf("os.Open: %w", err)
+func compileJob(ctx context.Context, log zerolog.Logger, j job) Result {
+	res := Result{
+		File:    j.file,
+		Network: j.network.kind,
 	}
 
-	body, err := io.ReadAll(f)
+	start := time.Now()
+	compiled, err := compile(ctx, j.network.compiler, path.Join("..", "ride", j.file))
+	res.Seconds = time.Since(start).Seconds()
 	if err != nil {
-		return "", fmt.Errorf("io.ReadAll: %w", err)
+		res.Error = redact(err.Error(), j.network)
+		log.Error().
+			Str("file", j.file).
+			Str("kind", j.network.kind).
+			Str("error", res.Error).
+			Msg("compilation failed")
+		return res
 	}
 
-	wavesClient, err := client.NewClient(
-		client.Options{BaseUrl: node, Client: &http.Client{Timeout: time.Minute}})
+	report, err := newReport(ctx, j.network.client, j.file, j.network.kind, compiled)
 	if err != nil {
-		return "", err
+		res.Error = redact(fmt.Errorf("newReport: %w", err).Error(), j.network)
+		log.Error().
+			Str("file", j.file).
+			Str("kind", j.network.kind).
+			Str("error", res.Error).
+			Msg("report failed")
+		return res
 	}
+	res.Report = &report
 
-	wavesApi := NewAPI(wavesClient)
+	log.Info().
+		Str("file", j.file).
+		Str("kind", j.network.kind).
+		Int("complexity", report.Complexity).
+		Int("verifierComplexity", report.VerifierComplexity).
+		Interface("callableComplexities", report.CallableComplexities).
+		Int("scriptSize", report.ScriptSize).
+		Uint64("setScriptFee", report.SetScriptFee).
+		Msg("compiled")
+	return res
+}
+
+// newCompiler uses node compilation by default,
+// set COMPILER=local, COMPILERCOMMAND and COMPILERVERSION to compile offline.
+// Results are cached in COMPILERCACHEDIR shared with deployer, empty disables cache
+func newCompiler(nd node.Node) (compiler.Compiler, error) {
+	backend := compiler.BackendNode
+	if b, ok := os.LookupEnv("COMPILER"); ok {
+		backend = compiler.Backend(b)
+	}
 
-	u := fmt.Sprintf("%s/utils/script/compileCode?compact=true", wavesApi.WavesClient.GetOptions().BaseUrl)
+	cacheDir, ok := os.LookupEnv("COMPILERCACHEDIR")
+	if !ok {
+		cacheDir = defaultCacheDir
+	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	comp, err := compiler.New(compiler.Options{
+		Backend:    backend,
+		Node:       nd,
+		Command:    os.Getenv("COMPILERCOMMAND"),
+		Version:    os.Getenv("COMPILERVERSION"),
+		CacheDir:   cacheDir,
+		DriftCheck: os.Getenv("COMPILERDRIFTCHECK") == "true",
+		Scheme:     nd.Scheme(),
+	})
 	if err != nil {
-		return "", fmt.Errorf("http.NewRequestWithContext: %w", err)
+		return nil, fmt.Errorf("compiler.New: %w", err)
 	}
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "text/plain")
+	return comp, nil
+}
 
-	var compileResult CompileResult
-	_, err = wavesApi.WavesClient.Do(ctx, req, &compileResult)
+func compile(ctx context.Context, comp compiler.Compiler, path string) (node.CompileResult, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return node.CompileResult{}, fmt.Errorf("os.ReadFile: %w", err)
+	}
 
+	compileResult, err := comp.Compile(ctx, body, true)
 	if err != nil {
-		return "", fmt.Errorf("a.WavesClient.Do: %w", err)
+		return node.CompileResult{}, fmt.Errorf("comp.Compile: %w", err)
 	}
 
-	return compileResult.Script, nil
+	return compileResult, nil
 }
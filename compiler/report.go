@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/fee"
+	"github.com/waves-exchange/contracts/deployer/pkg/jsonfile"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/tools"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Report is compilation summary of one .ride file on one network
+type Report struct {
+	File                 string         `json:"file"`
+	Network              string         `json:"network"`
+	Complexity           int            `json:"complexity"`
+	VerifierComplexity   int            `json:"verifierComplexity"`
+	CallableComplexities map[string]int `json:"callableComplexities"`
+	ScriptSize           int            `json:"scriptSize"`
+	SetScriptFee         uint64         `json:"setScriptFee"`
+}
+
+func newReport(ctx context.Context, nd node.Node, file, network string, res node.CompileResult) (Report, error) {
+	script, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(res.Script, "base64:"))
+	if err != nil {
+		return Report{}, fmt.Errorf("base64.StdEncoding.DecodeString: %w", err)
+	}
+
+	// the fee is estimated by the node for an account without script, the same way syncer does before deploy
+	tx := proto.NewUnsignedSetScriptWithProofs(2, crypto.PublicKey{}, script, 0, tools.Timestamp())
+	err = fee.Set(ctx, nd, tx)
+	if err != nil {
+		return Report{}, fmt.Errorf("fee.Set: %w", err)
+	}
+
+	return Report{
+		File:                 file,
+		Network:              network,
+		Complexity:           res.Complexity,
+		VerifierComplexity:   res.VerifierComplexity,
+		CallableComplexities: res.CallableComplexities,
+		ScriptSize:           len(script),
+		SetScriptFee:         tx.Fee,
+	}, nil
+}
+
+// Baseline is committed list of reports, new reports are compared with it
+type Baseline []Report
+
+func loadBaseline(name string) (Baseline, error) {
+	var b Baseline
+	err := jsonfile.Load(name, &b)
+	if err != nil {
+		return nil, fmt.Errorf("jsonfile.Load: %w", err)
+	}
+	return b, nil
+}
+
+// save sorts reports by network and file, so baseline diff shows only changed scripts
+func (b Baseline) save(name string) error {
+	sort.Slice(b, func(i, j int) bool {
+		if b[i].Network != b[j].Network {
+			return b[i].Network < b[j].Network
+		}
+		return b[i].File < b[j].File
+	})
+
+	err := jsonfile.Save(name, b)
+	if err != nil {
+		return fmt.Errorf("jsonfile.Save: %w", err)
+	}
+	return nil
+}
+
+func (b Baseline) find(file, network string) (Report, bool) {
+	for _, r := range b {
+		if r.File == file && r.Network == network {
+			return r, true
+		}
+	}
+	return Report{}, false
+}
+
+// verifierKey is the name verifier complexity is checked by in budgets
+const verifierKey = "@verifier"
+
+// Budgets limit complexity of callables and verifiers
+type Budgets struct {
+	// Threshold is max allowed complexity growth compared to baseline, in percent
+	Threshold float64 `json:"threshold"`
+	// Default is the budget of callables and verifiers not listed in Files
+	Default int `json:"default"`
+	// Files maps .ride file to budgets of its callables, '*' is the budget of callables not listed,
+	// '@verifier' is the budget of verifier
+	Files map[string]map[string]int `json:"files"`
+}
+
+func loadBudgets(name string) (Budgets, error) {
+	var b Budgets
+	err := jsonfile.Load(name, &b)
+	if err != nil {
+		return Budgets{}, fmt.Errorf("jsonfile.Load: %w", err)
+	}
+	return b, nil
+}
+
+func (b Budgets) budget(file, name string) int {
+	if budget, ok := b.Files[file][name]; ok {
+		return budget
+	}
+	if budget, ok := b.Files[file]["*"]; ok && name != verifierKey {
+		return budget
+	}
+	return b.Default
+}
+
+// check returns violations of budgets and of growth threshold compared to base,
+// base is nil if the file isn't in baseline yet
+func (b Budgets) check(r Report, base *Report) []string {
+	complexities := map[string]int{verifierKey: r.VerifierComplexity}
+	for name, complexity := range r.CallableComplexities {
+		complexities[name] = complexity
+	}
+
+	names := make([]string, 0, len(complexities))
+	for name := range complexities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var res []string
+	for _, name := range names {
+		complexity := complexities[name]
+
+		budget := b.budget(r.File, name)
+		if budget > 0 && complexity > budget {
+			res = append(res, fmt.Sprintf("%s: complexity %d exceeds budget %d", name, complexity, budget))
+		}
+
+		if base == nil {
+			continue
+		}
+		prev, ok := base.CallableComplexities[name]
+		if name == verifierKey {
+			prev, ok = base.VerifierComplexity, true
+		}
+		if !ok || prev == 0 {
+			continue
+		}
+		growth := float64(complexity-prev) * 100 / float64(prev)
+		if growth > b.Threshold {
+			res = append(res, fmt.Sprintf(
+				"%s: complexity grew from %d to %d (+%.1f%%), threshold is %.1f%%",
+				name, prev, complexity, growth, b.Threshold,
+			))
+		}
+	}
+	return res
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Result is outcome of compiling one file on one network
+type Result struct {
+	File    string  `json:"file"`
+	Network string  `json:"network"`
+	Seconds float64 `json:"seconds"`
+	// Error is compilation error, node error message included, node url is replaced with the network name
+	Error string `json:"error,omitempty"`
+	// Violations are exceeded budgets and growth thresholds
+	Violations []string `json:"violations,omitempty"`
+	Report     *Report  `json:"report,omitempty"`
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     float64         `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes results as JUnit XML, a suite per network and a case per file.
+// Compilation errors are reported as errors, exceeded budgets as failures
+func writeJUnit(name string, results []Result) error {
+	suites := junitTestSuites{}
+	index := map[string]int{}
+	for _, res := range results {
+		i, ok := index[res.Network]
+		if !ok {
+			i = len(suites.Suites)
+			index[res.Network] = i
+			suites.Suites = append(suites.Suites, junitTestSuite{Name: res.Network})
+		}
+		suite := &suites.Suites[i]
+
+		tc := junitTestCase{
+			Name:      res.File,
+			ClassName: res.Network,
+			Time:      res.Seconds,
+		}
+		if res.Error != "" {
+			tc.Error = &junitFailure{
+				Message: "compilation failed on " + res.Network + " node",
+				Text:    res.Error,
+			}
+			suite.Errors++
+		} else if len(res.Violations) > 0 {
+			tc.Failure = &junitFailure{
+				Message: "complexity check failed",
+				Text:    strings.Join(res.Violations, "\n"),
+			}
+			suite.Failures++
+		}
+
+		suite.Tests++
+		suite.Time += res.Seconds
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	b, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("xml.MarshalIndent: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(name), 0700)
+	if err != nil {
+		return fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	err = os.WriteFile(name, append([]byte(xml.Header), b...), 0600)
+	if err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+	return nil
+}
+
+// redact replaces node urls and hosts in msg, they are secrets and results are uploaded as artifacts
+func redact(msg string, n *network) string {
+	for _, raw := range strings.Split(n.node, ",") {
+		raw = strings.TrimSuffix(strings.TrimSpace(raw), "/")
+		if raw == "" {
+			continue
+		}
+		placeholder := "<" + n.kind + " node>"
+		msg = strings.ReplaceAll(msg, raw, placeholder)
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			msg = strings.ReplaceAll(msg, u.Host, placeholder)
+		}
+	}
+	return msg
+}
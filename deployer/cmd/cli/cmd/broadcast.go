@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"github.com/waves-exchange/contracts/deployer/pkg/batch"
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/verifier"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+var (
+	broadcastNode   string
+	broadcastStatus bool
+)
+
+var broadcastCmd = &cobra.Command{
+	Use:   "broadcast <batch.json>",
+	Short: "Broadcast signed batch in order if proofs of all transactions are complete",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		b, err := batch.Load(args[0])
+		if err != nil {
+			printAndExit(err)
+		}
+
+		nodeURL := broadcastNode
+		if nodeURL == "" {
+			nodeURL = defaultNodeURL(b.ChainID)
+		}
+		cl, err := newNodeClient(nodeURL, b.ChainID)
+		if err != nil {
+			printAndExit(err)
+		}
+
+		statuses, err := b.Status(ctx, cl)
+		if err != nil {
+			printAndExit(err)
+		}
+
+		complete := true
+		for i, st := range statuses {
+			state := fmt.Sprintf("%d of %d proofs (%s)", st.Collected, st.Requirement.Threshold, st.Requirement.Kind)
+			switch {
+			case st.Applied:
+				state = "already applied"
+			case st.Requirement.Kind == verifier.KindApprovedTxID && !st.Complete:
+				state = "waiting for admins to approve tx id at " + st.Requirement.Vault
+			case st.Requirement.Kind == verifier.KindApprovedTxID:
+				state = "tx id approved by admins"
+			}
+			fmt.Printf("%d. %s %s %s: %s\n", i+1, st.Tag, st.ID, st.Address, state)
+			complete = complete && st.Complete
+		}
+
+		if broadcastStatus {
+			return
+		}
+		if !complete {
+			printAndExit(fmt.Errorf("proofs are not complete, nothing is broadcast"))
+		}
+		printSimulations(ctx, b, cl)
+
+		network := config.Testnet
+		if b.ChainID == proto.MainNetScheme {
+			network = config.Mainnet
+		}
+		st, err := openStorage(ctx, network)
+		if err != nil {
+			printAndExit(err)
+		}
+
+		confirmP := promptui.Prompt{
+			Label:     fmt.Sprintf("Broadcast %d transactions to %s", len(b.Items), nodeURL),
+			IsConfirm: true,
+		}
+		_, err = confirmP.Run()
+		if err != nil {
+			printAndExit(err)
+		}
+
+		err = b.Broadcast(ctx, auditNode(cl, st, network), confirmOptions(), nil)
+		if err != nil {
+			printAndExit(err)
+		}
+		fmt.Println("batch broadcast")
+	},
+}
+
+func init() {
+	broadcastCmd.Flags().StringVar(&broadcastNode, "node", "", "node url, public node of batch network by default")
+	broadcastCmd.Flags().BoolVar(&broadcastStatus, "status", false, "only show collected proofs")
+	rootCmd.AddCommand(broadcastCmd)
+}
+
+// printSimulations shows expected state changes of pending invokes before confirmation
+func printSimulations(ctx context.Context, b *batch.Batch, nd node.Node) {
+	sims, err := b.Simulate(ctx, nd)
+	if err != nil {
+		printAndExit(err)
+	}
+	for _, s := range sims {
+		fmt.Printf("%s %s evaluated on current state:\n", s.Tag, s.ID)
+		if s.Error != "" {
+			fmt.Printf("   %s, it fails on broadcast unless previous transactions fix it\n", s.Error)
+			continue
+		}
+		fmt.Printf("   %s\n", strings.ReplaceAll(s.Description, "\n", "\n   "))
+	}
+}
+
+func defaultNodeURL(scheme proto.Scheme) string {
+	if scheme == proto.MainNetScheme {
+		return "https://nodes.wx.network"
+	}
+	return "https://nodes-testnet.wx.network"
+}
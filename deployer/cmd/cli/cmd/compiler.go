@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/compiler"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+)
+
+var (
+	compilerBackend    string
+	compilerCommand    string
+	compilerVersion    string
+	compilerCacheDir   string
+	compilerDriftCheck bool
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&compilerBackend, "compiler", string(compiler.BackendNode), "compiler backend: node or local")
+	rootCmd.PersistentFlags().StringVar(&compilerCommand, "compiler-command", "", "local compiler command, {compact} is replaced with true or false")
+	rootCmd.PersistentFlags().StringVar(&compilerVersion, "compiler-version", "", "pinned version of local compiler")
+	rootCmd.PersistentFlags().StringVar(&compilerCacheDir, "compiler-cache-dir", "../.cache/ride", "folder of compilation cache, no cache if empty")
+	rootCmd.PersistentFlags().BoolVar(&compilerDriftCheck, "compiler-drift-check", false, "compare local compilation with node one")
+}
+
+func newCompiler(nd node.Node) (compiler.Compiler, error) {
+	comp, err := compiler.New(compiler.Options{
+		Backend:    compiler.Backend(compilerBackend),
+		Node:       nd,
+		Command:    compilerCommand,
+		Version:    compilerVersion,
+		CacheDir:   compilerCacheDir,
+		DriftCheck: compilerDriftCheck,
+		Scheme:     nd.Scheme(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("compiler.New: %w", err)
+	}
+	return comp, nil
+}
@@ -4,22 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/http"
 	"os"
 	"strconv"
-	"time"
 
 	"github.com/manifoldco/promptui"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
-	"github.com/waves-exchange/contracts/deployer/pkg/branch"
-	"github.com/waves-exchange/contracts/deployer/pkg/contract"
-	"github.com/waves-exchange/contracts/deployer/pkg/mongo"
+	"github.com/waves-exchange/contracts/deployer/pkg/audit"
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/datawriter"
+	"github.com/waves-exchange/contracts/deployer/pkg/fee"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
 	"github.com/waves-exchange/contracts/deployer/pkg/tools"
-	"github.com/wavesplatform/gowaves/pkg/client"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
 var log zerolog.Logger
@@ -31,28 +30,16 @@ var dropStageCmd = &cobra.Command{
 		ctx := context.Background()
 
 		const (
-			defiConfig = "defi_config"
-			branches   = "branches"
-			contracts  = "contracts"
-			node       = "https://nodes-testnet.wx.network"
+			nodeURL = "https://nodes-testnet.wx.network"
 		)
 
 		log = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).Level(zerolog.InfoLevel).With().Timestamp().Caller().Logger()
 
-		mongouriP := promptui.Prompt{
-			Label:       "Mongo uri ?",
-			HideEntered: true,
-		}
-		mongouri, err := mongouriP.Run()
-		if err != nil {
-			printAndExit(err)
-		}
-
-		db, err := mongo.NewConn(ctx, defiConfig, mongouri)
+		st, err := openStorage(ctx, config.Testnet)
 		if err != nil {
 			printAndExit(err)
 		}
-		branchModel := branch.NewModel(db.Collection(branches))
+		branchModel := st.Branches
 
 		stageP := promptui.Prompt{
 			Label: "Index of the stage ?",
@@ -90,28 +77,19 @@ var dropStageCmd = &cobra.Command{
 			printAndExit(err)
 		}
 
-		cl, err := client.NewClient(client.Options{
-			BaseUrl: node,
-			Client:  &http.Client{Timeout: time.Minute},
-			ChainID: proto.TestNetScheme,
-		})
+		client, err := newNodeClient(nodeURL, proto.TestNetScheme)
 		if err != nil {
 			printAndExit(err)
 		}
+		cl := auditNode(client, st, config.Testnet)
 
-		stageFilter := bson.D{{Key: "stage", Value: stageInt}}
-		contractCursor, err := db.Collection(contracts).Find(ctx, stageFilter)
+		stageContracts, err := st.Contracts.GetStage(ctx, uint32(stageInt))
 		if err != nil {
 			printAndExit(err)
 		}
-		for contractCursor.Next(ctx) {
-			var res contract.Contract
-			e := contractCursor.Decode(&res)
-			if e != nil {
-				printAndExit(e)
-			}
-
-			e = dropContract(res.SignerPrv, res.BasePub, ctx, cl)
+		for _, res := range stageContracts {
+			ctx := audit.WithSource(ctx, res.File, res.Tag)
+			e := dropContract(res.SignerPrv, res.BasePub, ctx, cl)
 			if e != nil {
 				printAndExit(fmt.Errorf("dropContract: %s", e))
 			}
@@ -121,14 +99,19 @@ var dropStageCmd = &cobra.Command{
 			}
 		}
 
-		_, err = db.Collection(branches).DeleteMany(ctx, stageFilter)
+		err = branchModel.DeleteStage(ctx, uint32(stageInt))
+		if err != nil {
+			printAndExit(fmt.Errorf("branchModel.DeleteStage: %s", err))
+		}
+
+		err = st.Contracts.DeleteStage(ctx, uint32(stageInt))
 		if err != nil {
-			printAndExit(fmt.Errorf("branches.DeleteMany: %s", err))
+			printAndExit(fmt.Errorf("st.Contracts.DeleteStage: %s", err))
 		}
 
-		_, err = db.Collection(contracts).DeleteMany(ctx, stageFilter)
+		err = st.Journal.DeleteStage(ctx, uint32(stageInt))
 		if err != nil {
-			printAndExit(fmt.Errorf("contracts.DeleteMany: %s", err))
+			printAndExit(fmt.Errorf("st.Journal.DeleteStage: %s", err))
 		}
 		log.Info().Str("stage", stageStr).Msg("Stage dropped")
 	},
@@ -155,17 +138,17 @@ func getKeysFromBase58String(privateKeyBase58 string, publicKeyBase58 string) (c
 	return secretKey, publicKey, address, nil
 }
 
-func dropContract(privateKeyBase58 string, publicKeyBase58 string, ctx context.Context, cl *client.Client) error {
+func dropContract(privateKeyBase58 string, publicKeyBase58 string, ctx context.Context, cl node.Node) error {
 	secretKey, publicKey, address, err := getKeysFromBase58String(privateKeyBase58, publicKeyBase58)
 	if err != nil {
 		return fmt.Errorf("getKeysFromBase58String: %s", err)
 	}
 
-	script, _, err := cl.Addresses.ScriptInfo(ctx, address)
+	script, err := cl.Script(ctx, address)
 	if err != nil {
-		return fmt.Errorf("cl.Addresses.ScriptInfo: %s", err)
+		return fmt.Errorf("cl.Script: %s", err)
 	}
-	if script.Script == "" {
+	if script == "" {
 		log.Info().Str("address", address.String()).Msg("Empty script")
 		return nil
 	}
@@ -174,11 +157,15 @@ func dropContract(privateKeyBase58 string, publicKeyBase58 string, ctx context.C
 		2,
 		publicKey,
 		nil,
-		500000,
+		0,
 		tools.Timestamp(),
 	)
+	err = fee.Set(ctx, cl, dropScriptTx)
+	if err != nil {
+		return fmt.Errorf("fee.Set: %s", err)
+	}
 
-	err = tools.SignBroadcastWait(ctx, proto.TestNetScheme, cl, dropScriptTx, secretKey)
+	err = tools.SignBroadcastWait(ctx, proto.TestNetScheme, cl, dropScriptTx, signer.NewKey(secretKey), confirmOptions())
 	if err != nil {
 		return fmt.Errorf("tools.SignBroadcastWait: %s", err)
 	}
@@ -187,47 +174,29 @@ func dropContract(privateKeyBase58 string, publicKeyBase58 string, ctx context.C
 	return nil
 }
 
-func dropDataState(privateKeyBase58 string, publicKeyBase58 string, ctx context.Context, cl *client.Client) error {
+func dropDataState(privateKeyBase58 string, publicKeyBase58 string, ctx context.Context, cl node.Node) error {
 	secretKey, publicKey, address, err := getKeysFromBase58String(privateKeyBase58, publicKeyBase58)
 	if err != nil {
 		return fmt.Errorf("getKeysFromBase58String: %s", err)
 	}
-	dState, _, err := cl.Addresses.AddressesData(ctx, address)
+	dState, err := cl.Data(ctx, address)
 	if err != nil {
-		return fmt.Errorf("cl.Addresses.AddressesData: %s", err)
+		return fmt.Errorf("cl.Data: %s", err)
 	}
 	if len(dState) == 0 {
 		log.Info().Str("address", address.String()).Msg("Data state is empty")
 		return nil
 	}
 
-	dividedState := []proto.DataEntries{}
-	chunkSize := 100
-	for i := 0; i < len(dState); i += chunkSize {
-		end := i + chunkSize
-		if end > len(dState) {
-			end = len(dState)
-		}
-		dividedState = append(dividedState, dState[i:end])
+	deletes := make(proto.DataEntries, 0, len(dState))
+	for _, row := range dState {
+		deletes = append(deletes, &proto.DeleteDataEntry{Key: row.GetKey()})
 	}
-	for _, chunk := range dividedState {
-		dataTx := proto.NewUnsignedDataWithProofs(
-			2,
-			publicKey,
-			500000,
-			tools.Timestamp(),
-		)
-		for _, row := range chunk {
-			dataTx.AppendEntry(
-				&proto.DeleteDataEntry{
-					Key: row.GetKey(),
-				},
-			)
-		}
-		e := tools.SignBroadcastWait(ctx, proto.TestNetScheme, cl, dataTx, secretKey)
-		if e != nil {
-			return fmt.Errorf("tools.SignBroadcastWait: %s", e)
-		}
+	err = datawriter.Write(ctx, cl, publicKey, deletes, func(ctx context.Context, tx *proto.DataWithProofs) error {
+		return tools.SignBroadcastWait(ctx, proto.TestNetScheme, cl, tx, signer.NewKey(secretKey), confirmOptions())
+	})
+	if err != nil {
+		return fmt.Errorf("datawriter.Write: %s", err)
 	}
 	log.Info().Str("address", address.String()).Msg("Data state cleared")
 	return nil
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/waves-exchange/contracts/deployer/pkg/audit"
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+)
+
+var (
+	historyNetwork string
+	historyTag     string
+	historyAddress string
+	historyLimit   int
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show transactions broadcast by the deployer from audit log",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		network := config.Network(historyNetwork)
+		if _, err := network.Scheme(); err != nil {
+			printAndExit(err)
+		}
+
+		st, err := openStorage(ctx, network)
+		if err != nil {
+			printAndExit(err)
+		}
+		records, err := st.Audit.Find(ctx, audit.Filter{
+			Network: network,
+			Tag:     historyTag,
+			Address: historyAddress,
+			Limit:   historyLimit,
+		})
+		if err != nil {
+			printAndExit(err)
+		}
+
+		for _, r := range records {
+			height := "unconfirmed"
+			if r.Confirmed {
+				height = fmt.Sprintf("height %d", r.Height)
+			}
+			target := r.Sender
+			if r.DApp != "" {
+				target += " -> " + r.DApp
+			}
+			source := r.Tag
+			if r.File != "" {
+				source += " " + r.File
+			}
+			revision := r.Branch
+			if r.Commit != "" {
+				revision += "@" + r.Commit
+			}
+			fmt.Printf("%s %s %-14s %s %s fee %d %s %s\n",
+				r.BroadcastAt.Format(time.RFC3339), r.ID, r.Type, source, target, r.Fee, height, revision)
+		}
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyNetwork, "network", string(config.Testnet), "mainnet or testnet")
+	historyCmd.Flags().StringVar(&historyTag, "tag", "", "only transactions of the contract tag")
+	historyCmd.Flags().StringVar(&historyAddress, "address", "", "only transactions sent by or invoking the address")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 50, "number of latest transactions, 0 for all")
+	rootCmd.AddCommand(historyCmd)
+}
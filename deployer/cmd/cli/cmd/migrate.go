@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"github.com/waves-exchange/contracts/deployer/pkg/batch"
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/migration"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/storage"
+	"github.com/waves-exchange/contracts/deployer/pkg/tools"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+)
+
+var (
+	migrateNetwork string
+	migrateDir     string
+	migrateNode    string
+	migrateOut     string
+	migrateBatch   string
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply migrations/<name> transactions in order and track applied ones in storage",
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show applied and pending migrations of the network",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		folders, applied, _ := loadMigrations(ctx)
+		for _, f := range folders {
+			state := "pending"
+			if m, ok := applied[f.Name]; ok && m.Partial {
+				state = fmt.Sprintf("interrupted, %d txs confirmed, apply again to continue", len(m.TxIDs))
+			} else if ok {
+				state = fmt.Sprintf("applied %s, %d txs", m.AppliedAt.Format(time.RFC3339), len(m.TxIDs))
+			} else if f.Script {
+				state = "script, not supported"
+			} else if _, err := f.Batch(tools.Timestamp()); err != nil {
+				state = "invalid: " + err.Error()
+			}
+			fmt.Printf("%-60s %s\n", f.Name, state)
+		}
+	},
+}
+
+var migratePlanCmd = &cobra.Command{
+	Use:   "plan [name]",
+	Short: "Validate and print transactions of pending migrations, or of the given one",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		folders, applied, _ := loadMigrations(ctx)
+		if len(args) == 1 {
+			f, err := migration.Find(folders, args[0])
+			if err != nil {
+				printAndExit(err)
+			}
+			folders = []migration.Folder{f}
+		} else if migrateOut != "" {
+			printAndExit(errors.New("--out requires migration name"))
+		}
+
+		for _, f := range folders {
+			if m, ok := applied[f.Name]; (ok && !m.Partial) || f.Script {
+				continue
+			}
+			fmt.Printf("%s:\n", f.Name)
+			b, err := f.Batch(tools.Timestamp())
+			if err != nil {
+				fmt.Printf("   invalid: %s\n", err)
+				continue
+			}
+			printBatch(b)
+
+			if migrateOut != "" {
+				err = b.Save(migrateOut)
+				if err != nil {
+					printAndExit(err)
+				}
+				fmt.Printf("batch saved to %s, sign it with 'cli sign-batch %s' and apply with "+
+					"'cli migrate apply %s --batch %s'\n", migrateOut, migrateOut, f.Name, migrateOut)
+			}
+		}
+	},
+}
+
+var migrateApplyCmd = &cobra.Command{
+	Use:   "apply <name>",
+	Short: "Broadcast signed migration transactions in order and record every confirmed one, interrupted apply continues",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		folders, applied, st := loadMigrations(ctx)
+		f, err := migration.Find(folders, args[0])
+		if err != nil {
+			printAndExit(err)
+		}
+		m, ok := applied[f.Name]
+		if ok && !m.Partial {
+			printAndExit(fmt.Errorf("migration %s is already applied to %s", f.Name, migrateNetwork))
+		}
+		if !ok {
+			m = migration.Migration{Name: f.Name, Network: config.Network(migrateNetwork), Partial: true}
+		}
+
+		b, err := f.Batch(tools.Timestamp())
+		if err != nil {
+			printAndExit(err)
+		}
+		if migrateBatch != "" {
+			signed, e := batch.Load(migrateBatch)
+			if e != nil {
+				printAndExit(e)
+			}
+			e = sameMigration(b, signed)
+			if e != nil {
+				printAndExit(fmt.Errorf("%s is not a batch of %s: %w", migrateBatch, f.Name, e))
+			}
+			b = signed
+		}
+		// transactions confirmed by interrupted run are recorded, batch of the folder has other timestamps and ids
+		if len(m.TxIDs) > 0 {
+			fmt.Printf("%d transactions are confirmed by interrupted run, skip them\n", len(m.TxIDs))
+			b.Items = b.Items[len(m.TxIDs):]
+		}
+
+		cl := migrateClient()
+		statuses, err := b.Status(ctx, cl)
+		if err != nil {
+			printAndExit(err)
+		}
+		for i, s := range statuses {
+			fmt.Printf("%d. %s %s %s: %d of %d proofs (%s)\n",
+				i+1, s.Tag, s.ID, s.Address, s.Collected, s.Requirement.Threshold, s.Requirement.Kind)
+			if !s.Complete {
+				printAndExit(fmt.Errorf("proofs are not complete, create batch with 'cli migrate plan %s --out batch.json', "+
+					"sign it with 'cli sign-batch batch.json' and apply with --batch batch.json", f.Name))
+			}
+		}
+
+		printSimulations(ctx, b, cl)
+
+		confirmP := promptui.Prompt{
+			Label:     fmt.Sprintf("Apply %s: broadcast %d transactions to %s", f.Name, len(b.Items), cl.BaseURL()),
+			IsConfirm: true,
+		}
+		_, err = confirmP.Run()
+		if err != nil {
+			printAndExit(err)
+		}
+
+		// every confirmed transaction is recorded, so a rerun continues from the next one
+		err = b.Broadcast(ctx, auditNode(cl, st, config.Network(migrateNetwork)), confirmOptions(), func(i int) error {
+			id, e := crypto.NewDigestFromBase58(b.Items[i].ID)
+			if e != nil {
+				return fmt.Errorf("crypto.NewDigestFromBase58: %w", e)
+			}
+			info, e := cl.TransactionInfo(ctx, id)
+			if e != nil {
+				return fmt.Errorf("cl.TransactionInfo: %w", e)
+			}
+			m.TxIDs = append(m.TxIDs, b.Items[i].ID)
+			m.Heights = append(m.Heights, uint64(info.GetHeight()))
+			m.AppliedAt = time.Now().UTC()
+			return st.Migrations.Save(ctx, m)
+		})
+		if err != nil {
+			printAndExit(err)
+		}
+
+		m.Partial = false
+		m.AppliedAt = time.Now().UTC()
+		err = st.Migrations.Save(ctx, m)
+		if err != nil {
+			printAndExit(err)
+		}
+		fmt.Printf("migration %s applied\n", f.Name)
+	},
+}
+
+var migrateVerifyCmd = &cobra.Command{
+	Use:   "verify [name]",
+	Short: "Check that on-chain state matches what applied migrations set",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		folders, applied, _ := loadMigrations(ctx)
+		if len(args) == 1 {
+			f, err := migration.Find(folders, args[0])
+			if err != nil {
+				printAndExit(err)
+			}
+			if m, ok := applied[f.Name]; !ok || m.Partial {
+				printAndExit(fmt.Errorf("migration %s isn't applied to %s", f.Name, migrateNetwork))
+			}
+			folders = []migration.Folder{f}
+		}
+
+		cl := migrateClient()
+		mismatches := 0
+		for _, f := range folders {
+			m, ok := applied[f.Name]
+			if !ok || m.Partial {
+				continue
+			}
+			b, err := f.Batch(tools.Timestamp())
+			if err != nil {
+				printAndExit(err)
+			}
+			checks, err := migration.Verify(ctx, cl, b, m)
+			if err != nil {
+				printAndExit(err)
+			}
+
+			fmt.Printf("%s:\n", f.Name)
+			for _, c := range checks {
+				if c.OK {
+					fmt.Printf("   ok       %s %s %s\n", c.Address, c.Subject, c.Actual)
+					continue
+				}
+				mismatches++
+				fmt.Printf("   mismatch %s %s: expected %s, actual %s (%s)\n",
+					c.Address, c.Subject, c.Expected, c.Actual, c.File)
+			}
+		}
+
+		if mismatches > 0 {
+			fmt.Printf("%d mismatches, state may be changed by later migrations or contracts\n", mismatches)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	migrateCmd.PersistentFlags().StringVar(&migrateNetwork, "network", string(config.Mainnet), "mainnet or testnet")
+	migrateCmd.PersistentFlags().StringVar(&migrateDir, "migrations-dir", "../migrations", "folder with migrations")
+	migrateCmd.PersistentFlags().StringVar(&migrateNode, "node", "", "node url, public node of the network by default")
+	migratePlanCmd.Flags().StringVar(&migrateOut, "out", "", "save batch of the migration to sign with sign-batch")
+	migrateApplyCmd.Flags().StringVar(&migrateBatch, "batch", "", "batch of the migration signed with sign-batch")
+
+	migrateCmd.AddCommand(migrateStatusCmd, migratePlanCmd, migrateApplyCmd, migrateVerifyCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
+
+// loadMigrations returns folders of the network, applied migrations by name and storage to record new ones
+func loadMigrations(ctx context.Context) ([]migration.Folder, map[string]migration.Migration, storage.Storage) {
+	network := config.Network(migrateNetwork)
+	if _, err := network.Scheme(); err != nil {
+		printAndExit(err)
+	}
+
+	all, err := migration.List(migrateDir)
+	if err != nil {
+		printAndExit(err)
+	}
+	var folders []migration.Folder
+	for _, f := range all {
+		if f.Network == network {
+			folders = append(folders, f)
+		}
+	}
+
+	st, err := openStorage(ctx, network)
+	if err != nil {
+		printAndExit(err)
+	}
+	records, err := st.Migrations.GetAll(ctx, network)
+	if err != nil {
+		printAndExit(err)
+	}
+	applied := map[string]migration.Migration{}
+	for _, m := range records {
+		applied[m.Name] = m
+	}
+	return folders, applied, st
+}
+
+func migrateClient() *node.Client {
+	scheme, err := config.Network(migrateNetwork).Scheme()
+	if err != nil {
+		printAndExit(err)
+	}
+
+	nodeURL := migrateNode
+	if nodeURL == "" {
+		nodeURL = defaultNodeURL(scheme)
+	}
+	cl, err := newNodeClient(nodeURL, scheme)
+	if err != nil {
+		printAndExit(err)
+	}
+	return cl
+}
+
+// sameMigration checks that signed batch has the files of the migration in the same order
+func sameMigration(folder, signed *batch.Batch) error {
+	if folder.ChainID != signed.ChainID {
+		return fmt.Errorf("chain id %d doesn't match %d", signed.ChainID, folder.ChainID)
+	}
+	if len(folder.Items) != len(signed.Items) {
+		return fmt.Errorf("%d transactions instead of %d", len(signed.Items), len(folder.Items))
+	}
+	for i := range folder.Items {
+		if folder.Items[i].File != signed.Items[i].File || folder.Items[i].Address != signed.Items[i].Address {
+			return fmt.Errorf("transaction %d is %s of %s instead of %s of %s", i+1,
+				signed.Items[i].File, signed.Items[i].Address, folder.Items[i].File, folder.Items[i].Address)
+		}
+	}
+	return nil
+}
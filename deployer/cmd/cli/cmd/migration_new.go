@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/waves-exchange/contracts/deployer/pkg/batch"
+	"github.com/waves-exchange/contracts/deployer/pkg/migration"
+)
+
+var (
+	migrationNewDir  string
+	migrationNewNode string
+	migrationNewDate string
+)
+
+var migrationCmd = &cobra.Command{
+	Use:   "migration",
+	Short: "Author migrations/<name> folders",
+}
+
+var migrationNewCmd = &cobra.Command{
+	Use:   "new <spec.yaml>",
+	Short: "Generate data and invoke transactions of new migration from spec with contract tags",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		spec, err := migration.LoadSpec(args[0])
+		if err != nil {
+			printAndExit(err)
+		}
+		scheme, err := spec.Network.Scheme()
+		if err != nil {
+			printAndExit(err)
+		}
+
+		date := time.Now().UTC()
+		if migrationNewDate != "" {
+			date, err = time.Parse("2006-01-02", migrationNewDate)
+			if err != nil {
+				printAndExit(err)
+			}
+		}
+
+		nodeURL := migrationNewNode
+		if nodeURL == "" {
+			nodeURL = defaultNodeURL(scheme)
+		}
+		cl, err := newNodeClient(nodeURL, scheme)
+		if err != nil {
+			printAndExit(err)
+		}
+
+		st, err := openStorage(ctx, spec.Network)
+		if err != nil {
+			printAndExit(err)
+		}
+
+		files, err := spec.Build(ctx, st.Contracts, cl)
+		if err != nil {
+			printAndExit(err)
+		}
+		for _, f := range files {
+			fmt.Printf("%s: fee %d, %s\n", f.Name, f.Tx.GetFee(), batch.Describe(f.Tx))
+		}
+
+		dir, err := spec.Write(migrationNewDir, date, files)
+		if err != nil {
+			printAndExit(err)
+		}
+		fmt.Printf("migration written to %s, review it with 'cli migrate plan %s --network %s'\n",
+			dir, spec.FolderName(date), spec.Network)
+	},
+}
+
+func init() {
+	migrationNewCmd.Flags().StringVar(&migrationNewDir, "migrations-dir", "../migrations", "folder with migrations")
+	migrationNewCmd.Flags().StringVar(&migrationNewNode, "node", "", "node url to check sender scripts for fee, public node of the network by default")
+	migrationNewCmd.Flags().StringVar(&migrationNewDate, "date", "", "date of the migration folder as YYYY-MM-DD, today by default")
+
+	migrationCmd.AddCommand(migrationNewCmd)
+	rootCmd.AddCommand(migrationCmd)
+}
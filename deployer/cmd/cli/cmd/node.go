@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/waves-exchange/contracts/deployer/pkg/confirm"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/transport"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+var (
+	nodeRateLimit float64
+	nodeRetries   int
+	nodeMetrics   bool
+	nodeClients   []*node.Client
+	confirmations int
+)
+
+func init() {
+	defaults := transport.DefaultOptions()
+	rootCmd.PersistentFlags().Float64Var(&nodeRateLimit, "node-rps", defaults.RPS, "requests per second to nodes, 0 is no limit")
+	rootCmd.PersistentFlags().IntVar(&nodeRetries, "node-retries", defaults.Retries, "retries of 429, 5xx and network errors")
+	rootCmd.PersistentFlags().IntVar(&confirmations, "confirmations", confirm.DefaultOptions().Confirmations,
+		"blocks to wait for after transaction is mined, including its block")
+	rootCmd.PersistentFlags().BoolVar(&nodeMetrics, "node-metrics", false, "print node requests by endpoint on exit")
+	rootCmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		if !nodeMetrics {
+			return
+		}
+		for _, cl := range nodeClients {
+			fmt.Printf("%s\n%s", cl.BaseURL(), cl.Metrics().String())
+		}
+	}
+}
+
+// newNodeClient applies --node-rps and --node-retries, url may be comma separated list of nodes
+func newNodeClient(url string, scheme proto.Scheme) (*node.Client, error) {
+	opts := transport.DefaultOptions(strings.Split(url, ",")...)
+	opts.RPS = nodeRateLimit
+	opts.Burst = int(nodeRateLimit)
+	opts.Retries = nodeRetries
+
+	cl, err := node.NewClientWithOptions(scheme, opts)
+	if err != nil {
+		return nil, fmt.Errorf("node.NewClientWithOptions: %w", err)
+	}
+	nodeClients = append(nodeClients, cl)
+	return cl, nil
+}
+
+// confirmOptions applies --confirmations, rebroadcasts and rebuilds are logged to stderr
+func confirmOptions() confirm.Options {
+	opts := confirm.DefaultOptions()
+	opts.Confirmations = confirmations
+	opts.Logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).Level(zerolog.InfoLevel).With().Timestamp().Logger()
+	return opts
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/contract"
+	"github.com/waves-exchange/contracts/deployer/pkg/envelope"
+	"github.com/waves-exchange/contracts/deployer/pkg/storage"
+)
+
+const newMasterKeyPassphraseEnv = "NEWMASTERKEYPASSPHRASE"
+
+var (
+	registryNetwork  string
+	newMasterKeyFile string
+)
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Manage contracts registry",
+}
+
+var registryReencryptCmd = &cobra.Command{
+	Use: "reencrypt",
+	Short: "Encrypt private keys of contracts with the new master key, " +
+		"keys are decrypted with --master-key-file or " + masterKeyPassphraseEnv + ", plain text keys are encrypted, " +
+		"keys already under the new master key are skipped, so interrupted run may be started again",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		network := config.Network(registryNetwork)
+		if _, err := network.Scheme(); err != nil {
+			printAndExit(err)
+		}
+
+		oldCipher, err := envelope.Open(masterKeyFile, os.Getenv(masterKeyPassphraseEnv))
+		if err != nil {
+			printAndExit(fmt.Errorf("envelope.Open: %w", err))
+		}
+		newCipher, err := envelope.Open(newMasterKeyFile, os.Getenv(newMasterKeyPassphraseEnv))
+		if err != nil {
+			printAndExit(fmt.Errorf("envelope.Open: %w", err))
+		}
+		if newCipher == nil {
+			printAndExit(errors.New("--new-master-key-file or " + newMasterKeyPassphraseEnv + " env required"))
+		}
+
+		opts, err := storageOptions(network)
+		if err != nil {
+			printAndExit(err)
+		}
+		st, err := storage.OpenRaw(ctx, opts)
+		if err != nil {
+			printAndExit(fmt.Errorf("storage.OpenRaw: %w", err))
+		}
+		// keys of interrupted run are under the new master key already, so both are accepted
+		keyring := contract.NewEncryptedModel(st.Contracts, newCipher.WithOld(oldCipher))
+
+		stored, err := st.Contracts.GetAll(ctx)
+		if err != nil {
+			printAndExit(fmt.Errorf("st.Contracts.GetAll: %w", err))
+		}
+		// all keys are decrypted before the first write, so a wrong old key changes nothing
+		contracts, err := keyring.GetAll(ctx)
+		if err != nil {
+			printAndExit(fmt.Errorf("keyring.GetAll: %w", err))
+		}
+		decrypted := map[string]contract.Contract{}
+		for _, c := range contracts {
+			decrypted[c.File+"/"+c.BasePub] = c
+		}
+
+		count, skipped := 0, 0
+		for _, c := range stored {
+			done, e := reencrypted(newCipher, c)
+			if e != nil {
+				printAndExit(fmt.Errorf("reencrypted %s stage %d: %w", c.File, c.Stage, e))
+			}
+			if done {
+				skipped++
+				continue
+			}
+			e = keyring.UpdateKeys(ctx, decrypted[c.File+"/"+c.BasePub])
+			if e != nil {
+				printAndExit(fmt.Errorf("keyring.UpdateKeys %s stage %d: %w", c.File, c.Stage, e))
+			}
+			count++
+		}
+		fmt.Printf("keys of %d contracts are encrypted with master key %s, %d are skipped\n",
+			count, newCipher.ID(), skipped)
+	},
+}
+
+// reencrypted reports that stored keys of the contract are empty or encrypted with the new master key
+func reencrypted(newCipher *envelope.Cipher, c contract.Contract) (bool, error) {
+	for _, s := range []string{c.BasePrv, c.SignerPrv} {
+		if s == "" {
+			continue
+		}
+		ok, err := newCipher.Owns(s)
+		if err != nil {
+			return false, fmt.Errorf("newCipher.Owns: %w", err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func init() {
+	registryReencryptCmd.Flags().StringVar(&registryNetwork, "network", string(config.Testnet), "mainnet or testnet")
+	registryReencryptCmd.Flags().StringVar(&newMasterKeyFile, "new-master-key-file", "",
+		"file with new hex master key, "+newMasterKeyPassphraseEnv+" env passphrase is used if empty")
+
+	registryCmd.AddCommand(registryReencryptCmd)
+	rootCmd.AddCommand(registryCmd)
+}
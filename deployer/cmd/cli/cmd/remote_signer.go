@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
+)
+
+var (
+	remoteSignerListen string
+	remoteSignerNonces []uint
+)
+
+var remoteSignerCmd = &cobra.Command{
+	Use: "remote-signer",
+	Short: "Serve remote signer API with accounts of a local seed, it's a stand-in of signing service " +
+		"for local runs of sign-batch --remote-signer and ci REMOTESIGNERURL, " +
+		remoteSignerTokenEnv + " env is required as bearer token if set",
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		seedP := promptui.Prompt{
+			Label:       "Seed to sign with ?",
+			HideEntered: true,
+		}
+		seed, err := seedP.Run()
+		if err != nil {
+			printAndExit(err)
+		}
+
+		signers := make([]signer.Signer, 0, len(remoteSignerNonces))
+		for _, nonce := range remoteSignerNonces {
+			s, e := signer.NewSeed(seed, uint32(nonce))
+			if e != nil {
+				printAndExit(e)
+			}
+			fmt.Printf("nonce %d: %s\n", nonce, s.PublicKey().String())
+			signers = append(signers, s)
+		}
+
+		fmt.Printf("listening on %s\n", remoteSignerListen)
+		err = http.ListenAndServe(remoteSignerListen, signer.NewHandler(os.Getenv(remoteSignerTokenEnv), signers...))
+		if err != nil {
+			printAndExit(err)
+		}
+	},
+}
+
+func init() {
+	remoteSignerCmd.Flags().StringVar(&remoteSignerListen, "listen", "127.0.0.1:8090", "address to listen")
+	remoteSignerCmd.Flags().UintSliceVar(&remoteSignerNonces, "nonce", []uint{0}, "account nonces of the seed")
+	rootCmd.AddCommand(remoteSignerCmd)
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"github.com/waves-exchange/contracts/deployer/pkg/audit"
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/fee"
+	"github.com/waves-exchange/contracts/deployer/pkg/rollback"
+	"github.com/waves-exchange/contracts/deployer/pkg/scriptdiff"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
+	"github.com/waves-exchange/contracts/deployer/pkg/tools"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+var (
+	rollbackTag   string
+	rollbackStage int
+	rollbackNode  string
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Restore previous script of testnet stage contract",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		st, err := openStorage(ctx, config.Testnet)
+		if err != nil {
+			printAndExit(err)
+		}
+		c, err := st.Contracts.GetByTag(ctx, rollbackTag, &rollbackStage)
+		if err != nil {
+			printAndExit(err)
+		}
+
+		prv, err := crypto.NewSecretKeyFromBase58(c.BasePrv)
+		if err != nil {
+			printAndExit(err)
+		}
+		signerPrv, err := crypto.NewSecretKeyFromBase58(c.SignerPrv)
+		if err != nil {
+			printAndExit(err)
+		}
+		pub := crypto.GeneratePublicKey(prv)
+		addr, err := proto.NewAddressFromPublicKey(proto.TestNetScheme, pub)
+		if err != nil {
+			printAndExit(err)
+		}
+
+		nodeURL := rollbackNode
+		if nodeURL == "" {
+			nodeURL = defaultNodeURL(proto.TestNetScheme)
+		}
+		cl, err := newNodeClient(nodeURL, proto.TestNetScheme)
+		if err != nil {
+			printAndExit(err)
+		}
+
+		prev, err := rollback.PreviousScript(ctx, cl, addr)
+		if err != nil {
+			printAndExit(fmt.Errorf("%s %s: %w", c.Tag, addr, err))
+		}
+		prevID, err := prev.GetID(proto.TestNetScheme)
+		if err != nil {
+			printAndExit(err)
+		}
+		prevDigest, err := crypto.NewDigestFromBytes(prevID)
+		if err != nil {
+			printAndExit(err)
+		}
+
+		source := fmt.Sprintf("set by tx %s at %s", prevDigest,
+			time.UnixMilli(int64(prev.Timestamp)).UTC().Format(time.RFC3339))
+		records, err := st.Audit.Find(ctx, audit.Filter{Network: config.Testnet, Address: addr.String()})
+		if err != nil {
+			printAndExit(err)
+		}
+		for _, r := range records {
+			if r.ID == prevDigest.String() {
+				source += fmt.Sprintf(" from %s@%s", r.Branch, r.Commit)
+			}
+		}
+
+		current, err := cl.Script(ctx, addr)
+		if err != nil {
+			printAndExit(err)
+		}
+		_, err = scriptdiff.Print(ctx, cl, c.File, current, rollback.Base64(prev.Script))
+		if err != nil {
+			printAndExit(err)
+		}
+
+		confirmP := promptui.Prompt{
+			Label:     fmt.Sprintf("Roll back %s of stage %d (%s) to script %s", c.Tag, rollbackStage, addr, source),
+			IsConfirm: true,
+		}
+		_, err = confirmP.Run()
+		if err != nil {
+			printAndExit(err)
+		}
+
+		tx := proto.NewUnsignedSetScriptWithProofs(2, pub, prev.Script, 0, tools.Timestamp())
+		err = fee.Set(ctx, cl, tx)
+		if err != nil {
+			printAndExit(err)
+		}
+		balance, err := cl.Balance(ctx, addr)
+		if err != nil {
+			printAndExit(err)
+		}
+		if balance < tx.Fee {
+			printAndExit(fmt.Errorf("%s has %d WAVES, %d required for fee", addr, balance, tx.Fee))
+		}
+
+		err = tools.SignBroadcastWait(
+			audit.WithSource(ctx, c.File, c.Tag),
+			proto.TestNetScheme,
+			auditNode(cl, st, config.Testnet),
+			tx,
+			signer.NewKey(signerPrv),
+			confirmOptions(),
+		)
+		if err != nil {
+			printAndExit(err)
+		}
+		fmt.Printf("%s rolled back to script %s\n", c.Tag, source)
+	},
+}
+
+func init() {
+	rollbackCmd.Flags().StringVar(&rollbackTag, "tag", "", "tag of the contract")
+	rollbackCmd.Flags().IntVar(&rollbackStage, "stage", 0, "index of the stage")
+	rollbackCmd.Flags().StringVar(&rollbackNode, "node", "", "testnet node url, public node by default")
+	_ = rollbackCmd.MarkFlagRequired("tag")
+	_ = rollbackCmd.MarkFlagRequired("stage")
+	rootCmd.AddCommand(rollbackCmd)
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"github.com/waves-exchange/contracts/deployer/pkg/batch"
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/jsonfile"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
+	"github.com/waves-exchange/contracts/deployer/pkg/tools"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+)
+
+var (
+	signBatchNetwork        string
+	signBatchOut            string
+	signBatchSign           bool
+	signBatchYes            bool
+	signBatchSignaturesFile string
+	signBatchSignatures     []string
+	signBatchNonce          uint32
+	signBatchRemote         string
+	signBatchPublicKey      string
+)
+
+// remoteSignerTokenEnv is the same variable as RemoteSignerToken of ci config
+const remoteSignerTokenEnv = "REMOTESIGNERTOKEN"
+
+var signBatchCmd = &cobra.Command{
+	Use:   "sign-batch <batch.json | txs folder | migrations/<name>>",
+	Short: "Review transactions and add proofs offline, nothing is broadcast",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		scheme, err := config.Network(signBatchNetwork).Scheme()
+		if err != nil {
+			printAndExit(err)
+		}
+
+		b, out, err := loadBatch(args[0], signBatchOut, scheme)
+		if err != nil {
+			printAndExit(err)
+		}
+
+		printBatch(b)
+
+		if signBatchSignaturesFile != "" {
+			var sigs []batch.Signature
+			e := jsonfile.Load(signBatchSignaturesFile, &sigs)
+			if e != nil {
+				printAndExit(fmt.Errorf("jsonfile.Load: %w", e))
+			}
+			e = b.AddSignatures(sigs)
+			if e != nil {
+				printAndExit(fmt.Errorf("b.AddSignatures: %w", e))
+			}
+		}
+
+		for _, s := range signBatchSignatures {
+			parts := strings.Split(s, ":")
+			if len(parts) != 3 {
+				printAndExit(errors.New("signature must be id:publicKey:signature, got " + s))
+			}
+			e := b.AddSignature(batch.Signature{ID: parts[0], PublicKey: parts[1], Signature: parts[2]})
+			if e != nil {
+				printAndExit(fmt.Errorf("b.AddSignature: %w", e))
+			}
+		}
+
+		if signBatchSign && signBatchRemote != "" {
+			printAndExit(errors.New("either --sign or --remote-signer expected"))
+		}
+
+		var sgn signer.Signer
+		if signBatchSign {
+			seedP := promptui.Prompt{
+				Label:       "Seed to sign with ?",
+				HideEntered: true,
+			}
+			seed, e := seedP.Run()
+			if e != nil {
+				printAndExit(e)
+			}
+			sgn, e = signer.NewSeed(seed, signBatchNonce)
+			if e != nil {
+				printAndExit(e)
+			}
+		}
+		if signBatchRemote != "" {
+			sgn, err = remoteSigner(context.Background(), signBatchRemote, signBatchPublicKey)
+			if err != nil {
+				printAndExit(err)
+			}
+		}
+
+		if sgn != nil {
+			fmt.Printf("signer: %s\n", sgn.PublicKey().String())
+
+			for _, item := range b.Items {
+				if !signBatchYes {
+					confirmP := promptui.Prompt{
+						Label:     fmt.Sprintf("Sign %s (%s)", item.ID, item.Tag),
+						IsConfirm: true,
+					}
+					_, er := confirmP.Run()
+					if errors.Is(er, promptui.ErrAbort) {
+						continue
+					}
+					if er != nil {
+						printAndExit(er)
+					}
+				}
+				er := b.Sign(context.Background(), item.ID, sgn)
+				if er != nil {
+					printAndExit(fmt.Errorf("b.Sign: %w", er))
+				}
+			}
+		}
+
+		err = b.Save(out)
+		if err != nil {
+			printAndExit(err)
+		}
+		fmt.Printf("batch saved to %s, broadcast it with 'cli broadcast %s'\n", out, out)
+	},
+}
+
+func init() {
+	signBatchCmd.Flags().StringVar(&signBatchNetwork, "network", string(config.Mainnet), "mainnet or testnet")
+	signBatchCmd.Flags().StringVar(&signBatchOut, "out", "batch.json", "batch file to write if transactions are read from folder")
+	signBatchCmd.Flags().BoolVar(&signBatchSign, "sign", false, "sign transactions with a local seed")
+	signBatchCmd.Flags().Uint32Var(&signBatchNonce, "nonce", 0, "account nonce of the seed")
+	signBatchCmd.Flags().StringVar(&signBatchRemote, "remote-signer", "",
+		"sign transactions with remote signer url, "+remoteSignerTokenEnv+" env is sent as bearer token")
+	signBatchCmd.Flags().StringVar(&signBatchPublicKey, "public-key", "",
+		"public key of remote signer, required if it has several keys")
+	signBatchCmd.Flags().BoolVar(&signBatchYes, "yes", false, "sign all transactions without confirmation")
+	signBatchCmd.Flags().StringVar(&signBatchSignaturesFile, "signatures", "", "JSON file with detached signatures: [{id, publicKey, signature}]")
+	signBatchCmd.Flags().StringArrayVar(&signBatchSignatures, "signature", nil, "detached signature as id:publicKey:signature")
+	rootCmd.AddCommand(signBatchCmd)
+}
+
+// remoteSigner returns signer of the public key, or the only key of the signing service
+func remoteSigner(ctx context.Context, url, publicKey string) (signer.Signer, error) {
+	token := os.Getenv(remoteSignerTokenEnv)
+	if publicKey != "" {
+		pub, err := crypto.NewPublicKeyFromBase58(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("crypto.NewPublicKeyFromBase58: %w", err)
+		}
+		return signer.NewRemote(url, token, pub), nil
+	}
+
+	keys, err := signer.RemotePublicKeys(ctx, url, token)
+	if err != nil {
+		return nil, fmt.Errorf("signer.RemotePublicKeys: %w", err)
+	}
+	if len(keys) != 1 {
+		names := make([]string, 0, len(keys))
+		for _, k := range keys {
+			names = append(names, k.String())
+		}
+		return nil, fmt.Errorf("--public-key required, remote signer keys: [%s]", strings.Join(names, ", "))
+	}
+	return signer.NewRemote(url, token, keys[0]), nil
+}
+
+// loadBatch reads batch file which is updated in place, or transactions from folder saved to out
+func loadBatch(source, out string, scheme byte) (*batch.Batch, string, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, "", errors.New("no such file or folder: " + source)
+		}
+		return nil, "", fmt.Errorf("os.Stat: %w", err)
+	}
+
+	if !info.IsDir() {
+		b, e := batch.Load(source)
+		if e != nil {
+			return nil, "", fmt.Errorf("batch.Load: %w", e)
+		}
+		if b.ChainID != scheme {
+			return nil, "", fmt.Errorf("batch chain id %d doesn't match network %d", b.ChainID, scheme)
+		}
+		return b, source, nil
+	}
+
+	b, err := batch.FromDir(source, scheme, tools.Timestamp())
+	if err != nil {
+		return nil, "", fmt.Errorf("batch.FromDir: %w", err)
+	}
+	return b, out, nil
+}
+
+func printBatch(b *batch.Batch) {
+	for i, item := range b.Items {
+		tx, err := item.Transaction()
+		if err != nil {
+			printAndExit(fmt.Errorf("item.Transaction: %w", err))
+		}
+		fmt.Printf("%d. %s %s\n", i+1, item.Tag, item.File)
+		fmt.Printf("   id:        %s\n", item.ID)
+		fmt.Printf("   sender:    %s\n", item.Address)
+		fmt.Printf("   fee:       %d\n", tx.GetFee())
+		fmt.Printf("   timestamp: %d\n", tx.GetTimestamp())
+		fmt.Printf("   %s\n", batch.Describe(tx))
+		if item.ScriptHash != "" {
+			fmt.Printf("   script hash (base64): %s\n", item.ScriptHash)
+		}
+		if len(item.Signers) > 0 {
+			fmt.Printf("   signed by: %s\n", strings.Join(item.Signers, ", "))
+		}
+		if item.Diff != "" {
+			fmt.Println(item.Diff)
+		}
+		if item.StateDiff != "" {
+			fmt.Println(item.StateDiff)
+		}
+	}
+}
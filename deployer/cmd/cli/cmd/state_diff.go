@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/waves-exchange/contracts/deployer/pkg/batch"
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/statediff"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+var (
+	stateDiffNetwork string
+	stateDiffNode    string
+	stateDiffStrict  bool
+)
+
+var stateDiffCmd = &cobra.Command{
+	Use:   "state-diff <tx.json | batch.json | txs folder | migrations/<name>>",
+	Short: "Show per-key diff of data transactions against current state and flag suspicious changes",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := context.Background()
+
+		scheme, err := config.Network(stateDiffNetwork).Scheme()
+		if err != nil {
+			printAndExit(err)
+		}
+
+		txs, err := loadDataTxs(args[0], scheme)
+		if err != nil {
+			printAndExit(err)
+		}
+
+		nodeURL := stateDiffNode
+		if nodeURL == "" {
+			nodeURL = defaultNodeURL(scheme)
+		}
+		cl, err := newNodeClient(nodeURL, scheme)
+		if err != nil {
+			printAndExit(err)
+		}
+
+		suspicious := 0
+		for _, d := range txs {
+			addr, e := proto.NewAddressFromPublicKey(scheme, d.tx.SenderPK)
+			if e != nil {
+				printAndExit(e)
+			}
+			changes, e := statediff.Diff(ctx, cl, d.tx)
+			if e != nil {
+				printAndExit(e)
+			}
+			fmt.Printf("%s %s:\n%s\n", d.name, addr, statediff.Format(changes))
+			suspicious += len(statediff.Suspicious(changes))
+		}
+
+		if suspicious > 0 {
+			fmt.Printf("%d suspicious changes\n", suspicious)
+			if stateDiffStrict {
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func init() {
+	stateDiffCmd.Flags().StringVar(&stateDiffNetwork, "network", string(config.Mainnet), "mainnet or testnet")
+	stateDiffCmd.Flags().StringVar(&stateDiffNode, "node", "", "node url, public node of the network by default")
+	stateDiffCmd.Flags().BoolVar(&stateDiffStrict, "strict", false, "exit with 1 if there are suspicious changes")
+	rootCmd.AddCommand(stateDiffCmd)
+}
+
+type namedDataTx struct {
+	name string
+	tx   *proto.DataWithProofs
+}
+
+// loadDataTxs reads data transactions of single tx file, batch or folder in order
+func loadDataTxs(source string, scheme proto.Scheme) ([]namedDataTx, error) {
+	var res []namedDataTx
+
+	if info, err := os.Stat(source); err == nil && !info.IsDir() {
+		if _, e := batch.Load(source); e != nil {
+			raw, er := os.ReadFile(source)
+			if er != nil {
+				return nil, fmt.Errorf("os.ReadFile: %w", er)
+			}
+			tx, er := batch.DecodeTransaction(raw)
+			if er != nil {
+				return nil, fmt.Errorf("batch.DecodeTransaction: %w", er)
+			}
+			data, ok := tx.(*proto.DataWithProofs)
+			if !ok {
+				return nil, fmt.Errorf("%s is %T, not data transaction", source, tx)
+			}
+			return append(res, namedDataTx{name: source, tx: data}), nil
+		}
+	}
+
+	b, _, err := loadBatch(source, "", scheme)
+	if err != nil {
+		return nil, fmt.Errorf("loadBatch: %w", err)
+	}
+	for _, item := range b.Items {
+		tx, e := item.Transaction()
+		if e != nil {
+			return nil, fmt.Errorf("item.Transaction: %w", e)
+		}
+		if data, ok := tx.(*proto.DataWithProofs); ok {
+			res = append(res, namedDataTx{name: item.Tag, tx: data})
+		}
+	}
+	return res, nil
+}
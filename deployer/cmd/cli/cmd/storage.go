@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/manifoldco/promptui"
+	"github.com/rs/zerolog"
+	"github.com/waves-exchange/contracts/deployer/pkg/audit"
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/envelope"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/storage"
+)
+
+// masterKeyPassphraseEnv is the same variable as MasterKeyPassphrase of ci config
+const masterKeyPassphraseEnv = "MASTERKEYPASSPHRASE"
+
+var (
+	storageBackend string
+	storageDir     string
+	masterKeyFile  string
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&storageBackend, "storage", string(storage.BackendMongo), "storage backend: mongo or file")
+	rootCmd.PersistentFlags().StringVar(&storageDir, "storage-dir", "storage", "folder with JSON files for file storage backend")
+	rootCmd.PersistentFlags().StringVar(&masterKeyFile, "master-key-file", "",
+		"file with hex master key encrypting private keys of contracts, "+masterKeyPassphraseEnv+" env passphrase is used if empty")
+}
+
+// auditNode records transactions broadcast by cli in the audit log of the network
+func auditNode(nd node.Node, st storage.Storage, network config.Network) node.Node {
+	branch, commit := audit.Git()
+	return audit.NewNode(nd, st.Audit, network, branch, commit)
+}
+
+// openStorage opens storage with the master key of --master-key-file or passphrase env
+func openStorage(ctx context.Context, network config.Network) (storage.Storage, error) {
+	opts, err := storageOptions(network)
+	if err != nil {
+		return storage.Storage{}, err
+	}
+
+	opts.Cipher, err = envelope.Open(masterKeyFile, os.Getenv(masterKeyPassphraseEnv))
+	if err != nil {
+		return storage.Storage{}, fmt.Errorf("envelope.Open: %w", err)
+	}
+
+	st, err := storage.Open(ctx, opts)
+	if err != nil {
+		return storage.Storage{}, fmt.Errorf("storage.Open: %w", err)
+	}
+	return st, nil
+}
+
+// storageOptions asks mongo uri only if mongo backend is selected, cipher is not set
+func storageOptions(network config.Network) (storage.Options, error) {
+	const (
+		defiConfig = "defi_config"
+		branches   = "branches"
+		contracts  = "contracts"
+		migrations = "migrations"
+		auditLog   = "audit"
+		journal    = "journal"
+	)
+
+	opts := storage.Options{
+		Backend:                   storage.Backend(storageBackend),
+		MongoDatabaseName:         defiConfig,
+		MongoCollectionContracts:  contracts,
+		MongoCollectionBranches:   branches,
+		MongoCollectionMigrations: migrations,
+		MongoCollectionAudit:      auditLog,
+		MongoCollectionJournal:    journal,
+		Dir:                       filepath.Join(storageDir, string(network)),
+		Logger:                    zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).Level(zerolog.InfoLevel).With().Timestamp().Logger(),
+	}
+
+	if opts.Backend == storage.BackendMongo {
+		mongouriP := promptui.Prompt{
+			Label:       "Mongo uri ?",
+			HideEntered: true,
+		}
+		mongouri, err := mongouriP.Run()
+		if err != nil {
+			return storage.Options{}, fmt.Errorf("mongouriP.Run: %w", err)
+		}
+		opts.MongoURI = mongouri
+	}
+	return opts, nil
+}
@@ -3,14 +3,22 @@ package main
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strings"
 
-	"github.com/waves-exchange/contracts/deployer/pkg/branch"
+	"github.com/waves-exchange/contracts/deployer/pkg/audit"
+	"github.com/waves-exchange/contracts/deployer/pkg/compiler"
 	"github.com/waves-exchange/contracts/deployer/pkg/config"
-	"github.com/waves-exchange/contracts/deployer/pkg/contract"
+	"github.com/waves-exchange/contracts/deployer/pkg/confirm"
 	"github.com/waves-exchange/contracts/deployer/pkg/docs"
+	"github.com/waves-exchange/contracts/deployer/pkg/envelope"
 	"github.com/waves-exchange/contracts/deployer/pkg/logger"
-	"github.com/waves-exchange/contracts/deployer/pkg/mongo"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
+	"github.com/waves-exchange/contracts/deployer/pkg/storage"
 	"github.com/waves-exchange/contracts/deployer/pkg/syncer"
+	"github.com/waves-exchange/contracts/deployer/pkg/transport"
+	"github.com/wavesplatform/gowaves/pkg/proto"
 )
 
 func main() {
@@ -26,30 +34,66 @@ func main() {
 		panic(fmt.Errorf("logger.NewLogger: %w", err))
 	}
 
+	cipher, err := envelope.Open(cfg.MasterKeyFile, cfg.MasterKeyPassphrase)
+	if err != nil {
+		panic(fmt.Errorf("envelope.Open: %w", err))
+	}
+
+	storageOptions := func(network config.Network, mongoURI string) storage.Options {
+		return storage.Options{
+			Backend:                   storage.Backend(cfg.Storage),
+			MongoURI:                  mongoURI,
+			MongoDatabaseName:         cfg.MongoDatabaseName,
+			MongoCollectionContracts:  cfg.MongoCollectionContracts,
+			MongoCollectionBranches:   cfg.MongoCollectionBranches,
+			MongoCollectionMigrations: cfg.MongoCollectionMigrations,
+			MongoCollectionAudit:      cfg.MongoCollectionAudit,
+			MongoCollectionJournal:    cfg.MongoCollectionJournal,
+			Dir:                       filepath.Join(cfg.StorageDir, string(network)),
+			Cipher:                    cipher,
+			Logger:                    logg.ZL,
+		}
+	}
+
 	// 'context' may be testnet or mainnet
-	contextDB, err := mongo.NewConn(ctx, cfg.MongoDatabaseName, cfg.MongoURI)
+	contextStorage, err := storage.Open(ctx, storageOptions(cfg.Network, cfg.MongoURI))
 	if err != nil {
-		panic(fmt.Errorf("mongo.NewConn: %w", err))
+		panic(fmt.Errorf("storage.Open: %w", err))
 	}
 
-	testnetDB, err := mongo.NewConn(ctx, cfg.MongoDatabaseName, cfg.TestnetMongoURI)
+	testnetStorage, err := storage.Open(ctx, storageOptions(config.Testnet, cfg.TestnetMongoURI))
 	if err != nil {
-		panic(fmt.Errorf("mongo.NewConn: %w", err))
+		panic(fmt.Errorf("storage.Open: %w", err))
 	}
 
-	mainnetDB, err := mongo.NewConn(ctx, cfg.MongoDatabaseName, cfg.MainnetMongoURI)
+	mainnetStorage, err := storage.Open(ctx, storageOptions(config.Mainnet, cfg.MainnetMongoURI))
 	if err != nil {
-		panic(fmt.Errorf("mongo.NewConn: %w", err))
+		panic(fmt.Errorf("storage.Open: %w", err))
 	}
 
+	newNode := func(urls string, scheme proto.Scheme) *node.Client {
+		opts := transport.DefaultOptions(strings.Split(urls, ",")...)
+		opts.RPS = cfg.NodeRateLimit
+		opts.Burst = int(cfg.NodeRateLimit)
+		opts.Retries = cfg.NodeRetries
+		nd, e := node.NewClientWithOptions(scheme, opts)
+		if e != nil {
+			panic(fmt.Errorf("node.NewClientWithOptions: %w", e))
+		}
+		return nd
+	}
+
+	testnetNode := newNode(cfg.TestnetNode, proto.TestNetScheme)
+	mainnetNode := newNode(cfg.MainnetNode, proto.MainNetScheme)
+
 	dc, err := docs.NewDocs(
 		logg.ZL,
-		branch.NewModel(testnetDB.Collection(cfg.MongoCollectionBranches)),
-		contract.NewModel(testnetDB.Collection(cfg.MongoCollectionContracts)),
-		cfg.TestnetNode,
-		branch.NewModel(mainnetDB.Collection(cfg.MongoCollectionBranches)),
-		contract.NewModel(mainnetDB.Collection(cfg.MongoCollectionContracts)),
-		cfg.MainnetNode,
+		testnetStorage.Branches,
+		testnetStorage.Contracts,
+		testnetNode,
+		mainnetStorage.Branches,
+		mainnetStorage.Contracts,
+		mainnetNode,
 	)
 	if err != nil {
 		panic(fmt.Errorf("docs.NewDocs: %w", err))
@@ -60,16 +104,56 @@ func main() {
 		panic(fmt.Errorf("dc.Update: %w", err))
 	}
 
+	scheme, err := cfg.Network.Scheme()
+	if err != nil {
+		panic(fmt.Errorf("cfg.Network.Scheme: %w", err))
+	}
+
+	contextNode := newNode(cfg.Node, scheme)
+	defer func() {
+		for _, s := range contextNode.Metrics().Stats() {
+			logg.ZL.Info().
+				Str("endpoint", s.Endpoint).
+				Int("requests", s.Requests).
+				Int("retries", s.Retries).
+				Int("errors", s.Errors).
+				Dur("max", s.Max).
+				Msg("node requests")
+		}
+	}()
+
+	comp, err := compiler.New(compiler.Options{
+		Backend:    compiler.Backend(cfg.Compiler),
+		Node:       contextNode,
+		Command:    cfg.CompilerCommand,
+		Version:    cfg.CompilerVersion,
+		CacheDir:   cfg.CompilerCacheDir,
+		DriftCheck: cfg.CompilerDriftCheck,
+		Scheme:     contextNode.Scheme(),
+	})
+	if err != nil {
+		panic(fmt.Errorf("compiler.New: %w", err))
+	}
+
+	_, commit := audit.Git()
+	auditNode := audit.NewNode(contextNode, contextStorage.Audit, cfg.Network, cfg.Branch, commit)
+
+	confirmOpts := confirm.DefaultOptions()
+	confirmOpts.Confirmations = cfg.Confirmations
+
 	sc, err := syncer.NewSyncer(
 		logg.ZL,
 		cfg.Network,
-		cfg.Node,
+		auditNode,
+		comp,
 		cfg.Branch,
-		contract.NewModel(contextDB.Collection(cfg.MongoCollectionContracts)),
-		branch.NewModel(contextDB.Collection(cfg.MongoCollectionBranches)),
+		contextStorage.Contracts,
+		contextStorage.Branches,
 		cfg.CompareLpScriptAddress,
 		cfg.CompareLpStableScriptAddress,
 		cfg.FeeSeed,
+		cfg.DryRun,
+		confirmOpts,
 	)
 	if err != nil {
 		panic(fmt.Errorf("syncer.NewSyncer: %w", err))
@@ -79,4 +163,30 @@ func main() {
 	if err != nil {
 		panic(fmt.Errorf("sc.ApplyChanges: %w", err))
 	}
+
+	if cfg.DryRun {
+		err = sc.Plan().WriteFile(cfg.PlanFile)
+		if err != nil {
+			panic(fmt.Errorf("sc.Plan().WriteFile: %w", err))
+		}
+	}
+
+	if len(sc.Batch().Items) > 0 {
+		if cfg.RemoteSignerURL != "" {
+			signers, e := signer.NewRemoteSigners(ctx, cfg.RemoteSignerURL, cfg.RemoteSignerToken)
+			if e != nil {
+				panic(fmt.Errorf("signer.NewRemoteSigners: %w", e))
+			}
+			added, e := sc.Batch().SignWith(ctx, contextNode, signers)
+			if e != nil {
+				panic(fmt.Errorf("sc.Batch().SignWith: %w", e))
+			}
+			logg.ZL.Info().Int("proofs", added).Msg("batch signed with remote signer")
+		}
+
+		err = sc.Batch().Save(cfg.BatchFile)
+		if err != nil {
+			panic(fmt.Errorf("sc.Batch().Save: %w", err))
+		}
+	}
 }
@@ -0,0 +1,49 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+)
+
+// Record is a transaction broadcast by the deployer
+type Record struct {
+	ID      string         `bson:"id" json:"id"`
+	Type    string         `bson:"type" json:"type"`
+	Sender  string         `bson:"sender" json:"sender"`
+	DApp    string         `bson:"dApp,omitempty" json:"dApp,omitempty"`
+	File    string         `bson:"file,omitempty" json:"file,omitempty"`
+	Tag     string         `bson:"tag,omitempty" json:"tag,omitempty"`
+	Branch  string         `bson:"branch,omitempty" json:"branch,omitempty"`
+	Commit  string         `bson:"commit,omitempty" json:"commit,omitempty"`
+	Network config.Network `bson:"network" json:"network"`
+	Fee     uint64         `bson:"fee" json:"fee"`
+	// Height is set when the transaction is confirmed
+	Height      uint64    `bson:"height,omitempty" json:"height,omitempty"`
+	Confirmed   bool      `bson:"confirmed" json:"confirmed"`
+	BroadcastAt time.Time `bson:"broadcastAt" json:"broadcastAt"`
+}
+
+// Filter selects records of the network, by tag and by address of sender or invoked dApp if they are set
+type Filter struct {
+	Network config.Network
+	Tag     string
+	Address string
+	// Limit is the number of latest records, all if zero
+	Limit int
+}
+
+func (f Filter) match(r Record) bool {
+	return r.Network == f.Network &&
+		(f.Tag == "" || r.Tag == f.Tag) &&
+		(f.Address == "" || r.Sender == f.Address || r.DApp == f.Address)
+}
+
+// Model is audit log of broadcast transactions
+type Model interface {
+	Create(ctx context.Context, r Record) error
+	Confirm(ctx context.Context, id string, height uint64) error
+	// Find returns records in broadcast order
+	Find(ctx context.Context, f Filter) ([]Record, error)
+}
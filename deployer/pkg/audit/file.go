@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/jsonfile"
+)
+
+// fileModel keeps audit log in local JSON file
+type fileModel struct {
+	name string
+	mu   *sync.Mutex
+}
+
+func NewFileModel(name string) Model {
+	return fileModel{
+		name: name,
+		mu:   &sync.Mutex{},
+	}
+}
+
+func (m fileModel) load() ([]Record, error) {
+	var docs []Record
+	err := jsonfile.Load(m.name, &docs)
+	if err != nil {
+		return nil, fmt.Errorf("jsonfile.Load: %w", err)
+	}
+	return docs, nil
+}
+
+func (m fileModel) save(docs []Record) error {
+	err := jsonfile.Save(m.name, docs)
+	if err != nil {
+		return fmt.Errorf("jsonfile.Save: %w", err)
+	}
+	return nil
+}
+
+func (m fileModel) Create(_ context.Context, r Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return err
+	}
+	return m.save(append(docs, r))
+}
+
+func (m fileModel) Confirm(_ context.Context, id string, height uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	for i := range docs {
+		if docs[i].ID == id {
+			docs[i].Height = height
+			docs[i].Confirmed = true
+			return m.save(docs)
+		}
+	}
+	return fmt.Errorf("no audit record of tx %s", id)
+}
+
+func (m fileModel) Find(_ context.Context, f Filter) ([]Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Record
+	for _, doc := range docs {
+		if f.match(doc) {
+			res = append(res, doc)
+		}
+	}
+	if f.Limit > 0 && len(res) > f.Limit {
+		res = res[len(res)-f.Limit:]
+	}
+	return res, nil
+}
@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Git returns current branch and commit of the working tree, GITHUB_REF_NAME and GITHUB_SHA in CI
+func Git() (branch, commit string) {
+	branch = os.Getenv("GITHUB_REF_NAME")
+	if branch == "" {
+		branch = git("rev-parse", "--abbrev-ref", "HEAD")
+	}
+	commit = os.Getenv("GITHUB_SHA")
+	if commit == "" {
+		commit = git("rev-parse", "HEAD")
+	}
+	return branch, commit
+}
+
+func git(args ...string) string {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoModel struct {
+	coll *mongo.Collection
+}
+
+func NewMongoModel(coll *mongo.Collection) Model {
+	return mongoModel{
+		coll: coll,
+	}
+}
+
+func (m mongoModel) Create(ctx context.Context, r Record) error {
+	_, err := m.coll.InsertOne(ctx, r)
+	if err != nil {
+		return fmt.Errorf("m.coll.InsertOne: %w", err)
+	}
+	return nil
+}
+
+func (m mongoModel) Confirm(ctx context.Context, id string, height uint64) error {
+	res, err := m.coll.UpdateOne(ctx, bson.M{"id": id}, bson.M{"$set": bson.M{
+		"height":    height,
+		"confirmed": true,
+	}})
+	if err != nil {
+		return fmt.Errorf("m.coll.UpdateOne: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("no audit record of tx %s", id)
+	}
+	return nil
+}
+
+func (m mongoModel) Find(ctx context.Context, f Filter) ([]Record, error) {
+	q := bson.M{"network": f.Network}
+	if f.Tag != "" {
+		q["tag"] = f.Tag
+	}
+	if f.Address != "" {
+		q["$or"] = bson.A{bson.M{"sender": f.Address}, bson.M{"dApp": f.Address}}
+	}
+
+	// latest records are taken first if limited, then returned in broadcast order
+	opts := options.Find().SetSort(bson.M{"broadcastAt": -1})
+	if f.Limit > 0 {
+		opts.SetLimit(int64(f.Limit))
+	}
+
+	cur, err := m.coll.Find(ctx, q, opts)
+	if err != nil {
+		return nil, fmt.Errorf("m.coll.Find: %w", err)
+	}
+
+	var docs []Record
+	err = cur.All(ctx, &docs)
+	if err != nil {
+		return nil, fmt.Errorf("cur.All: %w", err)
+	}
+
+	for i, j := 0, len(docs)-1; i < j; i, j = i+1, j-1 {
+		docs[i], docs[j] = docs[j], docs[i]
+	}
+	return docs, nil
+}
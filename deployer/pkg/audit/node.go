@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/client"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+type sourceKey struct{}
+
+type source struct {
+	file string
+	tag  string
+}
+
+// WithSource marks transactions broadcast with ctx as made for the contract file and tag
+func WithSource(ctx context.Context, file, tag string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source{file: file, tag: tag})
+}
+
+// Node records every transaction broadcast through it and confirms the record
+// when the transaction is found on chain, e.g. by tools.BroadcastWait
+type Node struct {
+	node.Node
+	model   Model
+	network config.Network
+	branch  string
+	commit  string
+	mu      *sync.Mutex
+	pending map[crypto.Digest]bool
+}
+
+func NewNode(nd node.Node, model Model, network config.Network, branch, commit string) *Node {
+	return &Node{
+		Node:    nd,
+		model:   model,
+		network: network,
+		branch:  branch,
+		commit:  commit,
+		mu:      &sync.Mutex{},
+		pending: map[crypto.Digest]bool{},
+	}
+}
+
+func (n *Node) Broadcast(ctx context.Context, tx proto.Transaction) error {
+	err := n.Node.Broadcast(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	r, err := n.record(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("tx is broadcast but not recorded: %w", err)
+	}
+	id, err := crypto.NewDigestFromBase58(r.ID)
+	if err != nil {
+		return fmt.Errorf("crypto.NewDigestFromBase58: %w", err)
+	}
+
+	n.mu.Lock()
+	rebroadcast := n.pending[id]
+	n.mu.Unlock()
+	if rebroadcast {
+		return nil
+	}
+
+	err = n.model.Create(ctx, r)
+	if err != nil {
+		return fmt.Errorf("tx %s is broadcast but not recorded: n.model.Create: %w", r.ID, err)
+	}
+
+	n.mu.Lock()
+	n.pending[id] = true
+	n.mu.Unlock()
+	return nil
+}
+
+func (n *Node) TransactionInfo(ctx context.Context, id crypto.Digest) (client.TransactionInfo, error) {
+	info, err := n.Node.TransactionInfo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	n.mu.Lock()
+	pending := n.pending[id]
+	delete(n.pending, id)
+	n.mu.Unlock()
+
+	if pending {
+		e := n.model.Confirm(ctx, id.String(), uint64(info.GetHeight()))
+		if e != nil {
+			return nil, fmt.Errorf("n.model.Confirm: %w", e)
+		}
+	}
+	return info, nil
+}
+
+func (n *Node) record(ctx context.Context, tx proto.Transaction) (Record, error) {
+	scheme := n.Node.Scheme()
+
+	idBytes, err := tx.GetID(scheme)
+	if err != nil {
+		return Record{}, fmt.Errorf("tx.GetID: %w", err)
+	}
+	sender, err := tx.GetSender(scheme)
+	if err != nil {
+		return Record{}, fmt.Errorf("tx.GetSender: %w", err)
+	}
+	senderAddr, err := sender.ToWavesAddress(scheme)
+	if err != nil {
+		return Record{}, fmt.Errorf("sender.ToWavesAddress: %w", err)
+	}
+
+	src, _ := ctx.Value(sourceKey{}).(source)
+	r := Record{
+		ID:          proto.B58Bytes(idBytes).String(),
+		Type:        typeName(tx),
+		Sender:      senderAddr.String(),
+		File:        src.file,
+		Tag:         src.tag,
+		Branch:      n.branch,
+		Commit:      n.commit,
+		Network:     n.network,
+		Fee:         tx.GetFee(),
+		BroadcastAt: time.Now().UTC(),
+	}
+	if invoke, ok := tx.(*proto.InvokeScriptWithProofs); ok {
+		r.DApp = invoke.ScriptRecipient.String()
+	}
+	return r, nil
+}
+
+var typeNames = map[proto.TransactionType]string{
+	proto.IssueTransaction:          "issue",
+	proto.TransferTransaction:       "transfer",
+	proto.ReissueTransaction:        "reissue",
+	proto.BurnTransaction:           "burn",
+	proto.LeaseTransaction:          "lease",
+	proto.LeaseCancelTransaction:    "leaseCancel",
+	proto.DataTransaction:           "data",
+	proto.SetScriptTransaction:      "setScript",
+	proto.SponsorshipTransaction:    "sponsorship",
+	proto.SetAssetScriptTransaction: "setAssetScript",
+	proto.InvokeScriptTransaction:   "invokeScript",
+}
+
+func typeName(tx proto.Transaction) string {
+	t := tx.GetTypeInfo().Type
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return strconv.Itoa(int(t))
+}
+
+// compile-time check
+var _ node.Node = (*Node)(nil)
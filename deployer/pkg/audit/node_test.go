@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/tools"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// rebroadcasting accepts transaction which is already known, like the node does for the one in the pool
+type rebroadcasting struct {
+	*node.Fake
+}
+
+func (r rebroadcasting) Broadcast(ctx context.Context, tx proto.Transaction) error {
+	err := r.Fake.Broadcast(ctx, tx)
+	if err != nil && !strings.Contains(err.Error(), "already in the state") {
+		return err
+	}
+	return nil
+}
+
+func TestNode(t *testing.T) {
+	ctx := context.Background()
+	fake := node.NewFake(proto.TestNetScheme)
+	acc := node.NewFakeAccount(fake.Scheme(), "audit")
+	fake.SetBalance(acc.Address, 1_0000_0000)
+	model := NewFileModel(filepath.Join(t.TempDir(), "audit.json"))
+	nd := NewNode(rebroadcasting{fake}, model, config.Testnet, "dev", "abc")
+
+	tx := proto.NewUnsignedDataWithProofs(2, acc.PublicKey, 500000, uint64(time.Now().UnixMilli()))
+	err := tx.AppendEntry(&proto.IntegerDataEntry{Key: "%s__key", Value: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tx.Sign(fake.Scheme(), acc.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := tools.TxID(fake.Scheme(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := WithSource(ctx, "pool.ride", "pool")
+	err = nd.Broadcast(src, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// rebroadcast of the same transaction isn't recorded twice
+	err = nd.Broadcast(src, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	height, err := fake.Height(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := model.Find(ctx, Filter{Network: config.Testnet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	r := records[0]
+	if r.ID != id.String() || r.Type != "data" || r.Sender != acc.Address.String() ||
+		r.File != "pool.ride" || r.Tag != "pool" || r.Branch != "dev" || r.Commit != "abc" || r.Fee != 500000 {
+		t.Errorf("record = %+v", r)
+	}
+	if r.Confirmed {
+		t.Error("record is confirmed before the transaction is found on chain")
+	}
+
+	_, err = nd.TransactionInfo(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err = model.Find(ctx, Filter{Network: config.Testnet, Tag: "pool"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || !records[0].Confirmed || records[0].Height != height {
+		t.Errorf("records = %+v, want confirmed at height %d", records, height)
+	}
+
+	// rejected transaction isn't recorded
+	fake.BroadcastHook = func(proto.Transaction) error { return errors.New("rejected") }
+	if err = nd.Broadcast(ctx, tx); err == nil {
+		t.Fatal("rejection isn't returned")
+	}
+	records, err = model.Find(ctx, Filter{Network: config.Testnet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Errorf("rejected transaction is recorded")
+	}
+}
+
+func TestFileModelFind(t *testing.T) {
+	ctx := context.Background()
+	m := NewFileModel(filepath.Join(t.TempDir(), "audit.json"))
+	for _, r := range []Record{
+		{ID: "1", Network: config.Mainnet, Tag: "pool", Sender: "a"},
+		{ID: "2", Network: config.Testnet, Tag: "pool", Sender: "a"},
+		{ID: "3", Network: config.Mainnet, Tag: "factory", Sender: "b", DApp: "a"},
+		{ID: "4", Network: config.Mainnet, Tag: "pool", Sender: "c"},
+	} {
+		err := m.Create(ctx, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{name: "network", filter: Filter{Network: config.Mainnet}, want: []string{"1", "3", "4"}},
+		{name: "tag", filter: Filter{Network: config.Mainnet, Tag: "pool"}, want: []string{"1", "4"}},
+		{name: "sender or dApp", filter: Filter{Network: config.Mainnet, Address: "a"}, want: []string{"1", "3"}},
+		{name: "latest", filter: Filter{Network: config.Mainnet, Limit: 2}, want: []string{"3", "4"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			records, err := m.Find(ctx, tc.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, r := range records {
+				got = append(got, r.ID)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("Find() = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("Find() = %v, want %v", got, tc.want)
+					break
+				}
+			}
+		})
+	}
+
+	if err := m.Confirm(ctx, "5", 10); err == nil {
+		t.Error("unknown transaction is confirmed")
+	}
+}
@@ -0,0 +1,437 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/audit"
+	"github.com/waves-exchange/contracts/deployer/pkg/confirm"
+	"github.com/waves-exchange/contracts/deployer/pkg/jsonfile"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
+	"github.com/waves-exchange/contracts/deployer/pkg/simulate"
+	"github.com/waves-exchange/contracts/deployer/pkg/tools"
+	"github.com/waves-exchange/contracts/deployer/pkg/verifier"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Batch is a list of transactions waiting for proofs, they are signed offline and broadcast in order
+type Batch struct {
+	ChainID proto.Scheme `json:"chainId"`
+	Created time.Time    `json:"created"`
+	Items   []Item       `json:"items"`
+}
+
+// Item is pending transaction with metadata for reviewers
+type Item struct {
+	File    string `json:"file,omitempty"`
+	Tag     string `json:"tag,omitempty"`
+	Address string `json:"address"`
+	// Diff is decompiled script diff of setScript
+	Diff string `json:"diff,omitempty"`
+	// StateDiff is per-key diff of data transaction against the chain when the item is added
+	StateDiff string `json:"stateDiff,omitempty"`
+	// ScriptHash is base64 blake2b256 of the new script, the same as factory approves for pools
+	ScriptHash string          `json:"scriptHash,omitempty"`
+	ID         string          `json:"id"`
+	Tx         json.RawMessage `json:"tx"`
+	// Signers are base58 public keys of proofs added to the batch, in the same order
+	Signers []string `json:"signers,omitempty"`
+}
+
+// Signature is detached signature of transaction body, e.g. exported from ledger or pasted
+type Signature struct {
+	ID        string `json:"id"`
+	PublicKey string `json:"publicKey"`
+	Signature string `json:"signature"`
+}
+
+// ItemStatus shows how many proofs are collected versus the sender verifier needs
+type ItemStatus struct {
+	ID          string               `json:"id"`
+	Tag         string               `json:"tag,omitempty"`
+	Address     string               `json:"address"`
+	Requirement verifier.Requirement `json:"requirement"`
+	Collected   int                  `json:"collected"`
+	Complete    bool                 `json:"complete"`
+	// Applied is true if tx is already on chain
+	Applied bool `json:"applied"`
+}
+
+func New(chainID proto.Scheme) *Batch {
+	return &Batch{ChainID: chainID, Created: time.Now().UTC()}
+}
+
+func Load(name string) (*Batch, error) {
+	b := &Batch{}
+	err := jsonfile.Load(name, b)
+	if err != nil {
+		return nil, fmt.Errorf("jsonfile.Load: %w", err)
+	}
+	if b.ChainID == 0 {
+		return nil, errors.New("no batch at " + name)
+	}
+	return b, nil
+}
+
+func (b *Batch) Save(name string) error {
+	err := jsonfile.Save(name, b)
+	if err != nil {
+		return fmt.Errorf("jsonfile.Save: %w", err)
+	}
+	return nil
+}
+
+// Add appends tx, ID, Tx and Address of item are filled from tx
+func (b *Batch) Add(item Item, tx proto.Transaction) error {
+	sender, _, err := SenderAndProofs(tx)
+	if err != nil {
+		return fmt.Errorf("SenderAndProofs: %w", err)
+	}
+
+	addr, err := proto.NewAddressFromPublicKey(b.ChainID, sender)
+	if err != nil {
+		return fmt.Errorf("proto.NewAddressFromPublicKey: %w", err)
+	}
+
+	id, err := tools.TxID(b.ChainID, tx)
+	if err != nil {
+		return fmt.Errorf("tools.TxID: %w", err)
+	}
+	if _, e := b.Find(id.String()); e == nil {
+		return fmt.Errorf("duplicated transaction %s", id.String())
+	}
+
+	item.Address = addr.String()
+	item.ID = id.String()
+	err = item.setTransaction(b.ChainID, tx)
+	if err != nil {
+		return fmt.Errorf("item.setTransaction: %w", err)
+	}
+
+	b.Items = append(b.Items, item)
+	return nil
+}
+
+func (b *Batch) Find(id string) (int, error) {
+	for i, item := range b.Items {
+		if item.ID == id {
+			return i, nil
+		}
+	}
+	return 0, errors.New("no transaction " + id)
+}
+
+// Transaction decodes tx of the item
+func (it Item) Transaction() (proto.Transaction, error) {
+	tx, err := DecodeTransaction(it.Tx)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeTransaction: %w", err)
+	}
+	return tx, nil
+}
+
+func (it *Item) setTransaction(chainID proto.Scheme, tx proto.Transaction) error {
+	err := tx.GenerateID(chainID)
+	if err != nil {
+		return fmt.Errorf("tx.GenerateID: %w", err)
+	}
+
+	raw, err := json.Marshal(tx)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	it.Tx = raw
+	return nil
+}
+
+// Sign adds proof of the signer to transaction with id
+func (b *Batch) Sign(ctx context.Context, id string, s signer.Signer) error {
+	i, err := b.Find(id)
+	if err != nil {
+		return fmt.Errorf("b.Find: %w", err)
+	}
+
+	tx, err := b.Items[i].Transaction()
+	if err != nil {
+		return fmt.Errorf("b.Items[i].Transaction: %w", err)
+	}
+
+	body, err := proto.MarshalTxBody(b.ChainID, tx)
+	if err != nil {
+		return fmt.Errorf("proto.MarshalTxBody: %w", err)
+	}
+
+	sig, err := s.Sign(ctx, body)
+	if err != nil {
+		return fmt.Errorf("s.Sign: %w", err)
+	}
+
+	err = b.AddSignature(Signature{
+		ID:        id,
+		PublicKey: s.PublicKey().String(),
+		Signature: sig.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("b.AddSignature: %w", err)
+	}
+	return nil
+}
+
+// AddSignature verifies detached signature of transaction body and appends it to proofs,
+// the body isn't changed. Signature of the same public key is added once
+func (b *Batch) AddSignature(s Signature) error {
+	i, err := b.Find(s.ID)
+	if err != nil {
+		return fmt.Errorf("b.Find: %w", err)
+	}
+	item := &b.Items[i]
+
+	pub, err := crypto.NewPublicKeyFromBase58(s.PublicKey)
+	if err != nil {
+		return fmt.Errorf("crypto.NewPublicKeyFromBase58: %w", err)
+	}
+	for _, signer := range item.Signers {
+		if signer == pub.String() {
+			return nil
+		}
+	}
+
+	sig, err := crypto.NewSignatureFromBase58(s.Signature)
+	if err != nil {
+		return fmt.Errorf("crypto.NewSignatureFromBase58: %w", err)
+	}
+
+	tx, err := item.Transaction()
+	if err != nil {
+		return fmt.Errorf("item.Transaction: %w", err)
+	}
+
+	body, err := proto.MarshalTxBody(b.ChainID, tx)
+	if err != nil {
+		return fmt.Errorf("proto.MarshalTxBody: %w", err)
+	}
+	if !crypto.Verify(pub, sig, body) {
+		return fmt.Errorf("signature of %s doesn't match transaction %s", pub.String(), s.ID)
+	}
+
+	err = signer.AppendProof(tx, sig)
+	if err != nil {
+		return fmt.Errorf("signer.AppendProof: %w", err)
+	}
+
+	err = item.setTransaction(b.ChainID, tx)
+	if err != nil {
+		return fmt.Errorf("item.setTransaction: %w", err)
+	}
+	item.Signers = append(item.Signers, pub.String())
+	return nil
+}
+
+// SignWith adds proofs of signers the sender verifier accepts to pending transactions,
+// it returns the number of added proofs
+func (b *Batch) SignWith(ctx context.Context, nd node.Node, signers []signer.Signer) (int, error) {
+	statuses, err := b.Status(ctx, nd)
+	if err != nil {
+		return 0, fmt.Errorf("b.Status: %w", err)
+	}
+
+	added := 0
+	for i, st := range statuses {
+		if st.Applied {
+			continue
+		}
+		for _, s := range signers {
+			pub := s.PublicKey().String()
+			if !contains(st.Requirement.Signers, pub) || contains(b.Items[i].Signers, pub) {
+				continue
+			}
+			err = b.Sign(ctx, st.ID, s)
+			if err != nil {
+				return added, fmt.Errorf("b.Sign %s: %w", st.ID, err)
+			}
+			added++
+		}
+	}
+	return added, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// AddSignatures adds all signatures, e.g. exported from ledger
+func (b *Batch) AddSignatures(sigs []Signature) error {
+	for _, s := range sigs {
+		err := b.AddSignature(s)
+		if err != nil {
+			return fmt.Errorf("b.AddSignature: %w", err)
+		}
+	}
+	return nil
+}
+
+// Status resolves verifier of every sender and counts collected proofs
+func (b *Batch) Status(ctx context.Context, nd node.Node) ([]ItemStatus, error) {
+	if nd.Scheme() != b.ChainID {
+		return nil, fmt.Errorf("node chain id %c doesn't match batch chain id %c", nd.Scheme(), b.ChainID)
+	}
+
+	res := make([]ItemStatus, 0, len(b.Items))
+	for _, item := range b.Items {
+		tx, err := item.Transaction()
+		if err != nil {
+			return nil, fmt.Errorf("item.Transaction: %w", err)
+		}
+
+		sender, proofs, err := SenderAndProofs(tx)
+		if err != nil {
+			return nil, fmt.Errorf("SenderAndProofs: %w", err)
+		}
+
+		st := ItemStatus{ID: item.ID, Tag: item.Tag, Address: item.Address}
+
+		id, err := crypto.NewDigestFromBase58(item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("crypto.NewDigestFromBase58: %w", err)
+		}
+		if _, e := nd.TransactionInfo(ctx, id); e == nil {
+			st.Applied = true
+			st.Complete = true
+			res = append(res, st)
+			continue
+		}
+
+		st.Requirement, err = verifier.Resolve(ctx, nd, sender)
+		if err != nil {
+			return nil, fmt.Errorf("verifier.Resolve: %w", err)
+		}
+
+		st.Collected, st.Complete, err = st.Requirement.Collected(ctx, nd, tx, *proofs)
+		if err != nil {
+			return nil, fmt.Errorf("st.Requirement.Collected: %w", err)
+		}
+		res = append(res, st)
+	}
+	return res, nil
+}
+
+// Simulation is evaluation of pending invoke on current state
+type Simulation struct {
+	ID          string `json:"id"`
+	Tag         string `json:"tag,omitempty"`
+	Description string `json:"description,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Simulate evaluates pending invokes for review, invoke depending on previous transactions
+// of the batch may fail here and succeed on broadcast
+func (b *Batch) Simulate(ctx context.Context, nd node.Node) ([]Simulation, error) {
+	var res []Simulation
+	for _, item := range b.Items {
+		tx, err := item.Transaction()
+		if err != nil {
+			return nil, fmt.Errorf("item.Transaction: %w", err)
+		}
+		invoke, ok := tx.(*proto.InvokeScriptWithProofs)
+		if !ok {
+			continue
+		}
+
+		id, err := crypto.NewDigestFromBase58(item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("crypto.NewDigestFromBase58: %w", err)
+		}
+		if _, e := nd.TransactionInfo(ctx, id); e == nil {
+			continue
+		}
+
+		s := Simulation{ID: item.ID, Tag: item.Tag}
+		ev, err := simulate.Invoke(ctx, nd, invoke)
+		if err != nil {
+			s.Error = err.Error()
+		} else {
+			s.Description = simulate.Describe(ev)
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+// Broadcast broadcasts transactions in order and waits for confirmations of each one, invokes are evaluated first.
+// Nothing is broadcast unless proofs of all transactions are complete, expired transactions must be signed again.
+// Confirmed is called with the index of every transaction on chain, including ones applied before, it may be nil
+func (b *Batch) Broadcast(ctx context.Context, nd node.Node, opts confirm.Options, confirmed func(i int) error) error {
+	statuses, err := b.Status(ctx, nd)
+	if err != nil {
+		return fmt.Errorf("b.Status: %w", err)
+	}
+
+	var incomplete []string
+	for _, st := range statuses {
+		if !st.Complete {
+			incomplete = append(incomplete, fmt.Sprintf(
+				"%s (%s): %d of %d proofs", st.ID, st.Tag, st.Collected, st.Requirement.Threshold,
+			))
+		}
+	}
+	if len(incomplete) > 0 {
+		return errors.New("proofs are not complete: " + strings.Join(incomplete, ", "))
+	}
+
+	for i, item := range b.Items {
+		if statuses[i].Applied {
+			if confirmed != nil {
+				e := confirmed(i)
+				if e != nil {
+					return fmt.Errorf("confirmed %s: %w", item.ID, e)
+				}
+			}
+			continue
+		}
+
+		tx, e := item.Transaction()
+		if e != nil {
+			return fmt.Errorf("item.Transaction: %w", e)
+		}
+
+		_, proofs, e := SenderAndProofs(tx)
+		if e != nil {
+			return fmt.Errorf("SenderAndProofs: %w", e)
+		}
+		e = statuses[i].Requirement.Arrange(b.ChainID, tx, *proofs)
+		if e != nil {
+			return fmt.Errorf("statuses[i].Requirement.Arrange %s: %w", item.ID, e)
+		}
+
+		// previous transactions are applied, so evaluation sees the same state as the invoke
+		if invoke, ok := tx.(*proto.InvokeScriptWithProofs); ok {
+			_, e = simulate.Invoke(ctx, nd, invoke)
+			if e != nil {
+				return fmt.Errorf("simulate.Invoke %s: %w", item.ID, e)
+			}
+		}
+
+		e = tools.BroadcastWait(audit.WithSource(ctx, item.File, item.Tag), b.ChainID, nd, tx, opts, nil)
+		if e != nil {
+			return fmt.Errorf("tools.BroadcastWait %s: %w", item.ID, e)
+		}
+		if confirmed != nil {
+			e = confirmed(i)
+			if e != nil {
+				return fmt.Errorf("confirmed %s: %w", item.ID, e)
+			}
+		}
+	}
+	return nil
+}
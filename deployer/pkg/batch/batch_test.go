@@ -0,0 +1,183 @@
+package batch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/confirm"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+func newSigner(t *testing.T, seed string) signer.Signer {
+	t.Helper()
+	s, err := signer.NewSeed(seed, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func dataTx(t *testing.T, sender crypto.PublicKey, value int64) *proto.DataWithProofs {
+	t.Helper()
+	tx := proto.NewUnsignedDataWithProofs(2, sender, 500000, uint64(time.Now().UnixMilli()))
+	err := tx.AppendEntry(&proto.IntegerDataEntry{Key: "%s__key", Value: value})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+// detached signs body of tx the way ledger does
+func detached(t *testing.T, s signer.Signer, scheme proto.Scheme, tx proto.Transaction) string {
+	t.Helper()
+	body, err := proto.MarshalTxBody(scheme, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := s.Sign(context.Background(), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig.String()
+}
+
+func TestAddSignature(t *testing.T) {
+	scheme := proto.TestNetScheme
+	sender := newSigner(t, "sender")
+	other := newSigner(t, "other")
+
+	b := New(scheme)
+	tx := dataTx(t, sender.PublicKey(), 1)
+	err := b.Add(Item{Tag: "factory"}, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := b.Items[0].ID
+
+	tests := []struct {
+		name string
+		sig  Signature
+	}{
+		{"another body", Signature{
+			ID:        id,
+			PublicKey: sender.PublicKey().String(),
+			Signature: detached(t, sender, scheme, dataTx(t, sender.PublicKey(), 2)),
+		}},
+		{"another key", Signature{
+			ID:        id,
+			PublicKey: sender.PublicKey().String(),
+			Signature: detached(t, other, scheme, tx),
+		}},
+		{"unknown transaction", Signature{
+			ID:        crypto.Digest{}.String(),
+			PublicKey: sender.PublicKey().String(),
+			Signature: detached(t, sender, scheme, tx),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if e := b.AddSignature(tt.sig); e == nil {
+				t.Error("signature is added")
+			}
+		})
+	}
+	if len(b.Items[0].Signers) != 0 {
+		t.Fatalf("rejected signatures are added: %v", b.Items[0].Signers)
+	}
+
+	for _, s := range []signer.Signer{sender, other, sender} {
+		err = b.AddSignature(Signature{
+			ID:        id,
+			PublicKey: s.PublicKey().String(),
+			Signature: detached(t, s, scheme, tx),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	item := b.Items[0]
+	want := []string{sender.PublicKey().String(), other.PublicKey().String()}
+	if len(item.Signers) != len(want) || item.Signers[0] != want[0] || item.Signers[1] != want[1] {
+		t.Errorf("Signers = %v, want %v", item.Signers, want)
+	}
+	signed, err := item.Transaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, proofs, err := SenderAndProofs(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len((*proofs).Proofs); n != 2 {
+		t.Errorf("got %d proofs, want 2", n)
+	}
+	// proofs aren't a part of the body, so the signed transaction is the same
+	if _, err = b.Find(id); err != nil || item.ID != id {
+		t.Errorf("transaction id is changed to %s", item.ID)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	ctx := context.Background()
+	nd := node.NewFake(proto.TestNetScheme)
+	sender := newSigner(t, "sender")
+	addr, err := proto.NewAddressFromPublicKey(nd.Scheme(), sender.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	nd.SetBalance(addr, 1_0000_0000)
+
+	applied := dataTx(t, sender.PublicKey(), 1)
+	err = signer.SignTx(ctx, sender, nd.Scheme(), applied)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = nd.Broadcast(ctx, applied)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := New(nd.Scheme())
+	for i, tx := range []proto.Transaction{applied, dataTx(t, sender.PublicKey(), 2), dataTx(t, sender.PublicKey(), 3)} {
+		err = b.Add(Item{Tag: "factory", File: string(rune('a' + i))}, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := confirm.DefaultOptions()
+	opts.PollInterval = time.Millisecond
+	var confirmed []int
+	record := func(i int) error {
+		confirmed = append(confirmed, i)
+		return nil
+	}
+
+	err = b.Broadcast(ctx, nd, opts, record)
+	if err == nil {
+		t.Fatal("batch without proofs is broadcast")
+	}
+	if n := len(nd.Broadcasts()); n != 1 || len(confirmed) != 0 {
+		t.Fatalf("incomplete batch broadcast %d transactions, confirmed %v", n-1, confirmed)
+	}
+
+	_, err = b.SignWith(ctx, nd, []signer.Signer{sender})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = b.Broadcast(ctx, nd, opts, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(nd.Broadcasts()); n != 3 {
+		t.Errorf("got %d transactions on chain, want 3", n)
+	}
+	if len(confirmed) != 3 || confirmed[0] != 0 || confirmed[1] != 1 || confirmed[2] != 2 {
+		t.Errorf("confirmed %v, want [0 1 2]", confirmed)
+	}
+}
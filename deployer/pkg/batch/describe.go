@@ -0,0 +1,90 @@
+package batch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"golang.org/x/crypto/blake2b"
+)
+
+// maxDescribedEntries limits data entries shown by Describe
+const maxDescribedEntries = 10
+
+// Describe returns human-readable summary of tx for review before signing
+func Describe(tx proto.Transaction) string {
+	switch t := tx.(type) {
+	case *proto.SetScriptWithProofs:
+		if len(t.Script) == 0 {
+			return "setScript: remove script"
+		}
+		h := blake2b.Sum256(t.Script)
+		return fmt.Sprintf("setScript: %d bytes, hash %s", len(t.Script), proto.B58Bytes(h[:]).String())
+
+	case *proto.DataWithProofs:
+		entries := make([]string, 0, len(t.Entries))
+		for i, e := range t.Entries {
+			if i == maxDescribedEntries {
+				entries = append(entries, fmt.Sprintf("and %d more", len(t.Entries)-i))
+				break
+			}
+			entries = append(entries, describeEntry(e))
+		}
+		return "data: " + strings.Join(entries, ", ")
+
+	case *proto.InvokeScriptWithProofs:
+		args := make([]string, 0, len(t.FunctionCall.Arguments()))
+		for _, a := range t.FunctionCall.Arguments() {
+			args = append(args, describeArgument(a))
+		}
+		res := fmt.Sprintf("invoke: %s.%s(%s)", t.ScriptRecipient.String(), t.FunctionCall.Name(), strings.Join(args, ", "))
+		for _, p := range t.Payments {
+			res += fmt.Sprintf(", payment %d %s", p.Amount, p.Asset.String())
+		}
+		return res
+
+	case *proto.TransferWithProofs:
+		return fmt.Sprintf("transfer: %d %s to %s", t.Amount, t.AmountAsset.String(), t.Recipient.String())
+
+	default:
+		return fmt.Sprintf("%T", tx)
+	}
+}
+
+func describeEntry(e proto.DataEntry) string {
+	switch v := e.(type) {
+	case *proto.StringDataEntry:
+		return fmt.Sprintf("%s=%q", v.Key, v.Value)
+	case *proto.IntegerDataEntry:
+		return fmt.Sprintf("%s=%d", v.Key, v.Value)
+	case *proto.BooleanDataEntry:
+		return fmt.Sprintf("%s=%t", v.Key, v.Value)
+	case *proto.BinaryDataEntry:
+		return fmt.Sprintf("%s=base64:%d bytes", v.Key, len(v.Value))
+	case *proto.DeleteDataEntry:
+		return fmt.Sprintf("%s deleted", v.Key)
+	default:
+		return e.GetKey()
+	}
+}
+
+func describeArgument(a proto.Argument) string {
+	switch v := a.(type) {
+	case *proto.StringArgument:
+		return fmt.Sprintf("%q", v.Value)
+	case *proto.IntegerArgument:
+		return fmt.Sprintf("%d", v.Value)
+	case *proto.BooleanArgument:
+		return fmt.Sprintf("%t", v.Value)
+	case *proto.BinaryArgument:
+		return proto.B58Bytes(v.Value).String()
+	case *proto.ListArgument:
+		items := make([]string, 0, len(v.Items))
+		for _, item := range v.Items {
+			items = append(items, describeArgument(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprintf("%T", a)
+	}
+}
@@ -0,0 +1,104 @@
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/txfields"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// skipFiles are JSON files which are not transactions
+var skipFiles = map[string]bool{
+	"batch.json":        true,
+	"package.json":      true,
+	"package-lock.json": true,
+}
+
+// TxFiles returns folder with transaction files, txs inside dir if exists, and names of the files in order
+func TxFiles(dir string) (string, []string, error) {
+	txsDir := filepath.Join(dir, "txs")
+	info, err := os.Stat(txsDir)
+	if err == nil && info.IsDir() {
+		dir = txsDir
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", nil, fmt.Errorf("os.Stat: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("os.ReadDir: %w", err)
+	}
+
+	var res []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") || skipFiles[entry.Name()] {
+			continue
+		}
+		res = append(res, entry.Name())
+	}
+	return dir, res, nil
+}
+
+// FromDir reads transactions from JSON files in dir in the order of file names,
+// e.g. artifacts of github-actions-ci or migrations/<name> with txs folder inside.
+// Transactions without timestamp get the given one, so their body is fixed from now on
+func FromDir(dir string, chainID proto.Scheme, timestamp uint64) (*Batch, error) {
+	dir, files, err := TxFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("TxFiles: %w", err)
+	}
+
+	b := New(chainID)
+	for _, file := range files {
+		raw, e := os.ReadFile(filepath.Join(dir, file))
+		if e != nil {
+			return nil, fmt.Errorf("os.ReadFile: %w", e)
+		}
+
+		tx, e := decodeFile(raw, chainID, timestamp)
+		if e != nil {
+			return nil, fmt.Errorf("%s: %w", file, e)
+		}
+
+		e = b.Add(Item{
+			File: file,
+			Tag:  strings.TrimSuffix(file, ".json"),
+		}, tx)
+		if e != nil {
+			return nil, fmt.Errorf("b.Add %s: %w", file, e)
+		}
+	}
+	return b, nil
+}
+
+func decodeFile(raw []byte, chainID proto.Scheme, timestamp uint64) (proto.Transaction, error) {
+	var meta struct {
+		ChainID *proto.Scheme `json:"chainId"`
+	}
+	err := json.Unmarshal(raw, &meta)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	if meta.ChainID != nil && *meta.ChainID != chainID {
+		return nil, fmt.Errorf("chain id %d doesn't match %d", *meta.ChainID, chainID)
+	}
+
+	tx, err := DecodeTransaction(raw)
+	if err != nil {
+		return nil, fmt.Errorf("DecodeTransaction: %w", err)
+	}
+
+	if tx.GetTimestamp() == 0 {
+		err = txfields.SetTimestamp(chainID, tx, timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("txfields.SetTimestamp: %w", err)
+		}
+	}
+	return tx, nil
+}
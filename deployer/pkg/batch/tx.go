@@ -0,0 +1,39 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/txfields"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// DecodeTransaction decodes transaction JSON of any type as node API and waves-transactions produce it
+func DecodeTransaction(b []byte) (proto.Transaction, error) {
+	var ttv proto.TransactionTypeVersion
+	err := json.Unmarshal(b, &ttv)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	tx, err := proto.GuessTransactionType(&ttv)
+	if err != nil {
+		return nil, fmt.Errorf("proto.GuessTransactionType: %w", err)
+	}
+
+	err = json.Unmarshal(b, tx)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return tx, nil
+}
+
+// SenderAndProofs returns sender public key of tx and pointer to its proofs, so they can be appended
+func SenderAndProofs(tx proto.Transaction) (crypto.PublicKey, **proto.ProofsV1, error) {
+	f, err := txfields.Of(tx)
+	if err != nil {
+		return crypto.PublicKey{}, nil, err
+	}
+	return f.Sender, f.Proofs, nil
+}
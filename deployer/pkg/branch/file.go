@@ -0,0 +1,116 @@
+package branch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/jsonfile"
+)
+
+// fileModel keeps branches in local JSON file, it's useful for local stages and tests
+type fileModel struct {
+	name string
+	mu   *sync.Mutex
+}
+
+func NewFileModel(name string) Model {
+	return fileModel{
+		name: name,
+		mu:   &sync.Mutex{},
+	}
+}
+
+func (m fileModel) load() ([]Branch, error) {
+	var docs []Branch
+	err := jsonfile.Load(m.name, &docs)
+	if err != nil {
+		return nil, fmt.Errorf("jsonfile.Load: %w", err)
+	}
+	return docs, nil
+}
+
+func (m fileModel) GetTestnetBranches(_ context.Context) ([]Branch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Branch
+	for _, doc := range docs {
+		if doc.Network == config.Testnet {
+			res = append(res, doc)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Stage < res[j].Stage
+	})
+	return res, nil
+}
+
+func (m fileModel) StageExists(_ context.Context, stage uint32) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return false, err
+	}
+
+	for _, doc := range docs {
+		if doc.Stage == stage {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m fileModel) Create(_ context.Context, branch string, network config.Network, stage uint32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	docs = append(docs, Branch{
+		Branch:  branch,
+		Network: network,
+		Stage:   stage,
+	})
+
+	err = jsonfile.Save(m.name, docs)
+	if err != nil {
+		return fmt.Errorf("jsonfile.Save: %w", err)
+	}
+	return nil
+}
+
+func (m fileModel) DeleteStage(_ context.Context, stage uint32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	var res []Branch
+	for _, doc := range docs {
+		if doc.Stage != stage {
+			res = append(res, doc)
+		}
+	}
+
+	err = jsonfile.Save(m.name, res)
+	if err != nil {
+		return fmt.Errorf("jsonfile.Save: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package branch
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+)
+
+func TestFileModel(t *testing.T) {
+	ctx := context.Background()
+	m := NewFileModel(filepath.Join(t.TempDir(), "branches.json"))
+
+	for _, b := range []Branch{
+		{Branch: "feature", Network: config.Testnet, Stage: 3},
+		{Branch: "main", Network: config.Mainnet, Stage: 1},
+		{Branch: "dev", Network: config.Testnet, Stage: 2},
+	} {
+		err := m.Create(ctx, b.Branch, b.Network, b.Stage)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	branches, err := m.GetTestnetBranches(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(branches) != 2 || branches[0].Branch != "dev" || branches[1].Branch != "feature" {
+		t.Errorf("GetTestnetBranches() = %+v, want dev and feature sorted by stage", branches)
+	}
+
+	exists, err := m.StageExists(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Error("stage 3 doesn't exist")
+	}
+
+	err = m.DeleteStage(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exists, err = m.StageExists(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Error("deleted stage exists")
+	}
+	branches, err = m.GetTestnetBranches(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(branches) != 1 || branches[0].Branch != "dev" {
+		t.Errorf("GetTestnetBranches() = %+v, want dev", branches)
+	}
+}
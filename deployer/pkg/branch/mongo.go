@@ -0,0 +1,73 @@
+package branch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoModel struct {
+	coll *mongo.Collection
+}
+
+func NewMongoModel(coll *mongo.Collection) Model {
+	return mongoModel{
+		coll: coll,
+	}
+}
+
+func (m mongoModel) GetTestnetBranches(ctx context.Context) ([]Branch, error) {
+	sortOptions := options.Find().SetSort(bson.M{"stage": 1})
+	cur, err := m.coll.Find(ctx, bson.M{
+		"network": config.Testnet,
+	}, sortOptions)
+	if err != nil {
+		return nil, fmt.Errorf("m.coll.Find: %w", err)
+	}
+
+	var docs []Branch
+	err = cur.All(ctx, &docs)
+	if err != nil {
+		return nil, fmt.Errorf("cur.All: %w", err)
+	}
+
+	return docs, err
+}
+
+func (m mongoModel) StageExists(ctx context.Context, stage uint32) (bool, error) {
+	err := m.coll.FindOne(ctx, bson.M{
+		"stage": stage,
+	}).Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, fmt.Errorf("m.coll.FindOne: %w", err)
+	}
+	return true, nil
+}
+
+func (m mongoModel) Create(ctx context.Context, branch string, network config.Network, stage uint32) error {
+	_, err := m.coll.InsertOne(ctx, Branch{
+		Branch:  branch,
+		Network: network,
+		Stage:   stage,
+	})
+	if err != nil {
+		return fmt.Errorf("m.coll.InsertOne: %w", err)
+	}
+	return nil
+}
+
+func (m mongoModel) DeleteStage(ctx context.Context, stage uint32) error {
+	_, err := m.coll.DeleteMany(ctx, bson.M{"stage": stage})
+	if err != nil {
+		return fmt.Errorf("m.coll.DeleteMany: %w", err)
+	}
+	return nil
+}
@@ -7,24 +7,36 @@ import (
 	"io"
 	"os"
 	"path"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog"
+	"github.com/waves-exchange/contracts/deployer/pkg/audit"
+	"github.com/waves-exchange/contracts/deployer/pkg/compiler"
+	"github.com/waves-exchange/contracts/deployer/pkg/confirm"
 	"github.com/waves-exchange/contracts/deployer/pkg/contract"
+	"github.com/waves-exchange/contracts/deployer/pkg/datawriter"
+	"github.com/waves-exchange/contracts/deployer/pkg/fee"
+	"github.com/waves-exchange/contracts/deployer/pkg/journal"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
+	"github.com/waves-exchange/contracts/deployer/pkg/simulate"
 	"github.com/waves-exchange/contracts/deployer/pkg/tools"
-	"github.com/wavesplatform/gowaves/pkg/client"
+	"github.com/waves-exchange/contracts/deployer/pkg/verifier"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 )
 
 type Contract struct {
 	logger      zerolog.Logger
-	client      *client.Client
+	node        node.Node
+	compiler    compiler.Compiler
 	model       contract.Model
 	basePrv     crypto.SecretKey
 	signerPrv   crypto.SecretKey
-	signers     []crypto.SecretKey
-	gazPrv      crypto.SecretKey
+	signers     []signer.Signer
+	gaz         signer.Signer
 	networkByte proto.Scheme
 	tag         string
 	filename    string
@@ -32,15 +44,17 @@ type Contract struct {
 	compact     bool
 	data        []proto.DataEntry
 	constructor []*proto.InvokeScriptWithProofs
+	journal     *journal.Journal
+	confirmOpts confirm.Options
 }
 
 func New(
 	networkByte proto.Scheme,
-	client *client.Client,
+	nd node.Node,
 	model contract.Model,
 	basePrv crypto.SecretKey,
 	signerPrv crypto.SecretKey,
-	gazPrv crypto.SecretKey,
+	gaz signer.Signer,
 	tag string,
 	filename string,
 	stage uint32,
@@ -52,21 +66,46 @@ func New(
 		logger: zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).Level(zerolog.DebugLevel).With().Timestamp().
 			Caller().Logger(),
 		networkByte: networkByte,
-		client:      client,
+		node:        nd,
+		compiler:    compiler.NewNode(nd),
 		model:       model,
 		basePrv:     basePrv,
 		signerPrv:   signerPrv,
-		signers:     []crypto.SecretKey{basePrv, signerPrv},
-		gazPrv:      gazPrv,
+		signers:     []signer.Signer{signer.NewKey(basePrv), signer.NewKey(signerPrv)},
+		gaz:         gaz,
 		tag:         tag,
 		filename:    filename,
 		stage:       stage,
 		compact:     compact,
 		data:        data,
 		constructor: constructor,
+		confirmOpts: confirm.DefaultOptions(),
 	}
 }
 
+// WithJournal makes Deploy resumable: steps are journaled, already applied ones are skipped
+func (c Contract) WithJournal(j *journal.Journal) Contract {
+	c.journal = j
+	return c
+}
+
+// WithConfirm sets confirmations to wait for and rebroadcast limits
+func (c Contract) WithConfirm(opts confirm.Options) Contract {
+	c.confirmOpts = opts
+	return c
+}
+
+// WithCompiler replaces node compilation
+func (c Contract) WithCompiler(comp compiler.Compiler) Contract {
+	c.compiler = comp
+	return c
+}
+
+// step names of contracts don't collide with fund/<account> and issue/<ref> of create-stage
+func (c Contract) step(name string) string {
+	return "contract/" + c.tag + "/" + name
+}
+
 func (c Contract) DeployAndSave(ctx context.Context) error {
 	err := c.Deploy(ctx)
 	if err != nil {
@@ -82,6 +121,8 @@ func (c Contract) DeployAndSave(ctx context.Context) error {
 }
 
 func (c Contract) Deploy(ctx context.Context) error {
+	ctx = audit.WithSource(ctx, c.filename, c.tag)
+
 	addr, err := proto.NewAddressFromPublicKey(c.networkByte, crypto.GeneratePublicKey(c.basePrv))
 	if err != nil {
 		return fmt.Errorf("proto.NewAddressFromPublicKey: %w", err)
@@ -136,56 +177,127 @@ func (c Contract) selfTransferFee(ctx context.Context) error {
 		return fmt.Errorf("proto.NewAddressFromPublicKey: %w", err)
 	}
 
-	err = tools.SignBroadcastWait(
-		ctx,
-		c.networkByte,
-		c.client,
-		proto.NewUnsignedTransferWithProofs(
-			3,
-			crypto.GeneratePublicKey(c.gazPrv),
-			proto.NewOptionalAssetWaves(),
-			proto.NewOptionalAssetWaves(),
-			tools.Timestamp(),
-			100000000,
-			100000,
-			proto.NewRecipientFromAddress(addr),
-			nil,
-		),
-		c.gazPrv,
+	tx := proto.NewUnsignedTransferWithProofs(
+		3,
+		c.gaz.PublicKey(),
+		proto.NewOptionalAssetWaves(),
+		proto.NewOptionalAssetWaves(),
+		tools.Timestamp(),
+		100000000,
+		0,
+		proto.NewRecipientFromAddress(addr),
+		nil,
 	)
+	err = fee.Set(ctx, c.node, tx)
 	if err != nil {
-		return fmt.Errorf("tools.SignBroadcastWait: %w", err)
+		return fmt.Errorf("fee.Set: %w", err)
 	}
-	return nil
+
+	funded := journal.Funded(c.node, addr, tx.Amount)
+	return c.journal.Apply(ctx, c.node, c.step("transfer"), tx, funded, func(ctx context.Context) error {
+		e := tools.SignBroadcastWait(ctx, c.networkByte, c.node, tx, c.gaz, c.confirmOpts)
+		if e != nil {
+			return fmt.Errorf("tools.SignBroadcastWait: %w", e)
+		}
+		return nil
+	})
 }
 
+// setData writes data entries in order, one journal step per data transaction
 func (c Contract) setData(ctx context.Context) error {
-	tx := proto.NewUnsignedDataWithProofs(
-		2,
-		crypto.GeneratePublicKey(c.basePrv),
-		500000,
-		tools.Timestamp(),
-	)
-	for _, data := range c.data {
-		err := tx.AppendEntry(data)
+	txs, err := datawriter.Pack(ctx, c.node, crypto.GeneratePublicKey(c.basePrv), c.data)
+	if err != nil {
+		return fmt.Errorf("datawriter.Pack: %w", err)
+	}
+
+	for i, tx := range txs {
+		tx := tx
+		name := c.step("data")
+		if len(txs) > 1 {
+			name += "/" + strconv.Itoa(i)
+		}
+		err = c.journal.Apply(ctx, c.node, name, tx, c.dataApplied(tx.Entries), func(ctx context.Context) error {
+			return c.signBroadcastWait(ctx, tx)
+		})
 		if err != nil {
-			return fmt.Errorf("tx.AppendEntry: %w", err)
+			return err
 		}
 	}
+	return nil
+}
 
-	err := tools.TrySignBroadcastWait(ctx, c.networkByte, c.client, tx, c.signers)
-	if err != nil {
-		return fmt.Errorf("tools.SignBroadcastWait: %w", err)
+// dataApplied checks all entries are already on chain
+func (c Contract) dataApplied(entries proto.DataEntries) journal.Applied {
+	return func(ctx context.Context) (bool, string, error) {
+		addr, err := proto.NewAddressFromPublicKey(c.networkByte, crypto.GeneratePublicKey(c.basePrv))
+		if err != nil {
+			return false, "", fmt.Errorf("proto.NewAddressFromPublicKey: %w", err)
+		}
+
+		for _, entry := range entries {
+			current, e := c.node.DataKey(ctx, addr, entry.GetKey())
+			if e != nil {
+				return false, "", fmt.Errorf("c.node.DataKey: %w", e)
+			}
+			if entry.GetValueType() == proto.DataDelete {
+				if current != nil {
+					return false, "", nil
+				}
+				continue
+			}
+			if !reflect.DeepEqual(current, entry) {
+				return false, "", nil
+			}
+		}
+		return true, "", nil
 	}
+}
 
-	return nil
+// invoked checks the same function of the dApp is called by the sender with the same arguments and payments
+func (c Contract) invoked(tx *proto.InvokeScriptWithProofs) journal.Applied {
+	return func(ctx context.Context) (bool, string, error) {
+		sender, err := proto.NewAddressFromPublicKey(c.networkByte, tx.SenderPK)
+		if err != nil {
+			return false, "", fmt.Errorf("proto.NewAddressFromPublicKey: %w", err)
+		}
+
+		id, err := journal.Sent(ctx, c.node, sender, func(sent proto.Transaction) bool {
+			inv, ok := sent.(*proto.InvokeScriptWithProofs)
+			return ok &&
+				inv.ScriptRecipient.String() == tx.ScriptRecipient.String() &&
+				reflect.DeepEqual(inv.FunctionCall, tx.FunctionCall) &&
+				reflect.DeepEqual(inv.Payments, tx.Payments)
+		})
+		if err != nil {
+			return false, "", fmt.Errorf("journal.Sent: %w", err)
+		}
+		return id != "", id, nil
+	}
 }
 
 func (c Contract) callConstructor(ctx context.Context) error {
-	for _, tx := range c.constructor {
-		err := tools.TrySignBroadcastWait(ctx, c.networkByte, c.client, tx, c.signers)
+	for i, tx := range c.constructor {
+		tx := tx
+		name := c.step("constructor/" + strconv.Itoa(i) + "/" + tx.FunctionCall.Name())
+		// fee depends on the script set by the previous step
+		if tx.Fee == 0 {
+			err := fee.Set(ctx, c.node, tx)
+			if err != nil {
+				return fmt.Errorf("fee.Set: %w", err)
+			}
+		}
+		err := c.journal.Apply(ctx, c.node, name, tx, c.invoked(tx), func(ctx context.Context) error {
+			ev, e := simulate.Invoke(ctx, c.node, tx)
+			if e != nil {
+				return fmt.Errorf("simulate.Invoke %s: %w", tx.FunctionCall.Name(), e)
+			}
+			c.logger.Info().Str("tag", c.tag).Str("function", tx.FunctionCall.Name()).
+				Msg("constructor evaluated:\n" + simulate.Describe(ev))
+
+			return c.signBroadcastWait(ctx, tx)
+		})
 		if err != nil {
-			return fmt.Errorf("tools.SignBroadcastWait: %w", err)
+			return err
 		}
 	}
 	return nil
@@ -197,53 +309,94 @@ func (c Contract) setScript(ctx context.Context) error {
 		return fmt.Errorf("os.Open: %w", err)
 	}
 
-	var script string
-	if c.compact {
-		sc, e := tools.CompactScript(ctx, c.client, f)
+	defer func() {
+		_ = f.Close()
+	}()
+
+	body, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("io.ReadAll: %w", err)
+	}
+
+	compiled, err := c.compiler.Compile(ctx, body, c.compact)
+	if err != nil {
+		return fmt.Errorf("c.compiler.Compile: %w", err)
+	}
+
+	scriptBytes, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(compiled.Script, "base64:"))
+	if err != nil {
+		return fmt.Errorf("base64.StdEncoding.DecodeString: %w", err)
+	}
+
+	tx := proto.NewUnsignedSetScriptWithProofs(
+		2,
+		crypto.GeneratePublicKey(c.basePrv),
+		scriptBytes,
+		0,
+		tools.Timestamp(),
+	)
+	err = fee.Set(ctx, c.node, tx)
+	if err != nil {
+		return fmt.Errorf("fee.Set: %w", err)
+	}
+
+	applied := func(ctx context.Context) (bool, string, error) {
+		addr, e := proto.NewAddressFromPublicKey(c.networkByte, crypto.GeneratePublicKey(c.basePrv))
 		if e != nil {
-			return fmt.Errorf("tools.CompactScript: %w", e)
+			return false, "", fmt.Errorf("proto.NewAddressFromPublicKey: %w", e)
 		}
-		script = sc
-	} else {
-		body, e := io.ReadAll(f)
+		current, e := c.node.Script(ctx, addr)
 		if e != nil {
-			return fmt.Errorf("io.ReadAll: %w", e)
+			return false, "", fmt.Errorf("c.node.Script: %w", e)
 		}
+		return strings.TrimPrefix(current, "base64:") == strings.TrimPrefix(compiled.Script, "base64:"), "", nil
+	}
 
-		sc, _, e := c.client.Utils.ScriptCompile(ctx, string(body))
-		if e != nil {
-			return fmt.Errorf("c.client.Utils.ScriptCompile: %w", e)
-		}
-		script = sc.Script
+	return c.journal.Apply(ctx, c.node, c.step("setScript"), tx, applied, func(ctx context.Context) error {
+		return c.signBroadcastWait(ctx, tx)
+	})
+}
+
+// signBroadcastWait signs with keys the contract verifier accepts, base key before the script is set
+// and signer key after it
+func (c Contract) signBroadcastWait(ctx context.Context, tx proto.Transaction) error {
+	_, err := tx.Validate(c.networkByte)
+	if err != nil {
+		return fmt.Errorf("tx.Validate: %w", err)
 	}
 
-	scriptBytes, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(script, "base64:"))
+	logger := c.logger.With().Str("tag", c.tag).Logger()
+	err = verifier.Sign(ctx, logger, c.node, tx, c.signers)
 	if err != nil {
-		return fmt.Errorf("base64.StdEncoding.DecodeString: %w", err)
+		return fmt.Errorf("verifier.Sign: %w", err)
 	}
-	setScriptFee := tools.CalcSetScriptFee(scriptBytes)
 
-	err = tools.TrySignBroadcastWait(
-		ctx,
-		c.networkByte,
-		c.client,
-		proto.NewUnsignedSetScriptWithProofs(
-			2,
-			crypto.GeneratePublicKey(c.basePrv),
-			scriptBytes,
-			setScriptFee,
-			tools.Timestamp(),
-		),
-		c.signers,
-	)
+	opts := c.confirmOpts
+	opts.Logger = logger
+	// expired tx is signed again by the keys the verifier accepts now
+	err = tools.BroadcastWait(ctx, c.networkByte, c.node, tx, opts, func(ctx context.Context, tx proto.Transaction) error {
+		return verifier.Sign(ctx, logger, c.node, tx, c.signers)
+	})
 	if err != nil {
-		return fmt.Errorf("tools.SignBroadcastWait: %w", err)
+		logger.Error().Err(err).Msg("broadcast failed")
+		return fmt.Errorf("tools.BroadcastWait: %w", err)
 	}
 	return nil
 }
 
+// Save creates contract record, existing record of the stage with the same tag is kept as is
 func (c Contract) Save(ctx context.Context) error {
-	err := c.model.Create(
+	contracts, err := c.model.GetStage(ctx, c.stage)
+	if err != nil {
+		return fmt.Errorf("c.model.GetStage: %w", err)
+	}
+	for _, cn := range contracts {
+		if cn.Tag == c.tag {
+			return nil
+		}
+	}
+
+	err = c.model.Create(
 		ctx,
 		c.filename,
 		c.stage,
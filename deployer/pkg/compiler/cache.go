@@ -0,0 +1,136 @@
+package compiler
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/jsonfile"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Cache is content-addressed on-disk cache of compilation results shared across runs,
+// the key is blake2b of the source, compact flag, network and compiler version.
+// Results are kept in memory too, so each script is compiled or read once per run.
+// Safe for concurrent use, concurrent calls with the same key wait for the first one
+type Cache struct {
+	compiler Compiler
+	dir      string
+	scheme   proto.Scheme
+
+	mu      *sync.Mutex
+	version string
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	once *sync.Once
+	res  node.CompileResult
+	err  error
+}
+
+func NewCache(compiler Compiler, dir string, scheme proto.Scheme) *Cache {
+	return &Cache{
+		compiler: compiler,
+		dir:      dir,
+		scheme:   scheme,
+		mu:       &sync.Mutex{},
+		entries:  map[string]*cacheEntry{},
+	}
+}
+
+func (c *Cache) key(version string, body []byte, compact bool) string {
+	source := blake2b.Sum256(body)
+	h, _ := blake2b.New256(nil)
+	_, _ = h.Write(source[:])
+	_, _ = h.Write([]byte(strconv.FormatBool(compact)))
+	_, _ = h.Write([]byte{0, c.scheme, 0})
+	_, _ = h.Write([]byte(version))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cache) Compile(ctx context.Context, body []byte, compact bool) (node.CompileResult, error) {
+	version, err := c.Version(ctx)
+	if err != nil {
+		return node.CompileResult{}, fmt.Errorf("c.Version: %w", err)
+	}
+	key := c.key(version, body, compact)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &cacheEntry{once: &sync.Once{}}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.res, entry.err = c.load(ctx, key, body, compact)
+	})
+	if entry.err != nil {
+		// don't keep failures, e.g. node timeout, the next call tries again
+		c.mu.Lock()
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+		return node.CompileResult{}, entry.err
+	}
+	return entry.res, nil
+}
+
+func (c *Cache) load(ctx context.Context, key string, body []byte, compact bool) (node.CompileResult, error) {
+	name := filepath.Join(c.dir, key+".json")
+
+	_, err := os.Stat(name)
+	if err == nil {
+		var res node.CompileResult
+		e := jsonfile.Load(name, &res)
+		if e != nil {
+			return node.CompileResult{}, fmt.Errorf("jsonfile.Load: %w", e)
+		}
+		return res, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return node.CompileResult{}, fmt.Errorf("os.Stat: %w", err)
+	}
+
+	res, err := c.compiler.Compile(ctx, body, compact)
+	if err != nil {
+		return node.CompileResult{}, err
+	}
+
+	err = jsonfile.Save(name, res)
+	if err != nil {
+		return node.CompileResult{}, fmt.Errorf("jsonfile.Save: %w", err)
+	}
+	return res, nil
+}
+
+// Version is asked once, it doesn't change during the run
+func (c *Cache) Version(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.version != "" {
+		return c.version, nil
+	}
+
+	v, err := c.compiler.Version(ctx)
+	if err != nil {
+		return "", fmt.Errorf("c.compiler.Version: %w", err)
+	}
+	c.version = v
+	return v, nil
+}
+
+// compile-time check
+var _ Compiler = (*Cache)(nil)
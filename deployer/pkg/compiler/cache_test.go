@@ -0,0 +1,160 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// counter counts compilations, the source "fail" isn't compiled
+type counter struct {
+	mu      sync.Mutex
+	calls   int
+	version string
+}
+
+func (c *counter) Compile(_ context.Context, body []byte, compact bool) (node.CompileResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if string(body) == "fail" {
+		return node.CompileResult{}, errors.New("node timeout")
+	}
+	return node.CompileResult{Script: string(body), Complexity: len(body)}, nil
+}
+
+func (c *counter) Version(_ context.Context) (string, error) {
+	return c.version, nil
+}
+
+func (c *counter) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	comp := &counter{version: "node 1.4.0"}
+	c := NewCache(comp, dir, proto.TestNetScheme)
+
+	for i := 0; i < 2; i++ {
+		res, err := c.Compile(ctx, []byte("func a() = []"), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Script != "func a() = []" {
+			t.Errorf("cached script = %q", res.Script)
+		}
+	}
+	if n := comp.count(); n != 1 {
+		t.Errorf("source is compiled %d times, want 1", n)
+	}
+
+	// the next run reads the result from disk
+	_, err := NewCache(comp, dir, proto.TestNetScheme).Compile(ctx, []byte("func a() = []"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := comp.count(); n != 1 {
+		t.Errorf("cached source is compiled by the next run")
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("cache has %d files, want 1", len(files))
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	body := []byte("func a() = []")
+
+	compile := func(version string, scheme proto.Scheme, compact bool) int {
+		comp := &counter{version: version}
+		_, err := NewCache(comp, dir, scheme).Compile(ctx, body, compact)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return comp.count()
+	}
+
+	compile("node 1.4.0", proto.TestNetScheme, false)
+	for _, tc := range []struct {
+		name    string
+		version string
+		scheme  proto.Scheme
+		compact bool
+		calls   int
+	}{
+		{name: "same", version: "node 1.4.0", scheme: proto.TestNetScheme, calls: 0},
+		{name: "compact", version: "node 1.4.0", scheme: proto.TestNetScheme, compact: true, calls: 1},
+		{name: "network", version: "node 1.4.0", scheme: proto.MainNetScheme, calls: 1},
+		{name: "version", version: "node 1.4.1", scheme: proto.TestNetScheme, calls: 1},
+	} {
+		if n := compile(tc.version, tc.scheme, tc.compact); n != tc.calls {
+			t.Errorf("%s: compiled %d times, want %d", tc.name, n, tc.calls)
+		}
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	comp := &counter{version: "node 1.4.0"}
+	c := NewCache(comp, t.TempDir(), proto.TestNetScheme)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := c.Compile(context.Background(), []byte("func a() = []"), false)
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := comp.count(); n != 1 {
+		t.Errorf("concurrent calls compiled %d times, want 1", n)
+	}
+}
+
+func TestCacheFailure(t *testing.T) {
+	ctx := context.Background()
+	comp := &counter{version: "node 1.4.0"}
+	c := NewCache(comp, t.TempDir(), proto.TestNetScheme)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Compile(ctx, []byte("fail"), false)
+		if err == nil {
+			t.Fatal("failure isn't returned")
+		}
+	}
+	if n := comp.count(); n != 2 {
+		t.Errorf("failed source is compiled %d times, want 2", n)
+	}
+}
+
+func TestNewCache(t *testing.T) {
+	opts := Options{Backend: BackendNode, Node: node.NewFake(proto.TestNetScheme), CacheDir: t.TempDir()}
+	if _, err := New(opts); err == nil {
+		t.Error("cache without network is created")
+	}
+	opts.Scheme = proto.TestNetScheme
+	c, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.(*Cache); !ok {
+		t.Errorf("New() = %T, want cache", c)
+	}
+}
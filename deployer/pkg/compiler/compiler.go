@@ -0,0 +1,73 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Compiler compiles RIDE source, the result is the same as node /utils/script/compileCode returns
+type Compiler interface {
+	Compile(ctx context.Context, body []byte, compact bool) (node.CompileResult, error)
+	// Version identifies compiler output, scripts compiled by different versions may differ
+	Version(ctx context.Context) (string, error)
+}
+
+type Backend string
+
+const (
+	BackendNode  Backend = "node"
+	BackendLocal Backend = "local"
+)
+
+type Options struct {
+	Backend Backend
+	// Node compiles scripts for node backend and is the reference for drift check
+	Node node.Node
+	// Command runs local compiler, see NewLocal
+	Command string
+	// Version is pinned version of local compiler
+	Version string
+	// CacheDir enables on-disk cache if not empty
+	CacheDir string
+	// Scheme is the network scripts are compiled for, results of different networks are cached apart
+	Scheme proto.Scheme
+	// DriftCheck compiles every script by node too and fails on any difference
+	DriftCheck bool
+}
+
+func New(opts Options) (Compiler, error) {
+	var c Compiler
+	switch opts.Backend {
+	case BackendNode:
+		if opts.Node == nil {
+			return nil, errors.New("node required")
+		}
+		c = NewNode(opts.Node)
+	case BackendLocal:
+		local, err := NewLocal(opts.Command, opts.Version)
+		if err != nil {
+			return nil, fmt.Errorf("NewLocal: %w", err)
+		}
+		c = local
+		if opts.DriftCheck {
+			if opts.Node == nil {
+				return nil, errors.New("node required for drift check")
+			}
+			c = NewDriftCheck(c, NewNode(opts.Node))
+		}
+	default:
+		return nil, fmt.Errorf("unknown compiler backend: %s", opts.Backend)
+	}
+
+	if opts.CacheDir != "" {
+		if opts.Scheme == 0 {
+			return nil, errors.New("scheme required for cache")
+		}
+		c = NewCache(c, opts.CacheDir, opts.Scheme)
+	}
+	return c, nil
+}
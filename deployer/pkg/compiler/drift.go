@@ -0,0 +1,90 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+)
+
+var ErrDrift = errors.New("compilation results differ")
+
+// DriftCheck compiles by both compilers and returns result of the first one only if they are equal
+type DriftCheck struct {
+	compiler  Compiler
+	reference Compiler
+}
+
+func NewDriftCheck(compiler, reference Compiler) *DriftCheck {
+	return &DriftCheck{compiler: compiler, reference: reference}
+}
+
+func (d *DriftCheck) Compile(ctx context.Context, body []byte, compact bool) (node.CompileResult, error) {
+	res, err := d.compiler.Compile(ctx, body, compact)
+	if err != nil {
+		return node.CompileResult{}, fmt.Errorf("d.compiler.Compile: %w", err)
+	}
+	ref, err := d.reference.Compile(ctx, body, compact)
+	if err != nil {
+		return node.CompileResult{}, fmt.Errorf("d.reference.Compile: %w", err)
+	}
+
+	diff := Compare(res, ref)
+	if len(diff) != 0 {
+		return node.CompileResult{}, fmt.Errorf("%w: %s", ErrDrift, strings.Join(diff, ", "))
+	}
+	return res, nil
+}
+
+func (d *DriftCheck) Version(ctx context.Context) (string, error) {
+	v, err := d.compiler.Version(ctx)
+	if err != nil {
+		return "", fmt.Errorf("d.compiler.Version: %w", err)
+	}
+	ref, err := d.reference.Version(ctx)
+	if err != nil {
+		return "", fmt.Errorf("d.reference.Version: %w", err)
+	}
+	return v + " checked by " + ref, nil
+}
+
+// Compare lists differences of script and complexities, extra fee is node fee policy and isn't compared
+func Compare(a, b node.CompileResult) []string {
+	var diff []string
+	if strings.TrimPrefix(a.Script, "base64:") != strings.TrimPrefix(b.Script, "base64:") {
+		diff = append(diff, "script")
+	}
+	if a.Complexity != b.Complexity {
+		diff = append(diff, fmt.Sprintf("complexity %d != %d", a.Complexity, b.Complexity))
+	}
+	if a.VerifierComplexity != b.VerifierComplexity {
+		diff = append(diff, fmt.Sprintf("verifier complexity %d != %d", a.VerifierComplexity, b.VerifierComplexity))
+	}
+
+	names := map[string]struct{}{}
+	for name := range a.CallableComplexities {
+		names[name] = struct{}{}
+	}
+	for name := range b.CallableComplexities {
+		names[name] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		ca, okA := a.CallableComplexities[name]
+		cb, okB := b.CallableComplexities[name]
+		if okA != okB || ca != cb {
+			diff = append(diff, fmt.Sprintf("callable %s complexity %d != %d", name, ca, cb))
+		}
+	}
+	return diff
+}
+
+// compile-time check
+var _ Compiler = (*DriftCheck)(nil)
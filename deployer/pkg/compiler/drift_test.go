@@ -0,0 +1,95 @@
+package compiler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// stub returns the same result for any source and counts calls
+type stub struct {
+	res     node.CompileResult
+	version string
+	calls   int
+}
+
+func (s *stub) Compile(_ context.Context, _ []byte, _ bool) (node.CompileResult, error) {
+	s.calls++
+	return s.res, nil
+}
+
+func (s *stub) Version(_ context.Context) (string, error) {
+	return s.version, nil
+}
+
+func TestDriftCheck(t *testing.T) {
+	ctx := context.Background()
+	res := node.CompileResult{
+		Script:               "base64:AAIF",
+		Complexity:           100,
+		CallableComplexities: map[string]int{"swap": 100},
+		ExtraFee:             400000,
+	}
+
+	// node returns script without prefix and its own fee policy
+	ref := res
+	ref.Script = "AAIF"
+	ref.ExtraFee = 0
+	d := NewDriftCheck(&stub{res: res, version: "local 1.0.0"}, &stub{res: ref, version: "node 1.4.0"})
+	got, err := d.Compile(ctx, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Script != res.Script {
+		t.Errorf("script = %s, want %s of the checked compiler", got.Script, res.Script)
+	}
+	v, err := d.Version(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "local 1.0.0 checked by node 1.4.0" {
+		t.Errorf("Version() = %q", v)
+	}
+
+	drifted := res
+	drifted.CallableComplexities = map[string]int{"swap": 101, "get": 1}
+	d = NewDriftCheck(&stub{res: res}, &stub{res: drifted})
+	_, err = d.Compile(ctx, nil, false)
+	if !errors.Is(err, ErrDrift) {
+		t.Fatalf("Compile() error = %v, want %v", err, ErrDrift)
+	}
+	for _, s := range []string{"callable get complexity 0 != 1", "callable swap complexity 100 != 101"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("drift %q isn't reported: %v", s, err)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	nd := node.NewFake(proto.TestNetScheme)
+	for _, tc := range []struct {
+		name string
+		opts Options
+		ok   bool
+	}{
+		{name: "node", opts: Options{Backend: BackendNode, Node: nd}, ok: true},
+		{name: "node without node", opts: Options{Backend: BackendNode}},
+		{name: "local", opts: Options{Backend: BackendLocal, Command: "ride-compiler", Version: "1.0.0"}, ok: true},
+		{name: "local without version", opts: Options{Backend: BackendLocal, Command: "ride-compiler"}},
+		{name: "drift check without node", opts: Options{
+			Backend: BackendLocal, Command: "ride-compiler", Version: "1.0.0", DriftCheck: true,
+		}},
+		{name: "unknown", opts: Options{Backend: "remote", Node: nd}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New(tc.opts)
+			if (err == nil) != tc.ok {
+				t.Errorf("New() error = %v, want ok %t", err, tc.ok)
+			}
+		})
+	}
+}
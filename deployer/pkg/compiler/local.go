@@ -0,0 +1,71 @@
+package compiler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+)
+
+const compactPlaceholder = "{compact}"
+
+// Local runs locally installed RIDE compiler as a subprocess.
+// Source is written to its stdin, {compact} in the command is replaced with true or false,
+// stdout must be JSON in /utils/script/compileCode response format
+type Local struct {
+	args    []string
+	version string
+}
+
+// NewLocal requires pinned version of the compiler, it's a part of the cache key
+func NewLocal(command, version string) (*Local, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("compiler command required")
+	}
+	if version == "" {
+		return nil, errors.New("compiler version required")
+	}
+	return &Local{args: args, version: version}, nil
+}
+
+func (l *Local) Compile(ctx context.Context, body []byte, compact bool) (node.CompileResult, error) {
+	args := make([]string, len(l.args))
+	for i, a := range l.args {
+		args[i] = strings.ReplaceAll(a, compactPlaceholder, strconv.FormatBool(compact))
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(body)
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return node.CompileResult{}, fmt.Errorf("cmd.Run: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var res node.CompileResult
+	err = json.Unmarshal(stdout.Bytes(), &res)
+	if err != nil {
+		return node.CompileResult{}, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	if res.Script == "" {
+		return node.CompileResult{}, errors.New("compiler returned empty script")
+	}
+	return res, nil
+}
+
+func (l *Local) Version(_ context.Context) (string, error) {
+	return "local " + l.version, nil
+}
+
+// compile-time check
+var _ Compiler = (*Local)(nil)
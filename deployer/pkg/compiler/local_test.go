@@ -0,0 +1,95 @@
+package compiler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+)
+
+const helperEnv = "COMPILER_TEST_HELPER"
+
+// TestHelperCompiler is the local compiler run by tests: the script is the compact flag and the source,
+// the source "fail" is a compilation error
+func TestHelperCompiler(t *testing.T) {
+	if os.Getenv(helperEnv) == "" {
+		t.Skip("run by local compiler tests only")
+	}
+
+	body, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		os.Exit(2)
+	}
+	if string(body) == "fail" {
+		fmt.Fprint(os.Stderr, "syntax error")
+		os.Exit(1)
+	}
+	compact := os.Args[len(os.Args)-1]
+	_ = json.NewEncoder(os.Stdout).Encode(node.CompileResult{
+		Script:               "base64:" + base64.StdEncoding.EncodeToString([]byte(compact+" "+string(body))),
+		Complexity:           len(body),
+		CallableComplexities: map[string]int{"call": len(body)},
+	})
+	os.Exit(0)
+}
+
+// helperCommand runs TestHelperCompiler as the local compiler
+func helperCommand(t *testing.T) string {
+	t.Helper()
+	t.Setenv(helperEnv, "1")
+	return os.Args[0] + " -test.run=^TestHelperCompiler$ -- " + compactPlaceholder
+}
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	l, err := NewLocal(helperCommand(t), "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, compact := range []bool{false, true} {
+		res, e := l.Compile(ctx, []byte("func call() = []"), compact)
+		if e != nil {
+			t.Fatal(e)
+		}
+		script, e := base64.StdEncoding.DecodeString(strings.TrimPrefix(res.Script, "base64:"))
+		if e != nil {
+			t.Fatal(e)
+		}
+		want := fmt.Sprintf("%t func call() = []", compact)
+		if string(script) != want {
+			t.Errorf("script = %q, want %q", script, want)
+		}
+		if res.CallableComplexities["call"] != len("func call() = []") {
+			t.Errorf("callable complexities = %v", res.CallableComplexities)
+		}
+	}
+
+	_, err = l.Compile(ctx, []byte("fail"), false)
+	if err == nil || !strings.Contains(err.Error(), "syntax error") {
+		t.Errorf("Compile() error = %v, want stderr of the compiler", err)
+	}
+
+	v, err := l.Version(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "local 1.0.0" {
+		t.Errorf("Version() = %q, want local 1.0.0", v)
+	}
+}
+
+func TestNewLocal(t *testing.T) {
+	if _, err := NewLocal("", "1.0.0"); err == nil {
+		t.Error("local compiler without command is created")
+	}
+	if _, err := NewLocal("ride-compiler", ""); err == nil {
+		t.Error("local compiler without pinned version is created")
+	}
+}
@@ -0,0 +1,36 @@
+package compiler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+)
+
+// Node compiles scripts by node /utils/script/compileCode
+type Node struct {
+	node node.Node
+}
+
+func NewNode(nd node.Node) *Node {
+	return &Node{node: nd}
+}
+
+func (n *Node) Compile(ctx context.Context, body []byte, compact bool) (node.CompileResult, error) {
+	res, err := n.node.Compile(ctx, body, compact)
+	if err != nil {
+		return node.CompileResult{}, fmt.Errorf("n.node.Compile: %w", err)
+	}
+	return res, nil
+}
+
+func (n *Node) Version(ctx context.Context) (string, error) {
+	v, err := n.node.Version(ctx)
+	if err != nil {
+		return "", fmt.Errorf("n.node.Version: %w", err)
+	}
+	return "node " + v, nil
+}
+
+// compile-time check
+var _ Compiler = (*Node)(nil)
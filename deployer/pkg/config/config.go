@@ -1,22 +1,65 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/wavesplatform/gowaves/pkg/proto"
 )
 
 type Config struct {
 	Network                      Network `required:"true"`
 	Branch                       string  `required:"true"`
 	Node                         string  `required:"true"`
-	MongoURI                     string  `required:"true"`
-	MongoDatabaseName            string  `required:"true"`
-	MongoCollectionBranches      string  `required:"true"`
-	MongoCollectionContracts     string  `required:"true"`
-	CompareLpScriptAddress       string  `required:"true"`
-	CompareLpStableScriptAddress string  `required:"true"`
-	FeeSeed                      string  `required:"true"`
+	MongoURI                     string
+	MongoDatabaseName            string
+	MongoCollectionBranches      string
+	MongoCollectionContracts     string
+	MongoCollectionMigrations    string `default:"migrations"`
+	MongoCollectionAudit         string `default:"audit"`
+	MongoCollectionJournal       string `default:"journal"`
+	CompareLpScriptAddress       string `required:"true"`
+	CompareLpStableScriptAddress string `required:"true"`
+	FeeSeed                      string `required:"true"`
+
+	// NodeRateLimit is requests per second to nodes, NodeRetries of 429, 5xx and network errors.
+	// Node, TestnetNode and MainnetNode may be comma separated lists, the next node is used if one fails
+	NodeRateLimit float64 `default:"10"`
+	NodeRetries   int     `default:"5"`
+
+	// Confirmations are blocks to wait for after transaction is mined, including its block
+	Confirmations int `default:"1"`
+
+	// Storage is 'mongo' or 'file', file backend keeps records in StorageDir/<network>
+	Storage    string `default:"mongo"`
+	StorageDir string `default:"storage"`
+
+	// Private keys of contracts are encrypted with the master key of MasterKeyFile or derived from MasterKeyPassphrase,
+	// they are kept in plain text if both are empty
+	MasterKeyFile       string
+	MasterKeyPassphrase string
+
+	// Dry-run computes plan of changes without broadcasting anything
+	DryRun   bool
+	PlanFile string `default:"../.github/artifacts/plan.json"`
+
+	// BatchFile collects mainnet transactions waiting for signatures with review metadata
+	BatchFile string `default:"../.github/artifacts/batch.json"`
+
+	// Compiler is 'node' or 'local', local compiler is run as CompilerCommand and
+	// its output is compared with node compilation if CompilerDriftCheck is set.
+	// Compilation results are cached in CompilerCacheDir shared with cli and compiler, empty disables cache
+	Compiler           string `default:"node"`
+	CompilerCommand    string
+	CompilerVersion    string
+	CompilerCacheDir   string `default:"../.cache/ride"`
+	CompilerDriftCheck bool
+
+	// RemoteSignerURL is signing service adding proofs to mainnet batch with keys it keeps,
+	// so CI doesn't hold them. RemoteSignerToken is sent as bearer token
+	RemoteSignerURL   string
+	RemoteSignerToken string
 
 	// Testnet only
 	TestnetNode     string
@@ -32,6 +75,17 @@ const (
 	Mainnet Network = "mainnet"
 )
 
+func (n Network) Scheme() (proto.Scheme, error) {
+	switch n {
+	case Testnet:
+		return proto.TestNetScheme, nil
+	case Mainnet:
+		return proto.MainNetScheme, nil
+	default:
+		return 0, fmt.Errorf("unknown network: %s", n)
+	}
+}
+
 func NewConfig() (Config, error) {
 	var cfg Config
 	err := envconfig.Process("", &cfg)
@@ -39,5 +93,15 @@ func NewConfig() (Config, error) {
 		return Config{}, fmt.Errorf("envconfig.Process: %w", err)
 	}
 
+	if cfg.Storage == "mongo" {
+		if cfg.MongoURI == "" ||
+			cfg.MongoDatabaseName == "" ||
+			cfg.MongoCollectionBranches == "" ||
+			cfg.MongoCollectionContracts == "" {
+			return Config{}, errors.New("mongo storage requires MONGOURI, MONGODATABASENAME, " +
+				"MONGOCOLLECTIONBRANCHES and MONGOCOLLECTIONCONTRACTS")
+		}
+	}
+
 	return cfg, nil
 }
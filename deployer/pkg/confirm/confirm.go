@@ -0,0 +1,287 @@
+package confirm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
+	"github.com/waves-exchange/contracts/deployer/pkg/txfields"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+var (
+	// ErrExpired is returned if the transaction timestamp is out of the window accepted by the node
+	// and the transaction can't be signed again, e.g. it's signed offline
+	ErrExpired = errors.New("transaction expired")
+	// ErrRejected is returned if the node rejects the transaction dropped from the pool
+	ErrRejected = errors.New("transaction rejected")
+	ErrTimeout  = errors.New("transaction is not confirmed in time")
+)
+
+const (
+	// maxAge is how old transaction timestamp may be relative to the block, plus a margin for block time
+	maxAge = 2*time.Hour + time.Minute
+	// missingPolls are polls the transaction is neither on chain nor in the pool before it's considered dropped,
+	// the pool of the next node may not have it yet after failover
+	missingPolls = 3
+)
+
+type Options struct {
+	// Confirmations are blocks including the one with the transaction, 1 is mined
+	Confirmations int
+	PollInterval  time.Duration
+	// Timeout limits the whole wait including rebroadcasts, 0 is no limit
+	Timeout time.Duration
+	// Rebroadcasts limits rebroadcasts of the transaction dropped from the pool
+	Rebroadcasts int
+	Logger       zerolog.Logger
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Confirmations: 1,
+		PollInterval:  time.Second,
+		Timeout:       20 * time.Minute,
+		Rebroadcasts:  3,
+		Logger:        zerolog.Nop(),
+	}
+}
+
+// Rebuild signs tx again, it's called with fresh timestamp and without proofs
+// when the expired transaction can't be mined anymore, so it's safe to send the new one
+type Rebuild func(ctx context.Context, tx proto.Transaction) error
+
+// Wait waits until broadcast tx has opts.Confirmations, the transaction dropped from the pool is broadcast again,
+// the expired one is rebuilt if rebuild isn't nil, tx is changed in place then
+func Wait(ctx context.Context, nd node.Node, tx proto.Transaction, opts Options, rebuild Rebuild) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	var (
+		missing      int
+		rebroadcasts int
+		lastHeight   uint64
+	)
+	for {
+		id, err := txID(nd.Scheme(), tx)
+		if err != nil {
+			return err
+		}
+		log := opts.Logger.With().Str("txId", id.String()).Logger()
+
+		info, err := nd.TransactionInfo(ctx, id)
+		if err == nil {
+			missing = 0
+			h, e := nd.Height(ctx)
+			if e != nil {
+				log.Warn().Err(e).Msg("nd.Height")
+			} else if conf := int(h) - int(info.GetHeight()) + 1; conf >= opts.Confirmations {
+				return nil
+			} else if h != lastHeight {
+				lastHeight = h
+				log.Debug().Uint64("height", uint64(info.GetHeight())).Int("confirmations", conf).
+					Msg("waiting for confirmations")
+			}
+		} else {
+			inPool, e := nd.InUnconfirmed(ctx, id)
+			switch {
+			case e != nil:
+				log.Warn().Err(e).Msg("nd.InUnconfirmed")
+			case expired(tx):
+				// the node won't put it to a block, it's removed from the pool by the node eventually
+				e = renew(ctx, log, nd, tx, rebuild)
+				if e != nil {
+					return e
+				}
+				missing = 0
+			case inPool:
+				missing = 0
+			default:
+				missing++
+				if missing < missingPolls {
+					break
+				}
+				if rebroadcasts >= opts.Rebroadcasts {
+					return fmt.Errorf("%w: %s is dropped from the pool after %d rebroadcasts", ErrRejected, id, rebroadcasts)
+				}
+				rebroadcasts++
+				missing = 0
+				e = nd.Broadcast(ctx, tx)
+				switch {
+				case e == nil:
+					log.Info().Int("rebroadcast", rebroadcasts).Msg("transaction dropped from the pool is broadcast again")
+				case onChain(ctx, nd, id):
+				case isExpired(e):
+					e = renew(ctx, log, nd, tx, rebuild)
+					if e != nil {
+						return e
+					}
+				default:
+					return fmt.Errorf("%w: %s: %v", ErrRejected, id, e)
+				}
+			}
+		}
+
+		err = sleep(ctx, opts.PollInterval)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("%w: %s in %s", ErrTimeout, id, opts.Timeout)
+			}
+			return err
+		}
+	}
+}
+
+// WaitID waits while the transaction of a previous run is in the pool, it's true if the transaction is on chain
+// and false if it's neither on chain nor in the pool, so it may be built and sent again
+func WaitID(ctx context.Context, nd node.Node, id crypto.Digest, opts Options) (bool, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	missing := 0
+	for {
+		if onChain(ctx, nd, id) {
+			return true, nil
+		}
+		inPool, err := nd.InUnconfirmed(ctx, id)
+		switch {
+		case err != nil:
+			opts.Logger.Warn().Err(err).Str("txId", id.String()).Msg("nd.InUnconfirmed")
+		case inPool:
+			missing = 0
+		default:
+			missing++
+			if missing >= missingPolls {
+				return false, nil
+			}
+		}
+
+		err = sleep(ctx, opts.PollInterval)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return false, fmt.Errorf("%w: %s in %s", ErrTimeout, id, opts.Timeout)
+			}
+			return false, err
+		}
+	}
+}
+
+type rebuiltKey struct{}
+
+// OnRebuilt calls fn with the new id of rebuilt transaction before it's broadcast
+func OnRebuilt(ctx context.Context, fn func(ctx context.Context, id crypto.Digest) error) context.Context {
+	return context.WithValue(ctx, rebuiltKey{}, fn)
+}
+
+// renew rebuilds expired tx with fresh timestamp and broadcasts it,
+// it's checked on chain again, so a failed info request doesn't make a duplicate
+func renew(ctx context.Context, log zerolog.Logger, nd node.Node, tx proto.Transaction, rebuild Rebuild) error {
+	id, err := txID(nd.Scheme(), tx)
+	if err != nil {
+		return err
+	}
+	if onChain(ctx, nd, id) {
+		return nil
+	}
+	if rebuild == nil {
+		return fmt.Errorf("%w: %s timestamp %s, sign it again", ErrExpired, id, timestamp(tx))
+	}
+
+	err = refresh(ctx, nd, tx, rebuild)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrExpired, id, err)
+	}
+	newID, err := txID(nd.Scheme(), tx)
+	if err != nil {
+		return err
+	}
+	log.Info().Str("newTxId", newID.String()).Msg("expired transaction is rebuilt with fresh timestamp")
+	return nil
+}
+
+func refresh(ctx context.Context, nd node.Node, tx proto.Transaction, rebuild Rebuild) error {
+	// the new timestamp would change the asset id
+	if _, ok := tx.(*proto.IssueWithProofs); ok {
+		return errors.New("issue transaction can't be rebuilt")
+	}
+	err := txfields.SetTimestamp(nd.Scheme(), tx, uint64(time.Now().UnixMilli()))
+	if err != nil {
+		return fmt.Errorf("txfields.SetTimestamp: %w", err)
+	}
+	err = signer.ResetProofs(tx)
+	if err != nil {
+		return fmt.Errorf("signer.ResetProofs: %w", err)
+	}
+	err = rebuild(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("rebuild: %w", err)
+	}
+	if fn, ok := ctx.Value(rebuiltKey{}).(func(ctx context.Context, id crypto.Digest) error); ok {
+		id, e := txID(nd.Scheme(), tx)
+		if e != nil {
+			return e
+		}
+		e = fn(ctx, id)
+		if e != nil {
+			return fmt.Errorf("rebuilt: %w", e)
+		}
+	}
+	err = nd.Broadcast(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("nd.Broadcast: %w", err)
+	}
+	return nil
+}
+
+func onChain(ctx context.Context, nd node.Node, id crypto.Digest) bool {
+	_, err := nd.TransactionInfo(ctx, id)
+	return err == nil
+}
+
+func expired(tx proto.Transaction) bool {
+	return time.Since(timestamp(tx)) > maxAge
+}
+
+// isExpired reports whether the node rejected the transaction by its timestamp
+func isExpired(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "timestamp")
+}
+
+func timestamp(tx proto.Transaction) time.Time {
+	return time.UnixMilli(int64(tx.GetTimestamp()))
+}
+
+func txID(scheme proto.Scheme, tx proto.Transaction) (crypto.Digest, error) {
+	b, err := tx.GetID(scheme)
+	if err != nil {
+		return crypto.Digest{}, fmt.Errorf("tx.GetID: %w", err)
+	}
+	id, err := crypto.NewDigestFromBytes(b)
+	if err != nil {
+		return crypto.Digest{}, fmt.Errorf("crypto.NewDigestFromBytes: %w", err)
+	}
+	return id, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
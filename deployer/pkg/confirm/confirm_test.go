@@ -0,0 +1,203 @@
+package confirm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// pending keeps transactions in the pool, they are never mined
+type pending struct {
+	*node.Fake
+}
+
+func (p pending) InUnconfirmed(context.Context, crypto.Digest) (bool, error) {
+	return true, nil
+}
+
+// growing adds a block on every height request
+type growing struct {
+	*node.Fake
+	mu     sync.Mutex
+	blocks uint64
+}
+
+func (g *growing) Height(ctx context.Context) (uint64, error) {
+	h, err := g.Fake.Height(ctx)
+	if err != nil {
+		return 0, err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.blocks++
+	return h + g.blocks, nil
+}
+
+func newAccount(nd *node.Fake) node.FakeAccount {
+	acc := node.NewFakeAccount(nd.Scheme(), "confirm")
+	nd.SetBalance(acc.Address, 1_0000_0000)
+	return acc
+}
+
+func dataTx(t *testing.T, scheme proto.Scheme, acc node.FakeAccount, ts time.Time) *proto.DataWithProofs {
+	t.Helper()
+	tx := proto.NewUnsignedDataWithProofs(2, acc.PublicKey, 500000, uint64(ts.UnixMilli()))
+	err := tx.AppendEntry(&proto.IntegerDataEntry{Key: "%s__key", Value: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tx.Sign(scheme, acc.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func testOptions() Options {
+	opts := DefaultOptions()
+	opts.PollInterval = time.Millisecond
+	opts.Timeout = time.Second
+	return opts
+}
+
+func TestWaitMined(t *testing.T) {
+	ctx := context.Background()
+	nd := node.NewFake(proto.TestNetScheme)
+	acc := newAccount(nd)
+	tx := dataTx(t, nd.Scheme(), acc, time.Now())
+	err := nd.Broadcast(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Wait(ctx, nd, tx, testOptions(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(nd.Broadcasts()); n != 1 {
+		t.Errorf("mined transaction is broadcast %d times", n)
+	}
+}
+
+func TestWaitConfirmations(t *testing.T) {
+	ctx := context.Background()
+	fake := node.NewFake(proto.TestNetScheme)
+	acc := newAccount(fake)
+	tx := dataTx(t, fake.Scheme(), acc, time.Now())
+	err := fake.Broadcast(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nd := &growing{Fake: fake}
+	opts := testOptions()
+	opts.Confirmations = 4
+	err = Wait(ctx, nd, tx, opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the block with the transaction is the first confirmation
+	if nd.blocks != 3 {
+		t.Errorf("waited for %d blocks, want 3", nd.blocks)
+	}
+}
+
+func TestWaitDropped(t *testing.T) {
+	ctx := context.Background()
+	nd := node.NewFake(proto.TestNetScheme)
+	acc := newAccount(nd)
+	// neither on chain nor in the pool
+	tx := dataTx(t, nd.Scheme(), acc, time.Now())
+
+	err := Wait(ctx, nd, tx, testOptions(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(nd.Broadcasts()); n != 1 {
+		t.Errorf("dropped transaction is broadcast %d times, want 1", n)
+	}
+}
+
+func TestWaitRejected(t *testing.T) {
+	ctx := context.Background()
+	nd := node.NewFake(proto.TestNetScheme)
+	acc := newAccount(nd)
+	tx := dataTx(t, nd.Scheme(), acc, time.Now())
+	nd.BroadcastHook = func(proto.Transaction) error {
+		return errors.New("insufficient fee")
+	}
+
+	err := Wait(ctx, nd, tx, testOptions(), nil)
+	if !errors.Is(err, ErrRejected) {
+		t.Errorf("Wait() error = %v, want %v", err, ErrRejected)
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	ctx := context.Background()
+	fake := node.NewFake(proto.TestNetScheme)
+	acc := newAccount(fake)
+	tx := dataTx(t, fake.Scheme(), acc, time.Now())
+
+	opts := testOptions()
+	opts.Timeout = 20 * time.Millisecond
+	err := Wait(ctx, pending{Fake: fake}, tx, opts, nil)
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Wait() error = %v, want %v", err, ErrTimeout)
+	}
+	if n := len(fake.Broadcasts()); n != 0 {
+		t.Errorf("transaction in the pool is broadcast %d times", n)
+	}
+}
+
+func TestWaitExpired(t *testing.T) {
+	ctx := context.Background()
+	nd := node.NewFake(proto.TestNetScheme)
+	acc := newAccount(nd)
+	old := time.Now().Add(-3 * time.Hour)
+
+	tx := dataTx(t, nd.Scheme(), acc, old)
+	err := Wait(ctx, nd, tx, testOptions(), nil)
+	if !errors.Is(err, ErrExpired) {
+		t.Errorf("Wait() error = %v, want %v", err, ErrExpired)
+	}
+	if n := len(nd.Broadcasts()); n != 0 {
+		t.Fatalf("expired transaction is broadcast %d times", n)
+	}
+
+	var rebuilt crypto.Digest
+	ctx = OnRebuilt(ctx, func(_ context.Context, id crypto.Digest) error {
+		rebuilt = id
+		return nil
+	})
+	tx = dataTx(t, nd.Scheme(), acc, old)
+	err = Wait(ctx, nd, tx, testOptions(), func(_ context.Context, tx proto.Transaction) error {
+		return tx.Sign(nd.Scheme(), acc.SecretKey)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tx.Timestamp <= uint64(old.UnixMilli()) {
+		t.Error("rebuilt transaction has the old timestamp")
+	}
+	id, err := txID(nd.Scheme(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt != id {
+		t.Errorf("OnRebuilt() got %s, want %s", rebuilt, id)
+	}
+	if !onChain(ctx, nd, id) {
+		t.Error("rebuilt transaction isn't on chain")
+	}
+	if n := len(nd.Broadcasts()); n != 1 {
+		t.Errorf("rebuilt transaction is broadcast %d times, want 1", n)
+	}
+}
@@ -4,21 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"time"
-
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"sort"
 )
 
 type Contract struct {
-	File      string `bson:"file,omitempty"`
-	Stage     uint32 `bson:"stage,omitempty"`
-	Compact   bool   `bson:"compact,omitempty"`
-	Tag       string `bson:"tag,omitempty"`
-	BasePub   string `bson:"base_pub,omitempty"`
-	BasePrv   string `bson:"base_prv,omitempty"`
-	SignerPrv string `bson:"signer_prv,omitempty"`
+	File      string `bson:"file,omitempty" json:"file,omitempty"`
+	Stage     uint32 `bson:"stage,omitempty" json:"stage,omitempty"`
+	Compact   bool   `bson:"compact,omitempty" json:"compact,omitempty"`
+	Tag       string `bson:"tag,omitempty" json:"tag,omitempty"`
+	BasePub   string `bson:"base_pub,omitempty" json:"base_pub,omitempty"`
+	BasePrv   string `bson:"base_prv,omitempty" json:"base_prv,omitempty"`
+	SignerPrv string `bson:"signer_prv,omitempty" json:"signer_prv,omitempty"`
 }
 
 func (c Contract) validate() error {
@@ -35,62 +31,41 @@ func (c Contract) validate() error {
 	return nil
 }
 
-type Model struct {
-	coll *mongo.Collection
-}
-
-func NewModel(coll *mongo.Collection) Model {
-	return Model{
-		coll: coll,
-	}
+// Model is contracts registry
+type Model interface {
+	// GetAll returns all contracts sorted by stage and file
+	GetAll(ctx context.Context) ([]Contract, error)
+	IsCompact(ctx context.Context, fileName string) (bool, error)
+	// GetFactory returns factory_v2 of the stage, or of the lowest stage if stage is nil
+	GetFactory(ctx context.Context, stage *int) (Contract, error)
+	// GetByTag returns contract of the stage, or of the lowest stage if stage is nil
+	GetByTag(ctx context.Context, tag string, stage *int) (Contract, error)
+	GetStage(ctx context.Context, stage uint32) ([]Contract, error)
+	Create(
+		ctx context.Context,
+		file string,
+		stage uint32,
+		compact bool,
+		tag, basePub, basePrv, signerPrv string,
+	) error
+	DeleteStage(ctx context.Context, stage uint32) error
+	// UpdateKeys replaces private keys of the contract with file and base public key of c, it's used to re-encrypt them
+	UpdateKeys(ctx context.Context, c Contract) error
 }
 
-func (m Model) GetAll(c context.Context) ([]Contract, error) {
-	ctx, cancel := context.WithTimeout(c, 10*time.Second)
-	defer cancel()
-
-	cur, err := m.coll.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{
-		Key:   "stage",
-		Value: 1,
-	}, {
-		Key:   "file",
-		Value: 1,
-	}}))
-	if err != nil {
-		return nil, fmt.Errorf("m.coll.Find: %w", err)
-	}
-
-	var res []Contract
-	err = cur.All(ctx, &res)
-	if err != nil {
-		return nil, fmt.Errorf("cur.All: %w", err)
-	}
+var ErrNotFound = errors.New("contract not found")
 
-	for _, r := range res {
+func validateAll(docs []Contract) error {
+	for _, r := range docs {
 		e := r.validate()
 		if e != nil {
-			return nil, fmt.Errorf("r.validate: file: %s tag: %s: %w", r.File, r.Tag, e)
+			return fmt.Errorf("r.validate: file: %s tag: %s: %w", r.File, r.Tag, e)
 		}
 	}
-
-	return res, nil
+	return nil
 }
 
-func (m Model) IsCompact(c context.Context, fileName string) (bool, error) {
-	ctx, cancel := context.WithTimeout(c, 10*time.Second)
-	defer cancel()
-
-	cur, err := m.coll.Find(ctx, bson.M{"file": fileName})
-	if err != nil {
-		return false, fmt.Errorf("m.coll.FindOne: %w", err)
-	}
-
-	var docs []Contract
-	err = cur.All(ctx, &docs)
-	if err != nil {
-		return false, fmt.Errorf("cur.All: %w", err)
-	}
-
+func compactOf(fileName string, docs []Contract) (bool, error) {
 	if len(docs) == 0 {
 		return false, errors.New("no contract found")
 	}
@@ -109,48 +84,11 @@ func (m Model) IsCompact(c context.Context, fileName string) (bool, error) {
 	return compact, nil
 }
 
-func (m Model) GetFactory(c context.Context, stage *int) (Contract, error) {
-	ctx, cancel := context.WithTimeout(c, 10*time.Second)
-	sortOptions := options.FindOne().SetSort(bson.M{"stage": 1})
-	defer cancel()
-
-	q := bson.M{
-		"tag": "factory_v2",
-	}
-	if stage != nil {
-		q["stage"] = *stage
-	}
-
-	var doc Contract
-	err := m.coll.FindOne(ctx, q, sortOptions).Decode(&doc)
-	if err != nil {
-		return Contract{}, fmt.Errorf("m.coll.FindOne: %w", err)
-	}
-
-	return doc, nil
-}
-
-func (m Model) Create(
-	c context.Context,
-	file string,
-	stage uint32,
-	compact bool,
-	tag, basePub, basePrv, signerPrv string,
-) error {
-	ctx, cancel := context.WithTimeout(c, 10*time.Second)
-	defer cancel()
-
-	_, err := m.coll.InsertOne(ctx, Contract{
-		File:      file,
-		Stage:     stage,
-		Compact:   compact,
-		Tag:       tag,
-		BasePub:   basePub,
-		BasePrv:   basePrv,
-		SignerPrv: signerPrv,
+func sortByStageAndFile(docs []Contract) {
+	sort.SliceStable(docs, func(i, j int) bool {
+		if docs[i].Stage != docs[j].Stage {
+			return docs[i].Stage < docs[j].Stage
+		}
+		return docs[i].File < docs[j].File
 	})
-	if err != nil {
-		return fmt.Errorf("m.coll.InsertOne: %w", err)
-	}
-	return nil
 }
@@ -0,0 +1,116 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/envelope"
+)
+
+// encryptedModel keeps private keys encrypted in the underlying model and returns them decrypted,
+// plain text keys written before encryption was enabled are returned as is
+type encryptedModel struct {
+	Model
+	cipher *envelope.Cipher
+}
+
+// NewEncryptedModel wraps the model, nil cipher keeps new keys in plain text
+// and fails on reading encrypted ones, storage.Open warns about it
+func NewEncryptedModel(m Model, c *envelope.Cipher) Model {
+	return encryptedModel{
+		Model:  m,
+		cipher: c,
+	}
+}
+
+func (m encryptedModel) decrypt(c Contract) (Contract, error) {
+	var err error
+	c.BasePrv, err = m.cipher.Decrypt(c.BasePrv, additional(c.Tag, c.Stage, "base_prv"))
+	if err != nil {
+		return Contract{}, fmt.Errorf("%s base key: %w", c.File, err)
+	}
+	c.SignerPrv, err = m.cipher.Decrypt(c.SignerPrv, additional(c.Tag, c.Stage, "signer_prv"))
+	if err != nil {
+		return Contract{}, fmt.Errorf("%s signer key: %w", c.File, err)
+	}
+	return c, nil
+}
+
+func (m encryptedModel) decryptAll(docs []Contract) ([]Contract, error) {
+	for i, doc := range docs {
+		c, err := m.decrypt(doc)
+		if err != nil {
+			return nil, err
+		}
+		docs[i] = c
+	}
+	return docs, nil
+}
+
+func (m encryptedModel) GetAll(ctx context.Context) ([]Contract, error) {
+	docs, err := m.Model.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.decryptAll(docs)
+}
+
+func (m encryptedModel) GetFactory(ctx context.Context, stage *int) (Contract, error) {
+	return m.GetByTag(ctx, "factory_v2", stage)
+}
+
+func (m encryptedModel) GetByTag(ctx context.Context, tag string, stage *int) (Contract, error) {
+	c, err := m.Model.GetByTag(ctx, tag, stage)
+	if err != nil {
+		return Contract{}, err
+	}
+	return m.decrypt(c)
+}
+
+func (m encryptedModel) GetStage(ctx context.Context, stage uint32) ([]Contract, error) {
+	docs, err := m.Model.GetStage(ctx, stage)
+	if err != nil {
+		return nil, err
+	}
+	return m.decryptAll(docs)
+}
+
+func (m encryptedModel) Create(
+	ctx context.Context,
+	file string,
+	stage uint32,
+	compact bool,
+	tag, basePub, basePrv, signerPrv string,
+) error {
+	encBasePrv, encSignerPrv, err := m.encrypt(tag, stage, basePrv, signerPrv)
+	if err != nil {
+		return err
+	}
+	return m.Model.Create(ctx, file, stage, compact, tag, basePub, encBasePrv, encSignerPrv)
+}
+
+func (m encryptedModel) UpdateKeys(ctx context.Context, c Contract) error {
+	var err error
+	c.BasePrv, c.SignerPrv, err = m.encrypt(c.Tag, c.Stage, c.BasePrv, c.SignerPrv)
+	if err != nil {
+		return err
+	}
+	return m.Model.UpdateKeys(ctx, c)
+}
+
+func (m encryptedModel) encrypt(tag string, stage uint32, basePrv, signerPrv string) (string, string, error) {
+	encBasePrv, err := m.cipher.Encrypt(basePrv, additional(tag, stage, "base_prv"))
+	if err != nil {
+		return "", "", fmt.Errorf("m.cipher.Encrypt: %w", err)
+	}
+	encSignerPrv, err := m.cipher.Encrypt(signerPrv, additional(tag, stage, "signer_prv"))
+	if err != nil {
+		return "", "", fmt.Errorf("m.cipher.Encrypt: %w", err)
+	}
+	return encBasePrv, encSignerPrv, nil
+}
+
+// additional binds encrypted key to the contract and the field, so it isn't decrypted if it's copied to another one
+func additional(tag string, stage uint32, field string) string {
+	return fmt.Sprintf("%s:%d:%s", tag, stage, field)
+}
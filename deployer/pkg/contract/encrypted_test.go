@@ -0,0 +1,111 @@
+package contract
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/envelope"
+)
+
+func newKeyFileCipher(t *testing.T) *envelope.Cipher {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "master.key")
+	err := os.WriteFile(path, []byte(strings.Repeat("ab", 32)+"\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := envelope.Open(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestEncryptedModel(t *testing.T) {
+	ctx := context.Background()
+	plain := NewFileModel(filepath.Join(t.TempDir(), "contracts.json"))
+	m := NewEncryptedModel(plain, newKeyFileCipher(t))
+
+	err := m.Create(ctx, "pool.ride", 1, false, "pool", "pub1", "prv", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = plain.Create(ctx, "router.ride", 1, false, "router", "pub2", "old prv", "old signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := plain.GetByTag(ctx, "pool", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !envelope.IsEncrypted(stored.BasePrv) || !envelope.IsEncrypted(stored.SignerPrv) {
+		t.Errorf("keys are stored in plain text: %+v", stored)
+	}
+
+	pool, err := m.GetByTag(ctx, "pool", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool.BasePrv != "prv" || pool.SignerPrv != "signer" {
+		t.Errorf("decrypted keys = %s, %s, want prv, signer", pool.BasePrv, pool.SignerPrv)
+	}
+	router, err := m.GetByTag(ctx, "router", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if router.BasePrv != "old prv" || router.SignerPrv != "old signer" {
+		t.Errorf("plain text keys = %s, %s, want them as is", router.BasePrv, router.SignerPrv)
+	}
+
+	router.BasePrv, router.SignerPrv = "new prv", "new signer"
+	err = m.UpdateKeys(ctx, router)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := m.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[1].BasePrv != "new prv" || all[1].SignerPrv != "new signer" {
+		t.Errorf("GetAll() = %+v, want updated router keys", all)
+	}
+
+	// key copied to another contract isn't decrypted
+	err = plain.Create(ctx, "pool.ride", 2, false, "pool", "pub3", stored.BasePrv, stored.SignerPrv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.GetStage(ctx, 2); err == nil {
+		t.Error("key copied to another stage is decrypted")
+	}
+}
+
+func TestEncryptedModelNilCipher(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	encrypted := NewEncryptedModel(NewFileModel(filepath.Join(dir, "contracts.json")), newKeyFileCipher(t))
+	err := encrypted.Create(ctx, "pool.ride", 1, false, "pool", "pub1", "prv", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewEncryptedModel(NewFileModel(filepath.Join(dir, "contracts.json")), nil)
+	if _, err = m.GetByTag(ctx, "pool", nil); err == nil {
+		t.Error("encrypted key is read without cipher")
+	}
+	err = m.Create(ctx, "router.ride", 1, false, "router", "pub2", "prv", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	router, err := m.GetByTag(ctx, "router", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if router.BasePrv != "prv" || router.SignerPrv != "signer" {
+		t.Errorf("keys without cipher = %s, %s, want plain text", router.BasePrv, router.SignerPrv)
+	}
+}
@@ -0,0 +1,178 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/jsonfile"
+)
+
+// fileModel keeps contracts in local JSON file, it's useful for local stages and tests
+type fileModel struct {
+	name string
+	mu   *sync.Mutex
+}
+
+func NewFileModel(name string) Model {
+	return fileModel{
+		name: name,
+		mu:   &sync.Mutex{},
+	}
+}
+
+func (m fileModel) load() ([]Contract, error) {
+	var docs []Contract
+	err := jsonfile.Load(m.name, &docs)
+	if err != nil {
+		return nil, fmt.Errorf("jsonfile.Load: %w", err)
+	}
+	return docs, nil
+}
+
+func (m fileModel) filter(fn func(Contract) bool) ([]Contract, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Contract
+	for _, doc := range docs {
+		if fn(doc) {
+			res = append(res, doc)
+		}
+	}
+	sortByStageAndFile(res)
+	return res, nil
+}
+
+func (m fileModel) GetAll(_ context.Context) ([]Contract, error) {
+	res, err := m.filter(func(Contract) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateAll(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (m fileModel) IsCompact(_ context.Context, fileName string) (bool, error) {
+	docs, err := m.filter(func(c Contract) bool { return c.File == fileName })
+	if err != nil {
+		return false, err
+	}
+
+	return compactOf(fileName, docs)
+}
+
+func (m fileModel) GetFactory(ctx context.Context, stage *int) (Contract, error) {
+	return m.GetByTag(ctx, "factory_v2", stage)
+}
+
+func (m fileModel) GetByTag(_ context.Context, tag string, stage *int) (Contract, error) {
+	docs, err := m.filter(func(c Contract) bool {
+		return c.Tag == tag && (stage == nil || int(c.Stage) == *stage)
+	})
+	if err != nil {
+		return Contract{}, err
+	}
+
+	if len(docs) == 0 {
+		return Contract{}, ErrNotFound
+	}
+	return docs[0], nil
+}
+
+func (m fileModel) GetStage(_ context.Context, stage uint32) ([]Contract, error) {
+	return m.filter(func(c Contract) bool { return c.Stage == stage })
+}
+
+func (m fileModel) Create(
+	_ context.Context,
+	file string,
+	stage uint32,
+	compact bool,
+	tag, basePub, basePrv, signerPrv string,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	docs = append(docs, Contract{
+		File:      file,
+		Stage:     stage,
+		Compact:   compact,
+		Tag:       tag,
+		BasePub:   basePub,
+		BasePrv:   basePrv,
+		SignerPrv: signerPrv,
+	})
+
+	err = jsonfile.Save(m.name, docs)
+	if err != nil {
+		return fmt.Errorf("jsonfile.Save: %w", err)
+	}
+	return nil
+}
+
+func (m fileModel) DeleteStage(_ context.Context, stage uint32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	var res []Contract
+	for _, doc := range docs {
+		if doc.Stage != stage {
+			res = append(res, doc)
+		}
+	}
+
+	err = jsonfile.Save(m.name, res)
+	if err != nil {
+		return fmt.Errorf("jsonfile.Save: %w", err)
+	}
+	return nil
+}
+
+func (m fileModel) UpdateKeys(_ context.Context, c Contract) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i := range docs {
+		if docs[i].File == c.File && docs[i].BasePub == c.BasePub {
+			docs[i].BasePrv = c.BasePrv
+			docs[i].SignerPrv = c.SignerPrv
+			found = true
+		}
+	}
+	if !found {
+		return ErrNotFound
+	}
+
+	err = jsonfile.Save(m.name, docs)
+	if err != nil {
+		return fmt.Errorf("jsonfile.Save: %w", err)
+	}
+	return nil
+}
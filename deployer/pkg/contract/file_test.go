@@ -0,0 +1,128 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileModel(t *testing.T) {
+	ctx := context.Background()
+	m := NewFileModel(filepath.Join(t.TempDir(), "testnet", "contracts.json"))
+
+	all, err := m.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 0 {
+		t.Fatalf("missing file has %d contracts", len(all))
+	}
+
+	for _, c := range []Contract{
+		{File: "pool.ride", Stage: 2, Tag: "pool", BasePub: "pub4"},
+		{File: "pool.ride", Stage: 1, Tag: "pool", BasePub: "pub1"},
+		{File: "factory_v2.ride", Stage: 1, Compact: true, Tag: "factory_v2", BasePub: "pub2"},
+		{File: "factory_v2.ride", Stage: 2, Compact: true, Tag: "factory_v2", BasePub: "pub3"},
+	} {
+		err = m.Create(ctx, c.File, c.Stage, c.Compact, c.Tag, c.BasePub, "prv", "signer")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err = m.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"pub2", "pub1", "pub3", "pub4"}
+	if len(all) != len(want) {
+		t.Fatalf("got %d contracts, want %d", len(all), len(want))
+	}
+	for i, c := range all {
+		if c.BasePub != want[i] {
+			t.Errorf("contracts[%d] = %s, want %s sorted by stage and file", i, c.BasePub, want[i])
+		}
+	}
+
+	factory, err := m.GetFactory(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if factory.BasePub != "pub2" {
+		t.Errorf("factory of the lowest stage = %s, want pub2", factory.BasePub)
+	}
+	stage := 2
+	factory, err = m.GetFactory(ctx, &stage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if factory.BasePub != "pub3" {
+		t.Errorf("factory of stage 2 = %s, want pub3", factory.BasePub)
+	}
+	if _, err = m.GetByTag(ctx, "router", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetByTag() error = %v, want %v", err, ErrNotFound)
+	}
+
+	compact, err := m.IsCompact(ctx, "factory_v2.ride")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !compact {
+		t.Error("factory_v2.ride isn't compact")
+	}
+	err = m.Create(ctx, "factory_v2.ride", 3, false, "factory_v2", "pub5", "prv", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.IsCompact(ctx, "factory_v2.ride"); err == nil {
+		t.Error("different compact flags of the file aren't reported")
+	}
+
+	err = m.UpdateKeys(ctx, Contract{File: "pool.ride", BasePub: "pub1", BasePrv: "new", SignerPrv: "new signer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := m.GetByTag(ctx, "pool", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool.BasePrv != "new" || pool.SignerPrv != "new signer" {
+		t.Errorf("keys aren't updated: %+v", pool)
+	}
+	err = m.UpdateKeys(ctx, Contract{File: "pool.ride", BasePub: "unknown"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("UpdateKeys() error = %v, want %v", err, ErrNotFound)
+	}
+
+	err = m.DeleteStage(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rest, err := m.GetStage(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 0 {
+		t.Errorf("stage 2 has %d contracts after delete", len(rest))
+	}
+	rest, err = m.GetStage(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 2 {
+		t.Errorf("stage 1 has %d contracts, want 2", len(rest))
+	}
+}
+
+func TestFileModelValidate(t *testing.T) {
+	ctx := context.Background()
+	m := NewFileModel(filepath.Join(t.TempDir(), "contracts.json"))
+	err := m.Create(ctx, "pool.ride", 1, false, "pool", "", "prv", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.GetAll(ctx); err == nil {
+		t.Error("contract without public key is read")
+	}
+}
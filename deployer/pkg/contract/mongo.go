@@ -0,0 +1,171 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoModel struct {
+	coll *mongo.Collection
+}
+
+func NewMongoModel(coll *mongo.Collection) Model {
+	return mongoModel{
+		coll: coll,
+	}
+}
+
+func (m mongoModel) GetAll(c context.Context) ([]Contract, error) {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	cur, err := m.coll.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{
+		Key:   "stage",
+		Value: 1,
+	}, {
+		Key:   "file",
+		Value: 1,
+	}}))
+	if err != nil {
+		return nil, fmt.Errorf("m.coll.Find: %w", err)
+	}
+
+	var res []Contract
+	err = cur.All(ctx, &res)
+	if err != nil {
+		return nil, fmt.Errorf("cur.All: %w", err)
+	}
+
+	err = validateAll(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (m mongoModel) IsCompact(c context.Context, fileName string) (bool, error) {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	cur, err := m.coll.Find(ctx, bson.M{"file": fileName})
+	if err != nil {
+		return false, fmt.Errorf("m.coll.FindOne: %w", err)
+	}
+
+	var docs []Contract
+	err = cur.All(ctx, &docs)
+	if err != nil {
+		return false, fmt.Errorf("cur.All: %w", err)
+	}
+
+	return compactOf(fileName, docs)
+}
+
+func (m mongoModel) GetFactory(c context.Context, stage *int) (Contract, error) {
+	return m.GetByTag(c, "factory_v2", stage)
+}
+
+func (m mongoModel) GetByTag(c context.Context, tag string, stage *int) (Contract, error) {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	sortOptions := options.FindOne().SetSort(bson.M{"stage": 1})
+	defer cancel()
+
+	q := bson.M{
+		"tag": tag,
+	}
+	if stage != nil {
+		q["stage"] = *stage
+	}
+
+	var doc Contract
+	err := m.coll.FindOne(ctx, q, sortOptions).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return Contract{}, ErrNotFound
+		}
+		return Contract{}, fmt.Errorf("m.coll.FindOne: %w", err)
+	}
+
+	return doc, nil
+}
+
+func (m mongoModel) GetStage(c context.Context, stage uint32) ([]Contract, error) {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	cur, err := m.coll.Find(ctx, bson.M{"stage": stage}, options.Find().SetSort(bson.M{"file": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("m.coll.Find: %w", err)
+	}
+
+	var res []Contract
+	err = cur.All(ctx, &res)
+	if err != nil {
+		return nil, fmt.Errorf("cur.All: %w", err)
+	}
+
+	return res, nil
+}
+
+func (m mongoModel) Create(
+	c context.Context,
+	file string,
+	stage uint32,
+	compact bool,
+	tag, basePub, basePrv, signerPrv string,
+) error {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	_, err := m.coll.InsertOne(ctx, Contract{
+		File:      file,
+		Stage:     stage,
+		Compact:   compact,
+		Tag:       tag,
+		BasePub:   basePub,
+		BasePrv:   basePrv,
+		SignerPrv: signerPrv,
+	})
+	if err != nil {
+		return fmt.Errorf("m.coll.InsertOne: %w", err)
+	}
+	return nil
+}
+
+func (m mongoModel) DeleteStage(c context.Context, stage uint32) error {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	_, err := m.coll.DeleteMany(ctx, bson.M{"stage": stage})
+	if err != nil {
+		return fmt.Errorf("m.coll.DeleteMany: %w", err)
+	}
+	return nil
+}
+
+func (m mongoModel) UpdateKeys(c context.Context, doc Contract) error {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	res, err := m.coll.UpdateOne(ctx, bson.M{
+		"file":     doc.File,
+		"base_pub": doc.BasePub,
+	}, bson.M{"$set": bson.M{
+		"base_prv":   doc.BasePrv,
+		"signer_prv": doc.SignerPrv,
+	}})
+	if err != nil {
+		return fmt.Errorf("m.coll.UpdateOne: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
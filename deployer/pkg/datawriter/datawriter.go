@@ -0,0 +1,115 @@
+package datawriter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/fee"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/tools"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const (
+	version = 2
+	// maxEntries is the protocol limit of entries in one transaction
+	maxEntries = 100
+	// proofsBytes is the size of the biggest proofs: version, count and 8 proofs of 64 bytes with length prefix
+	proofsBytes = 1 + 2 + 8*(2+crypto.SignatureSize)
+	// maxBytes is the body size limit of data transaction, the protocol limit is of the signed one,
+	// fee is a fixed size field of the body, so it doesn't change the size
+	maxBytes = proto.MaxDataWithProofsBytes - proofsBytes
+)
+
+// Send signs and broadcasts transaction, it returns when the transaction is on chain
+type Send func(ctx context.Context, tx *proto.DataWithProofs) error
+
+// Pack splits entries into unsigned data transactions of the sender keeping their order,
+// every transaction is within entry count and size limits, fees are estimated by the node
+func Pack(
+	ctx context.Context,
+	nd node.Node,
+	sender crypto.PublicKey,
+	entries proto.DataEntries,
+) ([]*proto.DataWithProofs, error) {
+	var (
+		res []*proto.DataWithProofs
+		cur *proto.DataWithProofs
+	)
+	for _, e := range entries {
+		if cur != nil && len(cur.Entries) < maxEntries {
+			ok, err := fits(nd.Scheme(), cur, e)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				cur.Entries = append(cur.Entries, e)
+				continue
+			}
+		}
+
+		cur = proto.NewUnsignedDataWithProofs(version, sender, 0, tools.Timestamp()+uint64(len(res)))
+		err := cur.AppendEntry(e)
+		if err != nil {
+			return nil, fmt.Errorf("cur.AppendEntry: %w", err)
+		}
+		ok, err := fits(nd.Scheme(), cur, nil)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("entry %s is bigger than %d bytes", e.GetKey(), maxBytes)
+		}
+		res = append(res, cur)
+	}
+
+	for _, tx := range res {
+		err := fee.Set(ctx, nd, tx)
+		if err != nil {
+			return nil, fmt.Errorf("fee.Set: %w", err)
+		}
+	}
+	return res, nil
+}
+
+// fits checks that body of tx with the entry appended is within size limit, keys must be unique
+func fits(scheme proto.Scheme, tx *proto.DataWithProofs, e proto.DataEntry) (bool, error) {
+	candidate := *tx
+	candidate.Entries = append(proto.DataEntries{}, tx.Entries...)
+	if e != nil {
+		err := candidate.AppendEntry(e)
+		if err != nil {
+			return false, fmt.Errorf("candidate.AppendEntry: %w", err)
+		}
+	}
+
+	body, err := proto.MarshalTxBody(scheme, &candidate)
+	if err != nil {
+		return false, fmt.Errorf("proto.MarshalTxBody: %w", err)
+	}
+	return len(body) <= maxBytes, nil
+}
+
+// Write packs entries and sends transactions one by one, the next one is sent when the previous is on chain,
+// so entries are applied in order and a failed transaction stops the rest
+func Write(
+	ctx context.Context,
+	nd node.Node,
+	sender crypto.PublicKey,
+	entries proto.DataEntries,
+	send Send,
+) error {
+	txs, err := Pack(ctx, nd, sender, entries)
+	if err != nil {
+		return fmt.Errorf("Pack: %w", err)
+	}
+
+	for i, tx := range txs {
+		err = send(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("data tx %d of %d: %w", i+1, len(txs), err)
+		}
+	}
+	return nil
+}
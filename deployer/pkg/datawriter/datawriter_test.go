@@ -0,0 +1,82 @@
+package datawriter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+func TestPack(t *testing.T) {
+	ctx := context.Background()
+	nd := node.NewFake(proto.TestNetScheme)
+	pk := node.NewFakeAccount(nd.Scheme(), "datawriter").PublicKey
+
+	small := make(proto.DataEntries, 0, 250)
+	for i := 0; i < cap(small); i++ {
+		small = append(small, &proto.IntegerDataEntry{Key: fmt.Sprintf("%%s__key%d", i), Value: int64(i)})
+	}
+	big := make(proto.DataEntries, 0, 12)
+	for i := 0; i < cap(big); i++ {
+		big = append(big, &proto.BinaryDataEntry{
+			Key:   fmt.Sprintf("%%s__blob%d", i),
+			Value: bytes.Repeat([]byte{byte(i)}, 32000),
+		})
+	}
+
+	tests := []struct {
+		name    string
+		entries proto.DataEntries
+		sizes   []int
+	}{
+		{"entry count", small, []int{100, 100, 50}},
+		{"body size", big, []int{4, 4, 4}},
+		{"empty", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txs, e := Pack(ctx, nd, pk, tt.entries)
+			if e != nil {
+				t.Fatal(e)
+			}
+			if len(txs) != len(tt.sizes) {
+				t.Fatalf("got %d transactions, want %d", len(txs), len(tt.sizes))
+			}
+
+			var (
+				packed     proto.DataEntries
+				timestamps = map[uint64]bool{}
+			)
+			for i, tx := range txs {
+				if len(tx.Entries) != tt.sizes[i] {
+					t.Errorf("txs[%d] has %d entries, want %d", i, len(tx.Entries), tt.sizes[i])
+				}
+				if tx.Fee == 0 {
+					t.Errorf("txs[%d] fee isn't set", i)
+				}
+				if timestamps[tx.Timestamp] {
+					t.Errorf("txs[%d] has the same timestamp as the previous one, the same id is possible", i)
+				}
+				timestamps[tx.Timestamp] = true
+
+				body, e := proto.MarshalTxBody(nd.Scheme(), tx)
+				if e != nil {
+					t.Fatal(e)
+				}
+				if len(body) > maxBytes {
+					t.Errorf("txs[%d] body is %d bytes, limit is %d", i, len(body), maxBytes)
+				}
+				packed = append(packed, tx.Entries...)
+			}
+
+			for i, e := range packed {
+				if e.GetKey() != tt.entries[i].GetKey() {
+					t.Fatalf("entry %d is %s, want %s", i, e.GetKey(), tt.entries[i].GetKey())
+				}
+			}
+		})
+	}
+}
@@ -1,12 +1,9 @@
 package docs
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
 	"os"
 	"path"
 	"sort"
@@ -17,6 +14,7 @@ import (
 	"github.com/waves-exchange/contracts/deployer/pkg/branch"
 	"github.com/waves-exchange/contracts/deployer/pkg/config"
 	"github.com/waves-exchange/contracts/deployer/pkg/contract"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
 	"github.com/wavesplatform/gowaves/pkg/client"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
@@ -27,7 +25,7 @@ import (
 type cfg struct {
 	branchModel    branch.Model
 	contractsModel contract.Model
-	client         *client.Client // TODO: make gowaves client with ratelimit as separate package
+	node           node.Node
 }
 
 type Docs struct {
@@ -40,27 +38,16 @@ func NewDocs(
 	logger zerolog.Logger,
 	testnetBranch branch.Model,
 	testnetContracts contract.Model,
-	testnetNode string,
+	testnetNode node.Node,
 	mainnetBranch branch.Model,
 	mainnetContracts contract.Model,
-	mainnetNode string,
+	mainnetNode node.Node,
 ) (*Docs, error) {
-	testnetClient, err := client.NewClient(client.Options{
-		BaseUrl: testnetNode,
-		Client:  &http.Client{Timeout: time.Minute},
-		ChainID: proto.TestNetScheme,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("client.NewClient: %w", err)
+	if testnetNode.Scheme() != proto.TestNetScheme {
+		return nil, errors.New("testnet node has wrong scheme")
 	}
-
-	mainnetClient, err := client.NewClient(client.Options{
-		BaseUrl: mainnetNode,
-		Client:  &http.Client{Timeout: time.Minute},
-		ChainID: proto.MainNetScheme,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("client.NewClient: %w", err)
+	if mainnetNode.Scheme() != proto.MainNetScheme {
+		return nil, errors.New("mainnet node has wrong scheme")
 	}
 
 	return &Docs{
@@ -68,12 +55,12 @@ func NewDocs(
 		testnet: cfg{
 			branchModel:    testnetBranch,
 			contractsModel: testnetContracts,
-			client:         testnetClient,
+			node:           testnetNode,
 		},
 		mainnet: cfg{
 			branchModel:    mainnetBranch,
 			contractsModel: mainnetContracts,
-			client:         mainnetClient,
+			node:           mainnetNode,
 		},
 	}, nil
 }
@@ -211,9 +198,9 @@ func (d *Docs) update(
 				return errors.New("no assetId=" + lp)
 			}
 
-			lpDetails, _, e := d.getCfg(network).client.Assets.Details(ctx, *lpAsset.ToDigest())
+			lpDetails, e := d.getCfg(network).node.AssetDetails(ctx, *lpAsset.ToDigest())
 			if e != nil {
-				return fmt.Errorf("d.getCfg(network).client.Assets.Details: %w", e)
+				return fmt.Errorf("d.getCfg(network).node.AssetDetails: %w", e)
 			}
 
 			poolLpAssets = append(poolLpAssets, lpDetails)
@@ -234,9 +221,9 @@ func (d *Docs) update(
 			return errors.New("no assetId=" + a)
 		}
 
-		assetsDetails, _, e := d.getCfg(network).client.Assets.Details(ctx, *asset.ToDigest())
+		assetsDetails, e := d.getCfg(network).node.AssetDetails(ctx, *asset.ToDigest())
 		if e != nil {
-			return fmt.Errorf("d.client().Assets.Details: %w", e)
+			return fmt.Errorf("d.getCfg(network).node.AssetDetails: %w", e)
 		}
 
 		assets = append(assets, assetsDetails)
@@ -291,25 +278,6 @@ func (d *Docs) update(
 }
 
 func (d *Docs) getPoolConfig(ctx context.Context, network config.Network, factory, poolAddress proto.WavesAddress) (string, string, string, error) {
-	type eval struct {
-		Expr string `json:"expr"`
-	}
-
-	b, err := json.Marshal(eval{Expr: fmt.Sprintf(`getPoolConfigREADONLY("%s")`, poolAddress)})
-	if err != nil {
-		return "", "", "", fmt.Errorf("json.Marshal: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		fmt.Sprintf("%s/utils/script/evaluate/%s", d.getCfg(network).client.GetOptions().BaseUrl, factory),
-		bytes.NewReader(b),
-	)
-	if err != nil {
-		return "", "", "", fmt.Errorf("http.NewRequestWithContext: %w", err)
-	}
-
 	type evalRes struct {
 		Result struct {
 			Type  string `json:"type"`
@@ -343,9 +311,9 @@ func (d *Docs) getPoolConfig(ctx context.Context, network config.Network, factor
 		Address string `json:"address"`
 	}
 	res := evalRes{}
-	_, err = d.getCfg(network).client.Do(ctx, req, &res)
+	err := d.getCfg(network).node.Evaluate(ctx, factory, fmt.Sprintf(`getPoolConfigREADONLY("%s")`, poolAddress), &res)
 	if err != nil {
-		return "", "", "", fmt.Errorf("s.client().Do: %w", err)
+		return "", "", "", fmt.Errorf("d.getCfg(network).node.Evaluate: %w", err)
 	}
 
 	if len(res.Result.Value.Num2.Value) == 0 {
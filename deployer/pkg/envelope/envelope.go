@@ -0,0 +1,293 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// prefix marks encrypted values, values without it are plain text written before encryption was enabled
+const prefix = "enc:v1:"
+
+const keySize = 32
+
+var ErrNoMasterKey = errors.New("value is encrypted, master key required")
+
+// Cipher encrypts every value with a random data key and the data key with the master key,
+// the result is enc:v1:<master key id>:<salt>:<encrypted data key>:<encrypted value>,
+// salt is empty if the master key isn't derived from passphrase.
+// Both are sealed with additional data of the document, so the value can't be moved to another one.
+// Nil Cipher keeps values in plain text
+type Cipher struct {
+	primary key
+	// providers are the primary one and old ones, they derive keys of values with other salts
+	providers []Provider
+	mu        *sync.Mutex
+	keys      map[string]key
+}
+
+type key struct {
+	id   string
+	salt []byte
+	aead cipher.AEAD
+}
+
+// New returns cipher with the master key of provider, derived keys get a random salt
+func New(p Provider) (*Cipher, error) {
+	var salt []byte
+	if size := p.SaltSize(); size > 0 {
+		salt = make([]byte, size)
+		_, err := rand.Read(salt)
+		if err != nil {
+			return nil, fmt.Errorf("rand.Read: %w", err)
+		}
+	}
+
+	k, err := newKey(p, salt)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{
+		primary:   k,
+		providers: []Provider{p},
+		mu:        &sync.Mutex{},
+		keys:      map[string]key{k.id: k},
+	}, nil
+}
+
+func newKey(p Provider, salt []byte) (key, error) {
+	masterKey, err := p.MasterKey(salt)
+	if err != nil {
+		return key{}, fmt.Errorf("p.MasterKey: %w", err)
+	}
+	if len(masterKey) != keySize {
+		return key{}, fmt.Errorf("master key must be %d bytes, got %d", keySize, len(masterKey))
+	}
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return key{}, err
+	}
+
+	sum := sha256.Sum256(masterKey)
+	return key{
+		id:   hex.EncodeToString(sum[:8]),
+		salt: salt,
+		aead: aead,
+	}, nil
+}
+
+// WithOld returns keyring encrypting with the master key of c and decrypting values of both,
+// so values are readable while they are re-encrypted
+func (c *Cipher) WithOld(old *Cipher) *Cipher {
+	if c == nil || old == nil {
+		return c
+	}
+
+	res := &Cipher{
+		primary:   c.primary,
+		providers: append(append([]Provider{}, c.providers...), old.providers...),
+		mu:        &sync.Mutex{},
+		keys:      map[string]key{},
+	}
+	for _, keys := range []map[string]key{old.snapshot(), c.snapshot()} {
+		for id, k := range keys {
+			res.keys[id] = k
+		}
+	}
+	return res
+}
+
+func (c *Cipher) snapshot() map[string]key {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := make(map[string]key, len(c.keys))
+	for id, k := range c.keys {
+		res[id] = k
+	}
+	return res
+}
+
+// ID identifies the master key without revealing it
+func (c *Cipher) ID() string {
+	if c == nil {
+		return ""
+	}
+	return c.primary.id
+}
+
+// Encrypt returns empty and already encrypted values as is,
+// additional data identifies the document, the same one is required to decrypt
+func (c *Cipher) Encrypt(plain, additional string) (string, error) {
+	if c == nil || plain == "" || IsEncrypted(plain) {
+		return plain, nil
+	}
+
+	dataKey := make([]byte, keySize)
+	_, err := rand.Read(dataKey)
+	if err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	wrappedKey, err := seal(c.primary.aead, dataKey, additional)
+	if err != nil {
+		return "", err
+	}
+	value, err := seal(data, []byte(plain), additional)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + c.primary.id + ":" +
+		base64.StdEncoding.EncodeToString(c.primary.salt) + ":" +
+		base64.StdEncoding.EncodeToString(wrappedKey) + ":" +
+		base64.StdEncoding.EncodeToString(value), nil
+}
+
+// Decrypt returns plain text values as is
+func (c *Cipher) Decrypt(s, additional string) (string, error) {
+	if !IsEncrypted(s) {
+		return s, nil
+	}
+	if c == nil {
+		return "", ErrNoMasterKey
+	}
+
+	v, err := parse(s)
+	if err != nil {
+		return "", err
+	}
+	k, ok, err := c.key(v.id, v.salt)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("value is encrypted with unknown master key %s", v.id)
+	}
+
+	dataKey, err := open(k.aead, v.wrappedKey, additional)
+	if err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plain, err := open(data, v.value, additional)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// Owns reports whether the value is encrypted with a master key of c
+func (c *Cipher) Owns(s string) (bool, error) {
+	if c == nil || !IsEncrypted(s) {
+		return false, nil
+	}
+	v, err := parse(s)
+	if err != nil {
+		return false, err
+	}
+	_, ok, err := c.key(v.id, v.salt)
+	return ok, err
+}
+
+// key returns known key by id or derives it with the salt of the value
+func (c *Cipher) key(id string, salt []byte) (key, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if k, ok := c.keys[id]; ok {
+		return k, true, nil
+	}
+	if len(salt) == 0 {
+		return key{}, false, nil
+	}
+	for _, p := range c.providers {
+		if p.SaltSize() == 0 {
+			continue
+		}
+		k, err := newKey(p, salt)
+		if err != nil {
+			return key{}, false, err
+		}
+		if k.id == id {
+			c.keys[id] = k
+			return k, true, nil
+		}
+	}
+	return key{}, false, nil
+}
+
+type value struct {
+	id         string
+	salt       []byte
+	wrappedKey []byte
+	value      []byte
+}
+
+func parse(s string) (value, error) {
+	parts := strings.Split(strings.TrimPrefix(s, prefix), ":")
+	if len(parts) != 4 {
+		return value{}, errors.New("invalid encrypted value")
+	}
+
+	v := value{id: parts[0]}
+	for i, dst := range []*[]byte{&v.salt, &v.wrappedKey, &v.value} {
+		b, err := base64.StdEncoding.DecodeString(parts[i+1])
+		if err != nil {
+			return value{}, fmt.Errorf("base64.StdEncoding.DecodeString: %w", err)
+		}
+		*dst = b
+	}
+	return v, nil
+}
+
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("aes.NewCipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("cipher.NewGCM: %w", err)
+	}
+	return aead, nil
+}
+
+// seal returns nonce followed by ciphertext
+func seal(aead cipher.AEAD, plain []byte, additional string) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("rand.Read: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plain, []byte(additional)), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte, additional string) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encrypted value is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, []byte(additional))
+	if err != nil {
+		return nil, fmt.Errorf("aead.Open: %w", err)
+	}
+	return plain, nil
+}
@@ -0,0 +1,172 @@
+package envelope
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// staticKey is master key which isn't derived
+type staticKey []byte
+
+func (k staticKey) MasterKey([]byte) ([]byte, error) {
+	return k, nil
+}
+
+func (k staticKey) SaltSize() int {
+	return 0
+}
+
+func newCipher(t *testing.T, p Provider) *Cipher {
+	t.Helper()
+	c, err := New(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	c := newCipher(t, staticKey(bytes.Repeat([]byte{1}, keySize)))
+
+	enc, err := c.Encrypt("private key", "pool:1:base_prv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(enc) {
+		t.Fatalf("%s isn't encrypted", enc)
+	}
+
+	again, err := c.Encrypt(enc, "pool:1:base_prv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != enc {
+		t.Error("encrypted value is encrypted again")
+	}
+
+	plain, err := c.Decrypt(enc, "pool:1:base_prv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != "private key" {
+		t.Errorf("Decrypt() = %q, want %q", plain, "private key")
+	}
+
+	// value copied to another document or field isn't decrypted
+	for _, additional := range []string{"pool:2:base_prv", "other:1:base_prv", "pool:1:signer_prv"} {
+		if _, err = c.Decrypt(enc, additional); err == nil {
+			t.Errorf("value is decrypted with %s", additional)
+		}
+	}
+
+	other := newCipher(t, staticKey(bytes.Repeat([]byte{2}, keySize)))
+	if _, err = other.Decrypt(enc, "pool:1:base_prv"); err == nil {
+		t.Error("value is decrypted with another master key")
+	}
+}
+
+func TestNilCipher(t *testing.T) {
+	var c *Cipher
+
+	enc, err := c.Encrypt("private key", "pool:1:base_prv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc != "private key" {
+		t.Errorf("nil cipher changed value to %s", enc)
+	}
+
+	plain, err := c.Decrypt("private key", "pool:1:base_prv")
+	if err != nil || plain != "private key" {
+		t.Errorf("Decrypt() = %q, %v, want plain text as is", plain, err)
+	}
+
+	key := newCipher(t, staticKey(bytes.Repeat([]byte{1}, keySize)))
+	enc, err = key.Encrypt("private key", "pool:1:base_prv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.Decrypt(enc, "pool:1:base_prv"); !errors.Is(err, ErrNoMasterKey) {
+		t.Errorf("Decrypt() error = %v, want %v", err, ErrNoMasterKey)
+	}
+}
+
+func TestPassphraseSalt(t *testing.T) {
+	first := newCipher(t, PassphraseProvider{Passphrase: "passphrase"})
+	second := newCipher(t, PassphraseProvider{Passphrase: "passphrase"})
+	if first.ID() == second.ID() {
+		t.Error("keys derived from the same passphrase have the same salt")
+	}
+
+	enc, err := first.Encrypt("private key", "pool:1:base_prv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// salt is kept in the value, so the key is derived again by another run
+	plain, err := second.Decrypt(enc, "pool:1:base_prv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != "private key" {
+		t.Errorf("Decrypt() = %q, want %q", plain, "private key")
+	}
+
+	wrong := newCipher(t, PassphraseProvider{Passphrase: "wrong"})
+	if _, err = wrong.Decrypt(enc, "pool:1:base_prv"); err == nil {
+		t.Error("value is decrypted with wrong passphrase")
+	}
+}
+
+func TestWithOld(t *testing.T) {
+	oldKey := newCipher(t, staticKey(bytes.Repeat([]byte{1}, keySize)))
+	newKey := newCipher(t, staticKey(bytes.Repeat([]byte{2}, keySize)))
+	keyring := newKey.WithOld(oldKey)
+
+	oldValue, err := oldKey.Encrypt("old", "pool:1:base_prv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newValue, err := keyring.Encrypt("new", "pool:1:signer_prv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		value, additional, want string
+	}{
+		{oldValue, "pool:1:base_prv", "old"},
+		{newValue, "pool:1:signer_prv", "new"},
+	} {
+		plain, e := keyring.Decrypt(tt.value, tt.additional)
+		if e != nil {
+			t.Fatal(e)
+		}
+		if plain != tt.want {
+			t.Errorf("Decrypt() = %q, want %q", plain, tt.want)
+		}
+	}
+
+	// keyring encrypts with the new key only
+	owned, err := newKey.Owns(newValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !owned {
+		t.Error("value of keyring isn't encrypted with the new key")
+	}
+	owned, err = newKey.Owns(oldValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owned {
+		t.Error("value of the old key is owned by the new one")
+	}
+	owned, err = newKey.Owns("plain text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owned {
+		t.Error("plain text is owned")
+	}
+}
@@ -0,0 +1,73 @@
+package envelope
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Provider returns the master key, salt is used only by keys derived from passphrase
+type Provider interface {
+	MasterKey(salt []byte) ([]byte, error)
+	// SaltSize is 0 if the key isn't derived
+	SaltSize() int
+}
+
+// FileProvider reads hex encoded 32 bytes key, e.g. generated with 'openssl rand -hex 32'
+type FileProvider struct {
+	Path string
+}
+
+func (p FileProvider) MasterKey([]byte) ([]byte, error) {
+	b, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadFile: %w", err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString: %w", err)
+	}
+	return key, nil
+}
+
+func (p FileProvider) SaltSize() int {
+	return 0
+}
+
+// PassphraseProvider derives the key from passphrase with scrypt,
+// salt is random and is kept in every value, so the key is derived again on any machine
+type PassphraseProvider struct {
+	Passphrase string
+}
+
+func (p PassphraseProvider) MasterKey(salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(p.Passphrase), salt, 1<<15, 8, 1, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("scrypt.Key: %w", err)
+	}
+	return key, nil
+}
+
+func (p PassphraseProvider) SaltSize() int {
+	return 16
+}
+
+// Open returns cipher with the master key of key file or passphrase, nil cipher if both are empty
+func Open(keyFile, passphrase string) (*Cipher, error) {
+	var p Provider
+	switch {
+	case keyFile != "" && passphrase != "":
+		return nil, errors.New("either master key file or passphrase expected, not both")
+	case keyFile != "":
+		p = FileProvider{Path: keyFile}
+	case passphrase != "":
+		p = PassphraseProvider{Passphrase: passphrase}
+	default:
+		return nil, nil
+	}
+	return New(p)
+}
@@ -0,0 +1,53 @@
+package fee
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/txfields"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const (
+	// maxRounds limits re-estimation, fee is a part of the body, so it may change the size of data and setScript
+	maxRounds = 3
+	// placeholder is set before estimation, the node doesn't parse transactions with zero fee
+	placeholder = 100000
+)
+
+// Set estimates minimal fee of unsigned transaction by the node and sets it, so it's called before signing
+func Set(ctx context.Context, nd node.Node, tx proto.Transaction) error {
+	if tx.GetFee() == 0 {
+		err := txfields.SetFee(nd.Scheme(), tx, placeholder)
+		if err != nil {
+			return fmt.Errorf("txfields.SetFee: %w", err)
+		}
+	}
+
+	var highest uint64
+	for i := 0; i < maxRounds; i++ {
+		fee, err := nd.CalculateFee(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("nd.CalculateFee: %w", err)
+		}
+		if fee == tx.GetFee() {
+			return nil
+		}
+		if fee > highest {
+			highest = fee
+		}
+
+		err = txfields.SetFee(nd.Scheme(), tx, fee)
+		if err != nil {
+			return fmt.Errorf("txfields.SetFee: %w", err)
+		}
+	}
+
+	// estimation didn't converge, the last one may be lower than needed
+	err := txfields.SetFee(nd.Scheme(), tx, highest)
+	if err != nil {
+		return fmt.Errorf("txfields.SetFee: %w", err)
+	}
+	return nil
+}
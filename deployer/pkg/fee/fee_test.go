@@ -0,0 +1,85 @@
+package fee
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// rounds returns the given fees one by one and records the fee of the estimated transaction
+type rounds struct {
+	*node.Fake
+	fees []uint64
+	seen []uint64
+	err  error
+}
+
+func (r *rounds) CalculateFee(_ context.Context, tx proto.Transaction) (uint64, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	r.seen = append(r.seen, tx.GetFee())
+	fee := r.fees[0]
+	if len(r.fees) > 1 {
+		r.fees = r.fees[1:]
+	}
+	return fee, nil
+}
+
+func dataTx(fee uint64) *proto.DataWithProofs {
+	acc := node.NewFakeAccount(proto.TestNetScheme, "fee")
+	return proto.NewUnsignedDataWithProofs(2, acc.PublicKey, fee, uint64(time.Now().UnixMilli()))
+}
+
+func TestSet(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fee  uint64
+		fees []uint64
+		want uint64
+		seen []uint64
+	}{
+		{name: "estimated", fee: 500000, fees: []uint64{500000}, want: 500000, seen: []uint64{500000}},
+		{name: "placeholder", fees: []uint64{900000}, want: 900000, seen: []uint64{placeholder, 900000}},
+		{name: "size grows with fee", fees: []uint64{500000, 600000}, want: 600000, seen: []uint64{placeholder, 500000, 600000}},
+		{
+			name: "highest if not converged",
+			fees: []uint64{500000, 700000, 600000, 800000},
+			want: 700000,
+			seen: []uint64{placeholder, 500000, 700000},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			nd := &rounds{Fake: node.NewFake(proto.TestNetScheme), fees: tc.fees}
+			tx := dataTx(tc.fee)
+			err := Set(context.Background(), nd, tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tx.Fee != tc.want {
+				t.Errorf("fee = %d, want %d", tx.Fee, tc.want)
+			}
+			if len(nd.seen) != len(tc.seen) {
+				t.Fatalf("estimated fees %v, want %v", nd.seen, tc.seen)
+			}
+			for i := range nd.seen {
+				if nd.seen[i] != tc.seen[i] {
+					t.Errorf("estimated fees %v, want %v", nd.seen, tc.seen)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSetError(t *testing.T) {
+	failure := errors.New("node is down")
+	nd := &rounds{Fake: node.NewFake(proto.TestNetScheme), err: failure}
+	if err := Set(context.Background(), nd, dataTx(0)); !errors.Is(err, failure) {
+		t.Errorf("Set() error = %v, want %v", err, failure)
+	}
+}
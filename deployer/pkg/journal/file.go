@@ -0,0 +1,93 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/jsonfile"
+)
+
+// fileModel keeps journals in local JSON file
+type fileModel struct {
+	name string
+	mu   *sync.Mutex
+}
+
+func NewFileModel(name string) Model {
+	return fileModel{
+		name: name,
+		mu:   &sync.Mutex{},
+	}
+}
+
+func (m fileModel) load() ([]Step, error) {
+	var docs []Step
+	err := jsonfile.Load(m.name, &docs)
+	if err != nil {
+		return nil, fmt.Errorf("jsonfile.Load: %w", err)
+	}
+	return docs, nil
+}
+
+func (m fileModel) save(docs []Step) error {
+	err := jsonfile.Save(m.name, docs)
+	if err != nil {
+		return fmt.Errorf("jsonfile.Save: %w", err)
+	}
+	return nil
+}
+
+func (m fileModel) GetStage(_ context.Context, stage uint32) ([]Step, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Step
+	for _, doc := range docs {
+		if doc.Stage == stage {
+			res = append(res, doc)
+		}
+	}
+	return res, nil
+}
+
+func (m fileModel) Save(_ context.Context, st Step) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	for i, doc := range docs {
+		if doc.Stage == st.Stage && doc.Name == st.Name {
+			docs[i] = st
+			return m.save(docs)
+		}
+	}
+	return m.save(append(docs, st))
+}
+
+func (m fileModel) DeleteStage(_ context.Context, stage uint32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	res := make([]Step, 0, len(docs))
+	for _, doc := range docs {
+		if doc.Stage != stage {
+			res = append(res, doc)
+		}
+	}
+	return m.save(res)
+}
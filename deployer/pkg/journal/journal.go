@@ -0,0 +1,264 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/waves-exchange/contracts/deployer/pkg/confirm"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/tools"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusDone    Status = "done"
+)
+
+// Step is unique by stage and name
+type Step struct {
+	Stage   uint32 `bson:"stage" json:"stage"`
+	Name    string `bson:"name" json:"name"`
+	Account string `bson:"account" json:"account"`
+	TxID    string `bson:"txId,omitempty" json:"txId,omitempty"`
+	// Value is kept by steps without transaction, e.g. the height stage is created at
+	Value  string    `bson:"value,omitempty" json:"value,omitempty"`
+	Status Status    `bson:"status" json:"status"`
+	Time   time.Time `bson:"time" json:"time"`
+}
+
+// Model keeps steps applied by create-stage
+type Model interface {
+	GetStage(ctx context.Context, stage uint32) ([]Step, error)
+	// Save creates the step or replaces the one with the same stage and name
+	Save(ctx context.Context, st Step) error
+	DeleteStage(ctx context.Context, stage uint32) error
+}
+
+// Applied reports chain state is already as the step makes it,
+// txID is the transaction of the previous run that made it if it's known, e.g. issue of the asset
+type Applied func(ctx context.Context) (ok bool, txID string, err error)
+
+// Journal is the steps of one stage, every step is saved to the model as soon as its status changes
+type Journal struct {
+	logger zerolog.Logger
+	model  Model
+	stage  uint32
+	mu     *sync.Mutex
+	steps  map[string]Step
+}
+
+func Open(ctx context.Context, logger zerolog.Logger, model Model, stage uint32) (*Journal, error) {
+	docs, err := model.GetStage(ctx, stage)
+	if err != nil {
+		return nil, fmt.Errorf("model.GetStage: %w", err)
+	}
+
+	steps := map[string]Step{}
+	for _, st := range docs {
+		steps[st.Name] = st
+	}
+
+	return &Journal{
+		logger: logger.With().Str("pkg", "journal").Logger(),
+		model:  model,
+		stage:  stage,
+		mu:     &sync.Mutex{},
+		steps:  steps,
+	}, nil
+}
+
+func (j *Journal) Get(name string) (Step, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	st, ok := j.steps[name]
+	return st, ok
+}
+
+func (j *Journal) write(ctx context.Context, st Step) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	st.Stage = j.stage
+	st.Time = time.Now()
+	err := j.model.Save(ctx, st)
+	if err != nil {
+		return fmt.Errorf("j.model.Save: %w", err)
+	}
+
+	j.steps[st.Name] = st
+	return nil
+}
+
+// Value returns the value journaled by the first run, the given one is journaled if there is none,
+// so values of the first run are reused on resume. Nil journal returns the given value
+func (j *Journal) Value(ctx context.Context, name, value string) (string, error) {
+	if j == nil {
+		return value, nil
+	}
+	if st, ok := j.Get(name); ok && st.Status == StatusDone {
+		j.logger.Info().Str("step", name).Str("value", st.Value).Msg("value is journaled, reuse")
+		return st.Value, nil
+	}
+
+	err := j.write(ctx, Step{Name: name, Value: value, Status: StatusDone})
+	if err != nil {
+		return "", fmt.Errorf("j.write: %w", err)
+	}
+	return value, nil
+}
+
+// Apply runs send unless the step is applied already: it's journaled as done,
+// its journaled transaction is on chain, or applied reports chain state is already as desired.
+// Transaction id is journaled before broadcast, so crash between broadcast and confirmation is detected on resume.
+// Nil journal just runs send.
+func (j *Journal) Apply(
+	ctx context.Context,
+	nd node.Node,
+	name string,
+	tx proto.Transaction,
+	applied Applied,
+	send func(ctx context.Context) error,
+) error {
+	if j == nil {
+		return send(ctx)
+	}
+
+	sender, err := tx.GetSender(nd.Scheme())
+	if err != nil {
+		return fmt.Errorf("tx.GetSender: %w", err)
+	}
+	account := sender.String()
+
+	log := func() *zerolog.Event {
+		return j.logger.Info().Str("step", name).Str("account", account)
+	}
+
+	if st, ok := j.Get(name); ok {
+		if st.Status == StatusDone {
+			log().Str("txId", st.TxID).Msg("step is journaled as done, skip")
+			return nil
+		}
+
+		if st.TxID != "" {
+			id, e := crypto.NewDigestFromBase58(st.TxID)
+			if e != nil {
+				return fmt.Errorf("crypto.NewDigestFromBase58: %w", e)
+			}
+			// the transaction of the previous run may be still in the pool, sending a new one would duplicate it
+			mined, e := confirm.WaitID(ctx, nd, id, confirm.DefaultOptions())
+			if e != nil {
+				return fmt.Errorf("confirm.WaitID: %w", e)
+			}
+			if mined {
+				log().Str("txId", st.TxID).Msg("pending step transaction is on chain, skip")
+				st.Status = StatusDone
+				return j.write(ctx, st)
+			}
+			log().Str("txId", st.TxID).Msg("pending step transaction is neither on chain nor in the pool, send again")
+		}
+	}
+
+	if applied != nil {
+		ok, txID, e := applied(ctx)
+		if e != nil {
+			return fmt.Errorf("applied: %w", e)
+		}
+		if ok {
+			log().Str("txId", txID).Msg("chain state is up to date, skip")
+			return j.write(ctx, Step{Name: name, Account: account, TxID: txID, Status: StatusDone})
+		}
+	}
+
+	id, err := tools.TxID(nd.Scheme(), tx)
+	if err != nil {
+		return fmt.Errorf("tools.TxID: %w", err)
+	}
+
+	st := Step{Name: name, Account: account, TxID: id.String(), Status: StatusPending}
+	err = j.write(ctx, st)
+	if err != nil {
+		return fmt.Errorf("j.write: %w", err)
+	}
+
+	// expired transaction may be signed again with fresh timestamp,
+	// the new id is journaled before it's broadcast
+	err = send(confirm.OnRebuilt(ctx, func(ctx context.Context, id crypto.Digest) error {
+		st.TxID = id.String()
+		return j.write(ctx, st)
+	}))
+	if err != nil {
+		return err
+	}
+
+	st.Status = StatusDone
+	err = j.write(ctx, st)
+	if err != nil {
+		return fmt.Errorf("j.write: %w", err)
+	}
+	return nil
+}
+
+// Funded checks the address has the amount already, so the transfer of the previous run isn't sent twice
+func Funded(nd node.Node, addr proto.WavesAddress, amount uint64) Applied {
+	return func(ctx context.Context) (bool, string, error) {
+		balance, err := nd.Balance(ctx, addr)
+		if err != nil {
+			return false, "", fmt.Errorf("nd.Balance: %w", err)
+		}
+		return balance >= amount, "", nil
+	}
+}
+
+const pageSize = 100
+
+// Sent returns id of the newest transaction sent by addr that matches, empty string if there is none,
+// it finds transactions of the previous run which aren't journaled
+func Sent(
+	ctx context.Context,
+	nd node.Node,
+	addr proto.WavesAddress,
+	match func(tx proto.Transaction) bool,
+) (string, error) {
+	var after *crypto.Digest
+	for {
+		txs, err := nd.AddressTransactions(ctx, addr, pageSize, after)
+		if err != nil {
+			return "", fmt.Errorf("nd.AddressTransactions: %w", err)
+		}
+
+		for _, tx := range txs {
+			sender, e := tx.GetSender(nd.Scheme())
+			if e != nil {
+				return "", fmt.Errorf("tx.GetSender: %w", e)
+			}
+			senderAddr, e := sender.ToWavesAddress(nd.Scheme())
+			if e != nil {
+				return "", fmt.Errorf("sender.ToWavesAddress: %w", e)
+			}
+			if senderAddr != addr || !match(tx) {
+				continue
+			}
+			id, e := tools.TxID(nd.Scheme(), tx)
+			if e != nil {
+				return "", fmt.Errorf("tools.TxID: %w", e)
+			}
+			return id.String(), nil
+		}
+
+		if len(txs) < pageSize {
+			return "", nil
+		}
+		last, err := tools.TxID(nd.Scheme(), txs[len(txs)-1])
+		if err != nil {
+			return "", fmt.Errorf("tools.TxID: %w", err)
+		}
+		after = &last
+	}
+}
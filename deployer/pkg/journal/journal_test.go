@@ -0,0 +1,360 @@
+package journal
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/waves-exchange/contracts/deployer/pkg/confirm"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/tools"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const stage = 1
+
+type fixture struct {
+	nd    *node.Fake
+	acc   node.FakeAccount
+	model Model
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+	nd := node.NewFake(proto.TestNetScheme)
+	acc := node.NewFakeAccount(nd.Scheme(), "journal")
+	nd.SetBalance(acc.Address, 1_0000_0000)
+	return fixture{nd: nd, acc: acc, model: NewFileModel(filepath.Join(t.TempDir(), "journal.json"))}
+}
+
+func (f fixture) tx(t *testing.T, ts time.Time) *proto.DataWithProofs {
+	t.Helper()
+	tx := proto.NewUnsignedDataWithProofs(2, f.acc.PublicKey, 500000, uint64(ts.UnixMilli()))
+	err := tx.AppendEntry(&proto.IntegerDataEntry{Key: "%s__key", Value: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tx.Sign(f.nd.Scheme(), f.acc.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func (f fixture) open(t *testing.T) *Journal {
+	t.Helper()
+	j, err := Open(context.Background(), zerolog.Nop(), f.model, stage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return j
+}
+
+// send broadcasts tx and counts calls
+func (f fixture) send(tx proto.Transaction, calls *int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		*calls++
+		return f.nd.Broadcast(ctx, tx)
+	}
+}
+
+func (f fixture) pending(t *testing.T, name string, tx proto.Transaction) {
+	t.Helper()
+	id, err := tools.TxID(f.nd.Scheme(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.model.Save(context.Background(), Step{Stage: stage, Name: name, TxID: id.String(), Status: StatusPending})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	tx := f.tx(t, time.Now())
+	calls := 0
+
+	j := f.open(t)
+	err := j.Apply(ctx, f.nd, "data", tx, nil, f.send(tx, &calls))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatalf("send is called %d times, want 1", calls)
+	}
+
+	// the step is read back on resume
+	j = f.open(t)
+	st, ok := j.Get("data")
+	if !ok || st.Status != StatusDone {
+		t.Fatalf("Get() = %+v, %t, want done step", st, ok)
+	}
+	id, err := tools.TxID(f.nd.Scheme(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.TxID != id.String() {
+		t.Errorf("journaled txId = %s, want %s", st.TxID, id)
+	}
+
+	err = j.Apply(ctx, f.nd, "data", tx, nil, f.send(tx, &calls))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("done step is sent again")
+	}
+}
+
+func TestApplyApplied(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	tx := f.tx(t, time.Now())
+	calls := 0
+
+	j := f.open(t)
+	err := j.Apply(ctx, f.nd, "data", tx, func(context.Context) (bool, string, error) {
+		return true, "previous", nil
+	}, f.send(tx, &calls))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Error("step is sent while chain state is up to date")
+	}
+	if st, _ := j.Get("data"); st.Status != StatusDone || st.TxID != "previous" {
+		t.Errorf("Get() = %+v, want done step with transaction of the previous run", st)
+	}
+}
+
+func TestApplyPending(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("on chain", func(t *testing.T) {
+		f := newFixture(t)
+		tx := f.tx(t, time.Now())
+		err := f.nd.Broadcast(ctx, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.pending(t, "data", tx)
+		calls := 0
+
+		j := f.open(t)
+		err = j.Apply(ctx, f.nd, "data", tx, nil, f.send(tx, &calls))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls != 0 {
+			t.Error("pending transaction on chain is sent again")
+		}
+		if st, _ := j.Get("data"); st.Status != StatusDone {
+			t.Errorf("step status = %s, want %s", st.Status, StatusDone)
+		}
+	})
+
+	t.Run("lost", func(t *testing.T) {
+		f := newFixture(t)
+		tx := f.tx(t, time.Now())
+		f.pending(t, "data", tx)
+		calls := 0
+
+		j := f.open(t)
+		err := j.Apply(ctx, f.nd, "data", tx, nil, f.send(tx, &calls))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls != 1 {
+			t.Errorf("lost transaction is sent %d times, want 1", calls)
+		}
+	})
+}
+
+func TestApplyRebuilt(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	tx := f.tx(t, time.Now().Add(-3*time.Hour))
+
+	j := f.open(t)
+	opts := confirm.DefaultOptions()
+	opts.PollInterval = time.Millisecond
+	err := j.Apply(ctx, f.nd, "data", tx, nil, func(ctx context.Context) error {
+		return confirm.Wait(ctx, f.nd, tx, opts, func(_ context.Context, tx proto.Transaction) error {
+			return tx.Sign(f.nd.Scheme(), f.acc.SecretKey)
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := tools.TxID(f.nd.Scheme(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := j.Get("data"); st.TxID != id.String() || st.Status != StatusDone {
+		t.Errorf("Get() = %+v, want done step with rebuilt txId %s", st, id)
+	}
+}
+
+func TestApplyNil(t *testing.T) {
+	f := newFixture(t)
+	tx := f.tx(t, time.Now())
+	calls := 0
+
+	var j *Journal
+	err := j.Apply(context.Background(), f.nd, "data", tx, func(context.Context) (bool, string, error) {
+		return true, "", nil
+	}, f.send(tx, &calls))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("nil journal called send %d times, want 1", calls)
+	}
+}
+
+func TestStages(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	tx := f.tx(t, time.Now())
+	calls := 0
+
+	err := f.open(t).Apply(ctx, f.nd, "data", tx, nil, f.send(tx, &calls))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := Open(ctx, zerolog.Nop(), f.model, stage+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := other.Get("data"); ok {
+		t.Error("step of another stage is read")
+	}
+
+	err = f.model.DeleteStage(ctx, stage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := f.open(t).Get("data"); ok {
+		t.Error("step of deleted stage is read")
+	}
+}
+
+func TestSent(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	other := node.NewFakeAccount(f.nd.Scheme(), "other")
+	f.nd.SetBalance(other.Address, 10_0000_0000)
+
+	issue := func(acc node.FakeAccount, name string) crypto.Digest {
+		tx := proto.NewUnsignedIssueWithProofs(
+			3, acc.PublicKey, name, "", 1000, 0, false, nil, uint64(time.Now().UnixMilli()), 1_0000_0000,
+		)
+		err := tx.Sign(f.nd.Scheme(), acc.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = f.nd.Broadcast(ctx, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *tx.ID
+	}
+	issuedBy := func(name string) func(tx proto.Transaction) bool {
+		return func(tx proto.Transaction) bool {
+			issue, ok := tx.(*proto.IssueWithProofs)
+			return ok && issue.Name == name
+		}
+	}
+
+	want := issue(f.acc, "USDN")
+	issue(other, "USDT")
+	// the first page is filled with transactions of the account received from another one
+	for i := 0; i < pageSize; i++ {
+		tx := proto.NewUnsignedTransferWithProofs(
+			3, other.PublicKey, proto.NewOptionalAssetWaves(), proto.NewOptionalAssetWaves(),
+			uint64(time.Now().UnixMilli())+uint64(i), 1, 100000, proto.NewRecipientFromAddress(f.acc.Address), nil,
+		)
+		err := tx.Sign(f.nd.Scheme(), other.SecretKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = f.nd.Broadcast(ctx, tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	id, err := Sent(ctx, f.nd, f.acc.Address, issuedBy("USDN"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != want.String() {
+		t.Errorf("Sent() = %q, want %s", id, want)
+	}
+	id, err = Sent(ctx, f.nd, f.acc.Address, issuedBy("USDT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "" {
+		t.Errorf("issue of another sender is found: %s", id)
+	}
+}
+
+func TestFunded(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	for _, tc := range []struct {
+		amount uint64
+		want   bool
+	}{
+		{amount: 1_0000_0000, want: true},
+		{amount: 1_0000_0001, want: false},
+	} {
+		ok, _, err := Funded(f.nd, f.acc.Address, tc.amount)(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != tc.want {
+			t.Errorf("Funded(%d) = %t, want %t", tc.amount, ok, tc.want)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+
+	v, err := f.open(t).Value(ctx, "env/height", "100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "100" {
+		t.Errorf("Value() = %q, want the given one of the first run", v)
+	}
+
+	// resumed run gets the value of the first one
+	v, err = f.open(t).Value(ctx, "env/height", "200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "100" {
+		t.Errorf("Value() = %q, want 100 journaled by the first run", v)
+	}
+
+	var j *Journal
+	v, err = j.Value(ctx, "env/height", "200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "200" {
+		t.Errorf("nil journal Value() = %q, want the given one", v)
+	}
+}
@@ -0,0 +1,63 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoModel struct {
+	coll *mongo.Collection
+}
+
+func NewMongoModel(coll *mongo.Collection) Model {
+	return mongoModel{
+		coll: coll,
+	}
+}
+
+func (m mongoModel) GetStage(c context.Context, stage uint32) ([]Step, error) {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	cur, err := m.coll.Find(ctx, bson.M{"stage": stage})
+	if err != nil {
+		return nil, fmt.Errorf("m.coll.Find: %w", err)
+	}
+
+	var res []Step
+	err = cur.All(ctx, &res)
+	if err != nil {
+		return nil, fmt.Errorf("cur.All: %w", err)
+	}
+	return res, nil
+}
+
+func (m mongoModel) Save(c context.Context, st Step) error {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	_, err := m.coll.ReplaceOne(ctx, bson.M{
+		"stage": st.Stage,
+		"name":  st.Name,
+	}, st, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("m.coll.ReplaceOne: %w", err)
+	}
+	return nil
+}
+
+func (m mongoModel) DeleteStage(c context.Context, stage uint32) error {
+	ctx, cancel := context.WithTimeout(c, 10*time.Second)
+	defer cancel()
+
+	_, err := m.coll.DeleteMany(ctx, bson.M{"stage": stage})
+	if err != nil {
+		return fmt.Errorf("m.coll.DeleteMany: %w", err)
+	}
+	return nil
+}
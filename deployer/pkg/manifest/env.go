@@ -0,0 +1,290 @@
+package manifest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/tools"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+type Account struct {
+	Address   proto.WavesAddress
+	PublicKey crypto.PublicKey
+}
+
+// Env resolves manifest references:
+// ${stage}, ${height}, ${timestamp}, ${vars.name}, ${accounts.name.address}, ${accounts.name.publicKey}, ${assets.ref.id}
+type Env struct {
+	scheme    proto.Scheme
+	stage     uint32
+	height    uint64
+	timestamp uint64
+	vars      map[string]string
+	accounts  map[string]Account
+	assets    map[string]crypto.Digest
+}
+
+func NewEnv(
+	scheme proto.Scheme,
+	m Manifest,
+	stage uint32,
+	height uint64,
+	timestamp uint64,
+	accounts map[string]Account,
+) *Env {
+	return &Env{
+		scheme:    scheme,
+		stage:     stage,
+		height:    height,
+		timestamp: timestamp,
+		vars:      m.Vars,
+		accounts:  accounts,
+		assets:    map[string]crypto.Digest{},
+	}
+}
+
+// SetAsset makes issued asset available as ${assets.ref.id}
+func (env *Env) SetAsset(ref string, id crypto.Digest) {
+	env.assets[ref] = id
+}
+
+var refRegexp = regexp.MustCompile(`\$\{([^}]+)}`)
+
+func (env *Env) Expand(s string) (string, error) {
+	var errs []string
+	res := refRegexp.ReplaceAllStringFunc(s, func(ref string) string {
+		v, err := env.resolve(refRegexp.FindStringSubmatch(ref)[1])
+		if err != nil {
+			errs = append(errs, err.Error())
+			return ref
+		}
+		return v
+	})
+	if len(errs) != 0 {
+		return "", fmt.Errorf("can't resolve %q: %s", s, strings.Join(errs, ", "))
+	}
+	return res, nil
+}
+
+func (env *Env) resolve(ref string) (string, error) {
+	parts := strings.Split(ref, ".")
+	switch {
+	case ref == "stage":
+		return strconv.Itoa(int(env.stage)), nil
+	case ref == "height":
+		return strconv.FormatUint(env.height, 10), nil
+	case ref == "timestamp":
+		return strconv.FormatUint(env.timestamp, 10), nil
+	case len(parts) == 2 && parts[0] == "vars":
+		v, ok := env.vars[parts[1]]
+		if !ok {
+			return "", fmt.Errorf("unknown var: %s", parts[1])
+		}
+		return v, nil
+	case len(parts) == 3 && parts[0] == "accounts":
+		acc, ok := env.accounts[parts[1]]
+		if !ok {
+			return "", fmt.Errorf("unknown account: %s", parts[1])
+		}
+		switch parts[2] {
+		case "address":
+			return acc.Address.String(), nil
+		case "publicKey":
+			return acc.PublicKey.String(), nil
+		}
+	case len(parts) == 3 && parts[0] == "assets" && parts[2] == "id":
+		id, ok := env.assets[parts[1]]
+		if !ok {
+			return "", fmt.Errorf("unknown or not issued asset: %s", parts[1])
+		}
+		return id.String(), nil
+	}
+	return "", fmt.Errorf("unknown reference: %s", ref)
+}
+
+func (env *Env) account(name string) (Account, error) {
+	acc, ok := env.accounts[name]
+	if !ok {
+		return Account{}, fmt.Errorf("unknown account: %s", name)
+	}
+	return acc, nil
+}
+
+func (env *Env) IssueTx(a Asset) (*proto.IssueWithProofs, error) {
+	issuer, err := env.account(a.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	name, err := env.Expand(a.Name)
+	if err != nil {
+		return nil, err
+	}
+	description, err := env.Expand(a.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := proto.NewUnsignedIssueWithProofs(
+		2,
+		issuer.PublicKey,
+		name,
+		description,
+		a.Quantity,
+		a.Decimals,
+		a.Reissuable,
+		nil,
+		tools.Timestamp(),
+		0,
+	)
+	err = tx.GenerateID(env.scheme)
+	if err != nil {
+		return nil, fmt.Errorf("tx.GenerateID: %w", err)
+	}
+	return tx, nil
+}
+
+func (env *Env) DataEntries(entries []DataEntry) ([]proto.DataEntry, error) {
+	res := make([]proto.DataEntry, 0, len(entries))
+	for _, d := range entries {
+		key, err := env.Expand(d.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := env.Expand(d.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		switch d.Type {
+		case TypeString:
+			res = append(res, &proto.StringDataEntry{Key: key, Value: value})
+		case TypeInteger:
+			v, e := strconv.ParseInt(value, 10, 64)
+			if e != nil {
+				return nil, fmt.Errorf("strconv.ParseInt: %w", e)
+			}
+			res = append(res, &proto.IntegerDataEntry{Key: key, Value: v})
+		case TypeBoolean:
+			v, e := strconv.ParseBool(value)
+			if e != nil {
+				return nil, fmt.Errorf("strconv.ParseBool: %w", e)
+			}
+			res = append(res, &proto.BooleanDataEntry{Key: key, Value: v})
+		case TypeBinary:
+			v, e := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, "base64:"))
+			if e != nil {
+				return nil, fmt.Errorf("base64.StdEncoding.DecodeString: %w", e)
+			}
+			res = append(res, &proto.BinaryDataEntry{Key: key, Value: v})
+		case TypeDelete:
+			res = append(res, &proto.DeleteDataEntry{Key: key})
+		default:
+			return nil, fmt.Errorf("unknown data entry type %q of key %s", d.Type, key)
+		}
+	}
+	return res, nil
+}
+
+func (env *Env) Arguments(args []Argument) (proto.Arguments, error) {
+	res := proto.Arguments{}
+	for _, a := range args {
+		if a.Type == TypeList {
+			items, err := env.Arguments(a.Items)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, &proto.ListArgument{Items: items})
+			continue
+		}
+
+		value, err := env.Expand(a.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		switch a.Type {
+		case TypeString:
+			res = append(res, proto.NewStringArgument(value))
+		case TypeInteger:
+			v, e := strconv.ParseInt(value, 10, 64)
+			if e != nil {
+				return nil, fmt.Errorf("strconv.ParseInt: %w", e)
+			}
+			res = append(res, proto.NewIntegerArgument(v))
+		case TypeBoolean:
+			v, e := strconv.ParseBool(value)
+			if e != nil {
+				return nil, fmt.Errorf("strconv.ParseBool: %w", e)
+			}
+			res = append(res, &proto.BooleanArgument{Value: v})
+		case TypeBinary:
+			v, e := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, "base64:"))
+			if e != nil {
+				return nil, fmt.Errorf("base64.StdEncoding.DecodeString: %w", e)
+			}
+			res = append(res, &proto.BinaryArgument{Value: v})
+		default:
+			return nil, fmt.Errorf("unknown argument type %q", a.Type)
+		}
+	}
+	return res, nil
+}
+
+// InvokeTx builds constructor invoke, dApp defaults to contractAccount and caller defaults to the manager
+func (env *Env) InvokeTx(contractAccount string, inv Invoke) (*proto.InvokeScriptWithProofs, error) {
+	callerName := inv.Caller
+	if callerName == "" {
+		callerName = managerAccount
+	}
+	caller, err := env.account(callerName)
+	if err != nil {
+		return nil, err
+	}
+
+	dAppName := inv.DApp
+	if dAppName == "" {
+		dAppName = contractAccount
+	}
+	dApp, err := env.account(dAppName)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := env.Arguments(inv.Args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inv.Function, err)
+	}
+
+	var payments proto.ScriptPayments
+	for _, p := range inv.Payments {
+		asset := proto.NewOptionalAssetWaves()
+		if p.Asset != "" {
+			id, e := env.Expand(p.Asset)
+			if e != nil {
+				return nil, e
+			}
+			a, e := proto.NewOptionalAssetFromString(id)
+			if e != nil {
+				return nil, fmt.Errorf("proto.NewOptionalAssetFromString: %w", e)
+			}
+			asset = *a
+		}
+		payments = append(payments, proto.ScriptPayment{Amount: p.Amount, Asset: asset})
+	}
+
+	return proto.NewUnsignedInvokeScriptWithProofs(
+		1,
+		caller.PublicKey,
+		proto.NewRecipientFromAddress(dApp.Address),
+		proto.NewFunctionCall(inv.Function, args),
+		payments,
+		proto.NewOptionalAssetWaves(),
+		inv.Fee,
+		tools.Timestamp(),
+	), nil
+}
@@ -0,0 +1,85 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+func newTestEnv(t *testing.T) (*Env, Account, crypto.Digest) {
+	t.Helper()
+	manager := node.NewFakeAccount(proto.TestNetScheme, "manager")
+	acc := Account{Address: manager.Address, PublicKey: manager.PublicKey}
+
+	env := NewEnv(
+		proto.TestNetScheme,
+		Manifest{Vars: map[string]string{"decimals": "8"}},
+		3,
+		1806750,
+		1637580329884,
+		map[string]Account{managerAccount: acc},
+	)
+	id := crypto.MustDigestFromBase58("25FEqEjRkqK6yCkiT7Lz6SAYz7gUFCtxfCChnrVFD5AT")
+	env.SetAsset("XTN", id)
+	return env, acc, id
+}
+
+func TestExpand(t *testing.T) {
+	env, acc, id := newTestEnv(t)
+
+	for _, tc := range []struct {
+		s    string
+		want string
+	}{
+		{s: "plain", want: "plain"},
+		{s: "${stage}", want: "3"},
+		{s: "%s__${height}__${timestamp}", want: "%s__1806750__1637580329884"},
+		{s: "${vars.decimals}", want: "8"},
+		{s: "${accounts." + managerAccount + ".address}", want: acc.Address.String()},
+		{s: "${accounts." + managerAccount + ".publicKey}", want: acc.PublicKey.String()},
+		{s: "${assets.XTN.id}__${stage}", want: id.String() + "__3"},
+	} {
+		got, err := env.Expand(tc.s)
+		if err != nil {
+			t.Errorf("Expand(%q) error = %v", tc.s, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("Expand(%q) = %q, want %q", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestExpandUnknown(t *testing.T) {
+	env, _, _ := newTestEnv(t)
+
+	for _, tc := range []struct {
+		s   string
+		err string
+	}{
+		{s: "${vars.missing}", err: "unknown var: missing"},
+		{s: "${accounts.nobody.address}", err: "unknown account: nobody"},
+		{s: "${accounts." + managerAccount + ".secretKey}", err: "unknown reference"},
+		{s: "${assets.USDN.id}", err: "unknown or not issued asset: USDN"},
+		{s: "${network}", err: "unknown reference: network"},
+		{s: "${stage}__${vars.a}__${vars.b}", err: "unknown var: a, unknown var: b"},
+	} {
+		_, err := env.Expand(tc.s)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("Expand(%q) error = %v, want %q", tc.s, err, tc.err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	m, err := Load("../../manifests/testnet_stage.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Contracts) == 0 {
+		t.Error("stage manifest has no contracts")
+	}
+}
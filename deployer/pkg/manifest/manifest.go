@@ -0,0 +1,215 @@
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest describes testnet stage: accounts, issued assets and contracts deployed by create-stage.
+// String values may contain references resolved by Env, e.g. ${accounts.factoryV2.address} or ${assets.XTN.id}
+type Manifest struct {
+	Branch string `yaml:"branch"`
+	Node   string `yaml:"node"`
+	// Vars are constants available as ${vars.name}
+	Vars map[string]string `yaml:"vars"`
+	// Accounts maps account name to its index, account keys are derived from base seed, stage and index
+	Accounts  map[string]uint32 `yaml:"accounts"`
+	Fund      []Fund            `yaml:"fund"`
+	Assets    []Asset           `yaml:"assets"`
+	Contracts []Contract        `yaml:"contracts"`
+}
+
+// Fund is WAVES transfer from fee seed to the account
+type Fund struct {
+	Account string `yaml:"account"`
+	Amount  uint64 `yaml:"amount"`
+}
+
+type Asset struct {
+	// Ref is the name asset is referenced by, e.g. ${assets.XTN.id}
+	Ref         string `yaml:"ref"`
+	Issuer      string `yaml:"issuer"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Quantity    uint64 `yaml:"quantity"`
+	Decimals    byte   `yaml:"decimals"`
+	Reissuable  bool   `yaml:"reissuable"`
+}
+
+type Contract struct {
+	Tag     string `yaml:"tag"`
+	File    string `yaml:"file"`
+	Compact bool   `yaml:"compact"`
+	// Account contract is deployed to
+	Account string `yaml:"account"`
+	// Signer is the second key to sign contract transactions, usually the manager
+	Signer      string      `yaml:"signer"`
+	Data        []DataEntry `yaml:"data"`
+	Constructor []Invoke    `yaml:"constructor"`
+	// DependsOn lists tags of contracts which must be deployed before this one,
+	// e.g. when its constructor reads or invokes them
+	DependsOn []string `yaml:"dependsOn"`
+}
+
+type ValueType string
+
+const (
+	TypeString  ValueType = "string"
+	TypeInteger ValueType = "integer"
+	TypeBoolean ValueType = "boolean"
+	TypeBinary  ValueType = "binary"
+	TypeList    ValueType = "list"
+	TypeDelete  ValueType = "delete"
+)
+
+type DataEntry struct {
+	Key   string    `yaml:"key"`
+	Type  ValueType `yaml:"type"`
+	Value string    `yaml:"value"`
+}
+
+type Invoke struct {
+	// Caller is the sender account, the manager if empty
+	Caller string `yaml:"caller"`
+	// DApp is the invoked account, contract account if empty
+	DApp     string     `yaml:"dApp"`
+	Function string     `yaml:"function"`
+	Args     []Argument `yaml:"args"`
+	Payments []Payment  `yaml:"payments"`
+	// Fee is estimated by the node if empty
+	Fee uint64 `yaml:"fee"`
+}
+
+type Argument struct {
+	Type  ValueType  `yaml:"type"`
+	Value string     `yaml:"value"`
+	Items []Argument `yaml:"items"`
+}
+
+type Payment struct {
+	// Asset is asset id, WAVES if empty
+	Asset  string `yaml:"asset"`
+	Amount uint64 `yaml:"amount"`
+}
+
+const (
+	managerAccount = "manager"
+)
+
+func Load(name string) (Manifest, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	var m Manifest
+	err = yaml.Unmarshal(b, &m)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("yaml.Unmarshal: %w", err)
+	}
+
+	err = m.validate()
+	if err != nil {
+		return Manifest{}, fmt.Errorf("m.validate: %w", err)
+	}
+
+	return m, nil
+}
+
+// Dependencies returns tags of contracts c depends on:
+// listed in dependsOn and deployed to the same account before c, so the last script wins
+func (m Manifest) Dependencies(c Contract) []string {
+	res := append([]string{}, c.DependsOn...)
+	for _, prev := range m.Contracts {
+		if prev.Tag == c.Tag {
+			break
+		}
+		if prev.Account == c.Account {
+			res = append(res, prev.Tag)
+		}
+	}
+	return res
+}
+
+// validate checks account and asset names, references inside values are checked on resolve
+func (m Manifest) validate() error {
+	if m.Node == "" {
+		return errors.New("node is required")
+	}
+	if m.Branch == "" {
+		return errors.New("branch is required")
+	}
+	if _, ok := m.Accounts[managerAccount]; !ok {
+		return errors.New("manager account is required")
+	}
+
+	account := func(name string) error {
+		if _, ok := m.Accounts[name]; !ok {
+			return fmt.Errorf("unknown account: %s", name)
+		}
+		return nil
+	}
+
+	for _, f := range m.Fund {
+		if err := account(f.Account); err != nil {
+			return fmt.Errorf("fund: %w", err)
+		}
+	}
+
+	refs := map[string]bool{}
+	for _, a := range m.Assets {
+		if a.Ref == "" {
+			return errors.New("asset ref is required")
+		}
+		if refs[a.Ref] {
+			return fmt.Errorf("duplicated asset ref: %s", a.Ref)
+		}
+		refs[a.Ref] = true
+		if err := account(a.Issuer); err != nil {
+			return fmt.Errorf("asset %s: %w", a.Ref, err)
+		}
+	}
+
+	tags := map[string]bool{}
+	for _, c := range m.Contracts {
+		if c.Tag == "" || c.File == "" {
+			return errors.New("contract tag and file are required")
+		}
+		if tags[c.Tag] {
+			return fmt.Errorf("duplicated contract tag: %s", c.Tag)
+		}
+		tags[c.Tag] = true
+
+		if err := account(c.Account); err != nil {
+			return fmt.Errorf("contract %s: %w", c.Tag, err)
+		}
+		if err := account(c.Signer); err != nil {
+			return fmt.Errorf("contract %s: signer: %w", c.Tag, err)
+		}
+		for _, dep := range c.DependsOn {
+			if !tags[dep] {
+				return fmt.Errorf("contract %s: depends on %s which is not listed before it", c.Tag, dep)
+			}
+		}
+		for _, inv := range c.Constructor {
+			if inv.Function == "" {
+				return fmt.Errorf("contract %s: constructor function is required", c.Tag)
+			}
+			if inv.Caller != "" {
+				if err := account(inv.Caller); err != nil {
+					return fmt.Errorf("contract %s: %s caller: %w", c.Tag, inv.Function, err)
+				}
+			}
+			if inv.DApp != "" {
+				if err := account(inv.DApp); err != nil {
+					return fmt.Errorf("contract %s: %s dApp: %w", c.Tag, inv.Function, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/jsonfile"
+)
+
+// fileModel keeps applied migrations in local JSON file
+type fileModel struct {
+	name string
+	mu   *sync.Mutex
+}
+
+func NewFileModel(name string) Model {
+	return fileModel{
+		name: name,
+		mu:   &sync.Mutex{},
+	}
+}
+
+func (m fileModel) load() ([]Migration, error) {
+	var docs []Migration
+	err := jsonfile.Load(m.name, &docs)
+	if err != nil {
+		return nil, fmt.Errorf("jsonfile.Load: %w", err)
+	}
+	return docs, nil
+}
+
+func (m fileModel) GetAll(_ context.Context, network config.Network) ([]Migration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Migration
+	for _, doc := range docs {
+		if doc.Network == network {
+			res = append(res, doc)
+		}
+	}
+	return res, nil
+}
+
+func (m fileModel) Get(_ context.Context, name string, network config.Network) (Migration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return Migration{}, err
+	}
+
+	for _, doc := range docs {
+		if doc.Name == name && doc.Network == network {
+			return doc, nil
+		}
+	}
+	return Migration{}, ErrNotFound
+}
+
+func (m fileModel) Save(_ context.Context, mig Migration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	docs, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for i, doc := range docs {
+		if doc.Name == mig.Name && doc.Network == mig.Network {
+			if !doc.Partial {
+				return fmt.Errorf("migration %s is already applied to %s", mig.Name, mig.Network)
+			}
+			docs[i] = mig
+			found = true
+		}
+	}
+	if !found {
+		docs = append(docs, mig)
+	}
+
+	err = jsonfile.Save(m.name, docs)
+	if err != nil {
+		return fmt.Errorf("jsonfile.Save: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+)
+
+func TestFileModel(t *testing.T) {
+	ctx := context.Background()
+	m := NewFileModel(filepath.Join(t.TempDir(), "migrations.json"))
+
+	if _, err := m.Get(ctx, "2023_04_25_a", config.Mainnet); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() error = %v, want %v", err, ErrNotFound)
+	}
+
+	// interrupted apply is saved after every confirmed transaction
+	for _, mig := range []Migration{
+		{Name: "2023_04_25_a", Network: config.Mainnet, TxIDs: []string{"a"}, Heights: []uint64{1}, Partial: true},
+		{Name: "2023_04_25_a", Network: config.Mainnet, TxIDs: []string{"a", "b"}, Heights: []uint64{1, 2}},
+		{Name: "2023_04_25_a", Network: config.Testnet, TxIDs: []string{"c"}, Heights: []uint64{3}},
+	} {
+		err := m.Save(ctx, mig)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mig, err := m.Get(ctx, "2023_04_25_a", config.Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mig.Partial || len(mig.TxIDs) != 2 {
+		t.Errorf("Get() = %+v, want applied migration with 2 transactions", mig)
+	}
+	all, err := m.GetAll(ctx, config.Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 {
+		t.Errorf("GetAll() returned %d migrations, want 1 of the network", len(all))
+	}
+
+	err = m.Save(ctx, Migration{Name: "2023_04_25_a", Network: config.Mainnet})
+	if err == nil {
+		t.Error("applied migration is saved again")
+	}
+}
@@ -0,0 +1,165 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/batch"
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+var (
+	// dateRegexp matches YYYY_MM_DD anywhere in the name, e.g. 2023_04_26_manager_vault or referral_2022_07_29_release
+	dateRegexp = regexp.MustCompile(`(\d{4})_(\d{2})_(\d{2})`)
+	// shortDateRegexp matches DD_MM_YY prefix, e.g. 26_10_22_pools
+	shortDateRegexp = regexp.MustCompile(`^(\d{2})_(\d{2})_(\d{2})_`)
+)
+
+// Folder is migrations/<name> with transactions in JSON files
+type Folder struct {
+	Name string
+	Path string
+	// Date is parsed from the name, zero for undated folders
+	Date    time.Time
+	Network config.Network
+	// Script is true if there are no transaction files, e.g. migration is made by index.mjs
+	Script bool
+}
+
+// List returns migrations in the order they are applied: undated folders by name first, then by date
+func List(root string) ([]Folder, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("os.ReadDir: %w", err)
+	}
+
+	var res []Folder
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		f, e := newFolder(root, entry.Name())
+		if e != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), e)
+		}
+		res = append(res, f)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if !res[i].Date.Equal(res[j].Date) {
+			return res[i].Date.Before(res[j].Date)
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res, nil
+}
+
+// Find returns folder by name
+func Find(folders []Folder, name string) (Folder, error) {
+	for _, f := range folders {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	return Folder{}, fmt.Errorf("no migration %s", name)
+}
+
+func newFolder(root, name string) (Folder, error) {
+	f := Folder{
+		Name: name,
+		Path: filepath.Join(root, name),
+		Date: parseDate(name),
+	}
+
+	dir, files, err := batch.TxFiles(f.Path)
+	if err != nil {
+		return Folder{}, fmt.Errorf("batch.TxFiles: %w", err)
+	}
+	f.Script = len(files) == 0
+
+	f.Network = config.Mainnet
+	if strings.Contains(name, string(config.Testnet)) {
+		f.Network = config.Testnet
+	}
+	for _, file := range files {
+		raw, e := os.ReadFile(filepath.Join(dir, file))
+		if e != nil {
+			return Folder{}, fmt.Errorf("os.ReadFile: %w", e)
+		}
+
+		var meta struct {
+			ChainID *proto.Scheme `json:"chainId"`
+		}
+		// invalid files are reported by Batch
+		if json.Unmarshal(raw, &meta) != nil || meta.ChainID == nil {
+			continue
+		}
+		switch *meta.ChainID {
+		case proto.TestNetScheme:
+			f.Network = config.Testnet
+		case proto.MainNetScheme:
+			f.Network = config.Mainnet
+		}
+		break
+	}
+	return f, nil
+}
+
+func parseDate(name string) time.Time {
+	if m := dateRegexp.FindStringSubmatch(name); m != nil {
+		return date(m[1], m[2], m[3])
+	}
+	if m := shortDateRegexp.FindStringSubmatch(name); m != nil {
+		return date("20"+m[3], m[2], m[1])
+	}
+	return time.Time{}
+}
+
+func date(year, month, day string) time.Time {
+	y, _ := strconv.Atoi(year)
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	if m < 1 || m > 12 || d < 1 || d > 31 {
+		return time.Time{}
+	}
+	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+}
+
+// Batch decodes and validates transactions of the folder,
+// transactions without timestamp get the given one
+func (f Folder) Batch(timestamp uint64) (*batch.Batch, error) {
+	if f.Script {
+		return nil, fmt.Errorf("migration %s has no transaction files, script migrations aren't supported", f.Name)
+	}
+
+	scheme, err := f.Network.Scheme()
+	if err != nil {
+		return nil, fmt.Errorf("f.Network.Scheme: %w", err)
+	}
+
+	b, err := batch.FromDir(f.Path, scheme, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("batch.FromDir: %w", err)
+	}
+
+	for _, item := range b.Items {
+		tx, e := item.Transaction()
+		if e != nil {
+			return nil, fmt.Errorf("item.Transaction: %w", e)
+		}
+		_, e = tx.Validate(scheme)
+		if e != nil {
+			return nil, fmt.Errorf("%s: tx.Validate: %w", item.File, e)
+		}
+	}
+	return b, nil
+}
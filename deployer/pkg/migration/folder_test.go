@@ -0,0 +1,77 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestList(t *testing.T) {
+	root := t.TempDir()
+	names := []string{
+		"2023_04_25_b",
+		"26_10_22_pools",
+		"undated_z",
+		"referral_2022_07_29_release",
+		"2023_04_25_a",
+		"undated_a",
+	}
+	for _, name := range names {
+		err := os.Mkdir(filepath.Join(root, name), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// files are ignored
+	err := os.WriteFile(filepath.Join(root, "README.md"), []byte("migrations"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	folders, err := List(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"undated_a",
+		"undated_z",
+		"referral_2022_07_29_release",
+		"26_10_22_pools",
+		"2023_04_25_a",
+		"2023_04_25_b",
+	}
+	if len(folders) != len(want) {
+		t.Fatalf("got %d folders, want %d", len(folders), len(want))
+	}
+	for i, f := range folders {
+		if f.Name != want[i] {
+			t.Errorf("folders[%d] = %s, want %s", i, f.Name, want[i])
+		}
+		if !f.Script {
+			t.Errorf("%s without transaction files isn't script", f.Name)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		name string
+		want time.Time
+	}{
+		{"2023_04_26_manager_vault", time.Date(2023, 4, 26, 0, 0, 0, 0, time.UTC)},
+		{"referral_2022_07_29_release", time.Date(2022, 7, 29, 0, 0, 0, 0, time.UTC)},
+		{"26_10_22_pools", time.Date(2022, 10, 26, 0, 0, 0, 0, time.UTC)},
+		{"2023_13_01_invalid_month", time.Time{}},
+		{"initial", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseDate(tt.name)
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
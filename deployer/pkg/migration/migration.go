@@ -0,0 +1,34 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+)
+
+// Migration is the record of migrations/<name> applied to the network
+type Migration struct {
+	Name    string         `bson:"name" json:"name"`
+	Network config.Network `bson:"network" json:"network"`
+	// TxIDs and Heights are in the order of migration transactions
+	TxIDs     []string  `bson:"txIds" json:"txIds"`
+	Heights   []uint64  `bson:"heights" json:"heights"`
+	AppliedAt time.Time `bson:"appliedAt" json:"appliedAt"`
+	// Partial is set while transactions are broadcast, TxIDs and Heights are of confirmed ones,
+	// so interrupted apply continues from the next transaction
+	Partial bool `bson:"partial,omitempty" json:"partial,omitempty"`
+}
+
+var ErrNotFound = errors.New("migration not found")
+
+// Model is registry of applied migrations
+type Model interface {
+	// GetAll returns migrations applied to the network
+	GetAll(ctx context.Context, network config.Network) ([]Migration, error)
+	// Get returns ErrNotFound if migration isn't applied to the network
+	Get(ctx context.Context, name string, network config.Network) (Migration, error)
+	// Save creates migration or replaces partial one, applied migration can't be saved again
+	Save(ctx context.Context, m Migration) error
+}
@@ -0,0 +1,80 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoModel struct {
+	coll *mongo.Collection
+}
+
+func NewMongoModel(coll *mongo.Collection) Model {
+	return mongoModel{
+		coll: coll,
+	}
+}
+
+func (m mongoModel) GetAll(ctx context.Context, network config.Network) ([]Migration, error) {
+	sortOptions := options.Find().SetSort(bson.M{"appliedAt": 1})
+	cur, err := m.coll.Find(ctx, bson.M{
+		"network": network,
+	}, sortOptions)
+	if err != nil {
+		return nil, fmt.Errorf("m.coll.Find: %w", err)
+	}
+
+	var docs []Migration
+	err = cur.All(ctx, &docs)
+	if err != nil {
+		return nil, fmt.Errorf("cur.All: %w", err)
+	}
+	return docs, nil
+}
+
+func (m mongoModel) Get(ctx context.Context, name string, network config.Network) (Migration, error) {
+	var doc Migration
+	err := m.coll.FindOne(ctx, bson.M{
+		"name":    name,
+		"network": network,
+	}).Decode(&doc)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return Migration{}, ErrNotFound
+		}
+		return Migration{}, fmt.Errorf("m.coll.FindOne: %w", err)
+	}
+	return doc, nil
+}
+
+func (m mongoModel) Save(ctx context.Context, mig Migration) error {
+	doc, err := m.Get(ctx, mig.Name, mig.Network)
+	if err == nil {
+		if !doc.Partial {
+			return fmt.Errorf("migration %s is already applied to %s", mig.Name, mig.Network)
+		}
+		_, err = m.coll.ReplaceOne(ctx, bson.M{
+			"name":    mig.Name,
+			"network": mig.Network,
+		}, mig)
+		if err != nil {
+			return fmt.Errorf("m.coll.ReplaceOne: %w", err)
+		}
+		return nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("m.Get: %w", err)
+	}
+
+	_, err = m.coll.InsertOne(ctx, mig)
+	if err != nil {
+		return fmt.Errorf("m.coll.InsertOne: %w", err)
+	}
+	return nil
+}
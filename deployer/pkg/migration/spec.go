@@ -0,0 +1,302 @@
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/contract"
+	"github.com/waves-exchange/contracts/deployer/pkg/datawriter"
+	"github.com/waves-exchange/contracts/deployer/pkg/fee"
+	"github.com/waves-exchange/contracts/deployer/pkg/manifest"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/tools"
+	"github.com/waves-exchange/contracts/deployer/pkg/txfields"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// Spec describes transactions of new migration, contracts are referenced by tag,
+// values may reference ${vars.name}, ${accounts.<tag>.address} and ${accounts.<tag>.publicKey}
+type Spec struct {
+	// Name is folder name after the date, e.g. user_pools_fix for 2023_04_25_user_pools_fix
+	Name    string            `yaml:"name"`
+	Network config.Network    `yaml:"network"`
+	Vars    map[string]string `yaml:"vars"`
+	Txs     []TxSpec          `yaml:"txs"`
+}
+
+// TxSpec is either data transaction of the contract or invoke of the contract
+type TxSpec struct {
+	// Name is file name after the index, e.g. set_fee for 00_set_fee.json,
+	// data too big for one transaction is written to set_fee_1.json, set_fee_2.json and so on
+	Name string `yaml:"name"`
+	// Tag is contract which data is set or which is invoked
+	Tag  string               `yaml:"tag"`
+	Data []manifest.DataEntry `yaml:"data"`
+	// Invoke caller is contract tag or base58 public key, dApp is ignored
+	Invoke *manifest.Invoke `yaml:"invoke"`
+	// Fee is estimated by the node if empty
+	Fee uint64 `yaml:"fee"`
+}
+
+// File is generated transaction with its file name
+type File struct {
+	Name string
+	Tx   proto.Transaction
+}
+
+var accountRefRegexp = regexp.MustCompile(`\$\{accounts\.([^.}]+)\.`)
+
+func LoadSpec(name string) (Spec, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return Spec{}, fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	s := Spec{Network: config.Mainnet}
+	err = yaml.Unmarshal(b, &s)
+	if err != nil {
+		return Spec{}, fmt.Errorf("yaml.Unmarshal: %w", err)
+	}
+
+	err = s.validate()
+	if err != nil {
+		return Spec{}, fmt.Errorf("s.validate: %w", err)
+	}
+	return s, nil
+}
+
+func (s Spec) validate() error {
+	if s.Name == "" {
+		return errors.New("name required")
+	}
+	if _, err := s.Network.Scheme(); err != nil {
+		return err
+	}
+	if len(s.Txs) == 0 {
+		return errors.New("txs required")
+	}
+	for i, tx := range s.Txs {
+		switch {
+		case tx.Name == "":
+			return fmt.Errorf("txs[%d]: name required", i)
+		case tx.Tag == "":
+			return fmt.Errorf("%s: tag required", tx.Name)
+		case (len(tx.Data) == 0) == (tx.Invoke == nil):
+			return fmt.Errorf("%s: either data or invoke required", tx.Name)
+		case tx.Invoke != nil && tx.Invoke.Caller == "":
+			return fmt.Errorf("%s: invoke caller required", tx.Name)
+		case tx.Invoke != nil && tx.Invoke.Function == "":
+			return fmt.Errorf("%s: invoke function required", tx.Name)
+		}
+	}
+	return nil
+}
+
+// Build resolves contracts by tag and returns validated transactions without timestamp and proofs,
+// timestamp is set when the folder is read into batch for signing
+func (s Spec) Build(ctx context.Context, contracts contract.Model, nd node.Node) ([]File, error) {
+	scheme, err := s.Network.Scheme()
+	if err != nil {
+		return nil, fmt.Errorf("s.Network.Scheme: %w", err)
+	}
+
+	accounts, err := s.accounts(ctx, contracts, scheme)
+	if err != nil {
+		return nil, fmt.Errorf("s.accounts: %w", err)
+	}
+	height, err := nd.Height(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("nd.Height: %w", err)
+	}
+	env := manifest.NewEnv(scheme, manifest.Manifest{Vars: s.Vars}, 0, height, tools.Timestamp(), accounts)
+
+	var res []File
+	for _, spec := range s.Txs {
+		txs, e := spec.build(ctx, env, accounts, scheme, nd)
+		if e != nil {
+			return nil, fmt.Errorf("%s: %w", spec.Name, e)
+		}
+
+		for i, tx := range txs {
+			name := spec.Name
+			if len(txs) > 1 {
+				name += fmt.Sprintf("_%d", i+1)
+			}
+			// files are read in the order of names
+			res = append(res, File{Name: fmt.Sprintf("%02d_%s.json", len(res), name), Tx: tx})
+		}
+	}
+	return res, nil
+}
+
+// accounts resolves contract tags and public keys used in the spec
+func (s Spec) accounts(
+	ctx context.Context,
+	contracts contract.Model,
+	scheme proto.Scheme,
+) (map[string]manifest.Account, error) {
+	names := map[string]bool{}
+	for _, tx := range s.Txs {
+		names[tx.Tag] = true
+		if tx.Invoke != nil {
+			names[tx.Invoke.Caller] = true
+		}
+
+		b, err := yaml.Marshal(tx)
+		if err != nil {
+			return nil, fmt.Errorf("yaml.Marshal: %w", err)
+		}
+		for _, m := range accountRefRegexp.FindAllStringSubmatch(string(b), -1) {
+			names[m[1]] = true
+		}
+	}
+
+	res := map[string]manifest.Account{}
+	for name := range names {
+		pub, err := crypto.NewPublicKeyFromBase58(name)
+		if err != nil {
+			c, e := contracts.GetByTag(ctx, name, nil)
+			if e != nil {
+				return nil, fmt.Errorf("contracts.GetByTag %s: %w", name, e)
+			}
+			pub, err = crypto.NewPublicKeyFromBase58(c.BasePub)
+			if err != nil {
+				return nil, fmt.Errorf("crypto.NewPublicKeyFromBase58: %w", err)
+			}
+		}
+
+		addr, err := proto.NewAddressFromPublicKey(scheme, pub)
+		if err != nil {
+			return nil, fmt.Errorf("proto.NewAddressFromPublicKey: %w", err)
+		}
+		res[name] = manifest.Account{Address: addr, PublicKey: pub}
+	}
+	return res, nil
+}
+
+func (t TxSpec) build(
+	ctx context.Context,
+	env *manifest.Env,
+	accounts map[string]manifest.Account,
+	scheme proto.Scheme,
+	nd node.Node,
+) ([]proto.Transaction, error) {
+	var txs []proto.Transaction
+	if t.Invoke != nil {
+		inv := *t.Invoke
+		inv.DApp = ""
+		invoke, err := env.InvokeTx(t.Tag, inv)
+		if err != nil {
+			return nil, fmt.Errorf("env.InvokeTx: %w", err)
+		}
+		invoke.Version = 2
+		if invoke.Payments == nil {
+			invoke.Payments = proto.ScriptPayments{}
+		}
+		// invoke fee may be set in the spec
+		if invoke.Fee == 0 {
+			err = fee.Set(ctx, nd, invoke)
+			if err != nil {
+				return nil, fmt.Errorf("fee.Set: %w", err)
+			}
+		}
+		txs = append(txs, invoke)
+	} else {
+		entries, err := env.DataEntries(t.Data)
+		if err != nil {
+			return nil, fmt.Errorf("env.DataEntries: %w", err)
+		}
+		data, err := datawriter.Pack(ctx, nd, accounts[t.Tag].PublicKey, entries)
+		if err != nil {
+			return nil, fmt.Errorf("datawriter.Pack: %w", err)
+		}
+		for _, tx := range data {
+			txs = append(txs, tx)
+		}
+	}
+
+	for _, tx := range txs {
+		if t.Fee != 0 {
+			err := txfields.SetFee(scheme, tx, t.Fee)
+			if err != nil {
+				return nil, fmt.Errorf("txfields.SetFee: %w", err)
+			}
+		}
+		_, err := tx.Validate(scheme)
+		if err != nil {
+			return nil, fmt.Errorf("tx.Validate: %w", err)
+		}
+		err = txfields.SetTimestamp(scheme, tx, 0)
+		if err != nil {
+			return nil, fmt.Errorf("txfields.SetTimestamp: %w", err)
+		}
+	}
+	return txs, nil
+}
+
+// Write creates migrations/<date>_<name> with transaction files and returns its path
+func (s Spec) Write(root string, date time.Time, files []File) (string, error) {
+	scheme, err := s.Network.Scheme()
+	if err != nil {
+		return "", fmt.Errorf("s.Network.Scheme: %w", err)
+	}
+
+	dir := filepath.Join(root, s.FolderName(date))
+	if _, e := os.Stat(dir); e == nil {
+		return "", fmt.Errorf("migration %s already exists", dir)
+	}
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return "", fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	for _, f := range files {
+		raw, e := json.Marshal(f.Tx)
+		if e != nil {
+			return "", fmt.Errorf("json.Marshal: %w", e)
+		}
+		// gowaves doesn't marshal chain id, it's checked when migration is read
+		var fields map[string]json.RawMessage
+		e = json.Unmarshal(raw, &fields)
+		if e != nil {
+			return "", fmt.Errorf("json.Unmarshal: %w", e)
+		}
+		fields["chainId"] = json.RawMessage(strconv.Itoa(int(scheme)))
+		b, e := json.MarshalIndent(fields, "", "  ")
+		if e != nil {
+			return "", fmt.Errorf("json.MarshalIndent: %w", e)
+		}
+
+		e = os.WriteFile(filepath.Join(dir, f.Name), append(b, '\n'), 0o644)
+		if e != nil {
+			return "", fmt.Errorf("os.WriteFile: %w", e)
+		}
+	}
+
+	// the same check as on plan and apply
+	folder, err := newFolder(root, filepath.Base(dir))
+	if err != nil {
+		return "", fmt.Errorf("newFolder: %w", err)
+	}
+	_, err = folder.Batch(tools.Timestamp())
+	if err != nil {
+		return "", fmt.Errorf("folder.Batch: %w", err)
+	}
+	return dir, nil
+}
+
+// FolderName is migration folder name, the date is in the format List orders by
+func (s Spec) FolderName(date time.Time) string {
+	return date.Format("2006_01_02") + "_" + strings.TrimSpace(s.Name)
+}
@@ -0,0 +1,117 @@
+package migration
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/batch"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/statediff"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Check is result of comparing one transaction of migration with on-chain state
+type Check struct {
+	File    string
+	Address string
+	// Subject is data key, 'script' or 'tx <id>'
+	Subject  string
+	Expected string
+	Actual   string
+	OK       bool
+}
+
+// Verify compares state set by migration transactions with the chain:
+// data entries and scripts of the senders, other transactions must be on chain by recorded ids.
+// Data and scripts may be changed by later migrations, so mismatch doesn't always mean migration failed
+func Verify(ctx context.Context, nd node.Node, b *batch.Batch, applied Migration) ([]Check, error) {
+	var res []Check
+	for i, item := range b.Items {
+		tx, err := item.Transaction()
+		if err != nil {
+			return nil, fmt.Errorf("item.Transaction: %w", err)
+		}
+
+		addr, err := proto.NewAddressFromString(item.Address)
+		if err != nil {
+			return nil, fmt.Errorf("proto.NewAddressFromString: %w", err)
+		}
+
+		switch t := tx.(type) {
+		case *proto.DataWithProofs:
+			for _, e := range t.Entries {
+				actual, er := nd.DataKey(ctx, addr, e.GetKey())
+				if er != nil {
+					return nil, fmt.Errorf("nd.DataKey: %w", er)
+				}
+
+				var expected proto.DataEntry
+				if e.GetValueType() != proto.DataDelete {
+					expected = e
+				}
+				res = append(res, Check{
+					File:     item.File,
+					Address:  item.Address,
+					Subject:  e.GetKey(),
+					Expected: statediff.Value(expected),
+					Actual:   statediff.Value(actual),
+					OK:       reflect.DeepEqual(expected, actual),
+				})
+			}
+		case *proto.SetScriptWithProofs:
+			actual, er := nd.Script(ctx, addr)
+			if er != nil {
+				return nil, fmt.Errorf("nd.Script: %w", er)
+			}
+
+			expected := ""
+			if len(t.Script) > 0 {
+				expected = "base64:" + base64.StdEncoding.EncodeToString(t.Script)
+			}
+			res = append(res, Check{
+				File:     item.File,
+				Address:  item.Address,
+				Subject:  "script",
+				Expected: shorten(expected),
+				Actual:   shorten(actual),
+				OK:       expected == actual,
+			})
+		default:
+			// timestamp of transactions without it in the file is set on apply, so id is taken from the record
+			id := item.ID
+			if i < len(applied.TxIDs) {
+				id = applied.TxIDs[i]
+			}
+			digest, er := crypto.NewDigestFromBase58(id)
+			if er != nil {
+				return nil, fmt.Errorf("crypto.NewDigestFromBase58: %w", er)
+			}
+
+			check := Check{
+				File:     item.File,
+				Address:  item.Address,
+				Subject:  "tx " + id,
+				Expected: "on chain",
+				Actual:   "on chain",
+				OK:       true,
+			}
+			if _, er = nd.TransactionInfo(ctx, digest); er != nil {
+				check.Actual = "not found"
+				check.OK = false
+			}
+			res = append(res, check)
+		}
+	}
+	return res, nil
+}
+
+func shorten(script string) string {
+	const maxLen = 40
+	if len(script) <= maxLen {
+		return script
+	}
+	return script[:maxLen] + "..."
+}
@@ -0,0 +1,369 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/transport"
+	"github.com/wavesplatform/gowaves/pkg/client"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Client is Node implementation on top of gowaves HTTP client,
+// requests are rate limited, retried and sent to the next node if the current one fails
+type Client struct {
+	raw       *client.Client
+	scheme    proto.Scheme
+	transport *transport.Transport
+}
+
+// NewClient uses default transport options, baseURL may be comma separated list of nodes
+func NewClient(baseURL string, scheme proto.Scheme) (*Client, error) {
+	return NewClientWithOptions(scheme, transport.DefaultOptions(strings.Split(baseURL, ",")...))
+}
+
+func NewClientWithOptions(scheme proto.Scheme, opts transport.Options) (*Client, error) {
+	tr, err := transport.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("transport.New: %w", err)
+	}
+
+	cl, err := client.NewClient(
+		client.Options{BaseUrl: tr.BaseURL(), Client: &http.Client{Transport: tr}, ChainID: scheme},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("client.NewClient: %w", err)
+	}
+
+	return &Client{
+		raw:       cl,
+		scheme:    scheme,
+		transport: tr,
+	}, nil
+}
+
+// BaseURL is the first node, requests are sent to the current one
+func (c *Client) BaseURL() string {
+	return c.raw.GetOptions().BaseUrl
+}
+
+func (c *Client) Scheme() proto.Scheme {
+	return c.scheme
+}
+
+// Metrics are request counters by endpoint
+func (c *Client) Metrics() *transport.Metrics {
+	return c.transport.Metrics()
+}
+
+// Broadcast succeeds if the transaction with the same id is already on chain or in the pool,
+// e.g. the previous attempt was applied but its response was lost
+func (c *Client) Broadcast(ctx context.Context, tx proto.Transaction) error {
+	_, err := c.raw.Transactions.Broadcast(ctx, tx)
+	if err == nil {
+		return nil
+	}
+
+	b, e := tx.GetID(c.scheme)
+	if e != nil {
+		return fmt.Errorf("c.raw.Transactions.Broadcast: %w", err)
+	}
+	id, e := crypto.NewDigestFromBytes(b)
+	if e != nil {
+		return fmt.Errorf("c.raw.Transactions.Broadcast: %w", err)
+	}
+	if _, e = c.TransactionInfo(ctx, id); e == nil {
+		return nil
+	}
+	if ok, e := c.InUnconfirmed(ctx, id); e == nil && ok {
+		return nil
+	}
+	return fmt.Errorf("c.raw.Transactions.Broadcast: %w", err)
+}
+
+func (c *Client) TransactionInfo(ctx context.Context, id crypto.Digest) (client.TransactionInfo, error) {
+	info, _, err := c.raw.Transactions.Info(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("c.raw.Transactions.Info: %w", err)
+	}
+	return info, nil
+}
+
+func (c *Client) InUnconfirmed(ctx context.Context, id crypto.Digest) (bool, error) {
+	_, res, err := c.raw.Transactions.UnconfirmedInfo(ctx, id)
+	if err != nil {
+		if res != nil && res.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("c.raw.Transactions.UnconfirmedInfo: %w", err)
+	}
+	return true, nil
+}
+
+func (c *Client) Script(ctx context.Context, addr proto.WavesAddress) (string, error) {
+	type withScript struct {
+		Script *string `json:"script"`
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodGet,
+		c.BaseURL()+"/addresses/scriptInfo/"+addr.String(),
+		nil,
+	)
+	if err != nil {
+		return "", fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+
+	buf := new(bytes.Buffer)
+	_, err = c.raw.Do(ctx, req, buf)
+	if err != nil {
+		return "", fmt.Errorf("c.raw.Do: %w", err)
+	}
+
+	var info withScript
+	err = json.Unmarshal(buf.Bytes(), &info)
+	if err != nil {
+		return "", fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	if info.Script == nil {
+		return "", nil
+	}
+
+	return *info.Script, nil
+}
+
+func (c *Client) DataKey(ctx context.Context, addr proto.WavesAddress, key string) (proto.DataEntry, error) {
+	data, _, err := c.raw.Addresses.AddressesDataKey(ctx, addr, key)
+	if err != nil {
+		if strings.Contains(err.Error(), "no data for this key") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("c.raw.Addresses.AddressesDataKey: %w", err)
+	}
+	return data, nil
+}
+
+func (c *Client) Data(ctx context.Context, addr proto.WavesAddress) (proto.DataEntries, error) {
+	data, _, err := c.raw.Addresses.AddressesData(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("c.raw.Addresses.AddressesData: %w", err)
+	}
+	return data, nil
+}
+
+func (c *Client) Balance(ctx context.Context, addr proto.WavesAddress) (uint64, error) {
+	bal, _, err := c.raw.Addresses.Balance(ctx, addr)
+	if err != nil {
+		return 0, fmt.Errorf("c.raw.Addresses.Balance: %w", err)
+	}
+	return bal.Balance, nil
+}
+
+func (c *Client) Height(ctx context.Context) (uint64, error) {
+	h, _, err := c.raw.Blocks.Height(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("c.raw.Blocks.Height: %w", err)
+	}
+	return h.Height, nil
+}
+
+func (c *Client) Version(ctx context.Context) (string, error) {
+	v, _, err := c.raw.NodeInfo.Version(ctx)
+	if err != nil {
+		return "", fmt.Errorf("c.raw.NodeInfo.Version: %w", err)
+	}
+	return v, nil
+}
+
+func (c *Client) Compile(ctx context.Context, body []byte, compact bool) (CompileResult, error) {
+	u := fmt.Sprintf("%s/utils/script/compileCode?compact=%s", c.BaseURL(), strconv.FormatBool(compact))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return CompileResult{}, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "text/plain")
+
+	var compileResult CompileResult
+	_, err = c.raw.Do(ctx, req, &compileResult)
+	if err != nil {
+		return CompileResult{}, fmt.Errorf("c.raw.Do: %w", err)
+	}
+
+	return compileResult, nil
+}
+
+func (c *Client) Decompile(ctx context.Context, base64Script string) (string, error) {
+	u := fmt.Sprintf("%s/utils/script/decompile", c.BaseURL())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(base64Script))
+	if err != nil {
+		return "", fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "text/plain")
+
+	type DecompileResult struct {
+		Script string
+	}
+
+	var decompileResult DecompileResult
+	_, err = c.raw.Do(ctx, req, &decompileResult)
+	if err != nil {
+		return "", fmt.Errorf("c.raw.Do: %w", err)
+	}
+
+	return decompileResult.Script, nil
+}
+
+func (c *Client) Evaluate(ctx context.Context, addr proto.WavesAddress, expr string, v interface{}) error {
+	type eval struct {
+		Expr string `json:"expr"`
+	}
+	return c.evaluate(ctx, addr, eval{Expr: expr}, v)
+}
+
+func (c *Client) EvaluateInvoke(ctx context.Context, tx *proto.InvokeScriptWithProofs) (Evaluation, error) {
+	type invoke struct {
+		Call            *proto.FunctionCall  `json:"call"`
+		SenderPublicKey string               `json:"senderPublicKey"`
+		Payment         proto.ScriptPayments `json:"payment"`
+		Fee             uint64               `json:"fee"`
+		FeeAssetID      proto.OptionalAsset  `json:"feeAssetId"`
+	}
+
+	dApp := tx.ScriptRecipient.Address()
+	if dApp == nil {
+		return Evaluation{}, errors.New("invoke by alias is not supported: " + tx.ScriptRecipient.String())
+	}
+
+	payment := tx.Payments
+	if payment == nil {
+		payment = proto.ScriptPayments{}
+	}
+
+	var res Evaluation
+	err := c.evaluate(ctx, *dApp, invoke{
+		Call:            &tx.FunctionCall,
+		SenderPublicKey: tx.SenderPK.String(),
+		Payment:         payment,
+		Fee:             tx.Fee,
+		FeeAssetID:      tx.FeeAsset,
+	}, &res)
+	if err != nil {
+		return Evaluation{}, err
+	}
+	return res, nil
+}
+
+func (c *Client) evaluate(ctx context.Context, addr proto.WavesAddress, body interface{}, v interface{}) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/utils/script/evaluate/%s", c.BaseURL(), addr),
+		bytes.NewReader(b),
+	)
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+
+	_, err = c.raw.Do(ctx, req, v)
+	if err != nil {
+		return fmt.Errorf("c.raw.Do: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) AssetDetails(ctx context.Context, id crypto.Digest) (*client.AssetsDetail, error) {
+	details, _, err := c.raw.Assets.Details(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("c.raw.Assets.Details: %w", err)
+	}
+	return details, nil
+}
+
+func (c *Client) CalculateFee(ctx context.Context, tx proto.Transaction) (uint64, error) {
+	raw, err := json.Marshal(tx)
+	if err != nil {
+		return 0, fmt.Errorf("json.Marshal: %w", err)
+	}
+	// gowaves doesn't marshal chain id, the node needs it to parse the transaction
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(raw, &fields)
+	if err != nil {
+		return 0, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	fields["chainId"] = json.RawMessage(strconv.Itoa(int(c.scheme)))
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return 0, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		c.BaseURL()+"/transactions/calculateFee",
+		bytes.NewReader(b),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+
+	var res struct {
+		FeeAssetID *string `json:"feeAssetId"`
+		FeeAmount  uint64  `json:"feeAmount"`
+	}
+	_, err = c.raw.Do(ctx, req, &res)
+	if err != nil {
+		return 0, fmt.Errorf("c.raw.Do: %w", err)
+	}
+	if res.FeeAssetID != nil {
+		return 0, errors.New("fee in asset is not supported: " + *res.FeeAssetID)
+	}
+	return res.FeeAmount, nil
+}
+
+func (c *Client) AddressTransactions(
+	ctx context.Context,
+	addr proto.WavesAddress,
+	limit int,
+	after *crypto.Digest,
+) ([]proto.Transaction, error) {
+	u := fmt.Sprintf("%s/transactions/address/%s/limit/%d", c.BaseURL(), addr, limit)
+	if after != nil {
+		u += "?after=" + after.String()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+
+	var pages []client.TransactionsField
+	_, err = c.raw.Do(ctx, req, &pages)
+	if err != nil {
+		return nil, fmt.Errorf("c.raw.Do: %w", err)
+	}
+	if len(pages) == 0 {
+		return nil, nil
+	}
+	return pages[0], nil
+}
+
+// compile-time check
+var _ Node = (*Client)(nil)
@@ -0,0 +1,460 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/wavesplatform/gowaves/pkg/client"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Fake is in-memory Node, it records broadcasted transactions and applies them to its state,
+// so deployer logic can be run without a live Waves node.
+// Every broadcasted transaction is mined immediately in a new block.
+type Fake struct {
+	mu          *sync.Mutex
+	scheme      proto.Scheme
+	height      uint64
+	broadcasts  []proto.Transaction
+	txs         map[crypto.Digest]*fakeTxInfo
+	scripts     map[proto.WavesAddress]string
+	data        map[proto.WavesAddress]map[string]proto.DataEntry
+	balances    map[proto.WavesAddress]uint64
+	assets      map[crypto.Digest]*client.AssetsDetail
+	evaluations map[string]json.RawMessage
+	invokes     map[string]Evaluation
+
+	// BroadcastHook is called before transaction is applied, non-nil error rejects the transaction
+	BroadcastHook func(tx proto.Transaction) error
+}
+
+func NewFake(scheme proto.Scheme) *Fake {
+	return &Fake{
+		mu:          &sync.Mutex{},
+		scheme:      scheme,
+		height:      1,
+		txs:         map[crypto.Digest]*fakeTxInfo{},
+		scripts:     map[proto.WavesAddress]string{},
+		data:        map[proto.WavesAddress]map[string]proto.DataEntry{},
+		balances:    map[proto.WavesAddress]uint64{},
+		assets:      map[crypto.Digest]*client.AssetsDetail{},
+		evaluations: map[string]json.RawMessage{},
+		invokes:     map[string]Evaluation{},
+	}
+}
+
+type fakeTxInfo struct {
+	proto.Transaction
+	height proto.Height
+}
+
+func (i *fakeTxInfo) GetSpentComplexity() int {
+	return 0
+}
+
+func (i *fakeTxInfo) GetHeight() proto.Height {
+	return i.height
+}
+
+// FakeAccount is account of tests, its keys are derived from the seed, so the same seed is the same account
+type FakeAccount struct {
+	SecretKey crypto.SecretKey
+	PublicKey crypto.PublicKey
+	Address   proto.WavesAddress
+}
+
+// NewFakeAccount panics on error, keys of any seed and address of any public key are valid
+func NewFakeAccount(scheme proto.Scheme, seed string) FakeAccount {
+	sk, pk, err := crypto.GenerateKeyPair([]byte(seed))
+	if err != nil {
+		panic(fmt.Errorf("crypto.GenerateKeyPair: %w", err))
+	}
+	addr, err := proto.NewAddressFromPublicKey(scheme, pk)
+	if err != nil {
+		panic(fmt.Errorf("proto.NewAddressFromPublicKey: %w", err))
+	}
+	return FakeAccount{SecretKey: sk, PublicKey: pk, Address: addr}
+}
+
+func evaluationKey(addr proto.WavesAddress, expr string) string {
+	return addr.String() + " " + expr
+}
+
+func (f *Fake) SetBalance(addr proto.WavesAddress, balance uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.balances[addr] = balance
+}
+
+func (f *Fake) SetScript(addr proto.WavesAddress, base64Script string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scripts[addr] = base64Script
+}
+
+func (f *Fake) SetData(addr proto.WavesAddress, entries ...proto.DataEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.applyData(addr, entries)
+}
+
+func (f *Fake) SetAsset(details *client.AssetsDetail) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.assets[details.AssetId] = details
+}
+
+// SetEvaluation sets raw node response returned by Evaluate for the address and expression
+func (f *Fake) SetEvaluation(addr proto.WavesAddress, expr string, response json.RawMessage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.evaluations[evaluationKey(addr, expr)] = response
+}
+
+// SetInvokeEvaluation sets result of EvaluateInvoke for the dApp function,
+// invokes without result are evaluated successfully without state changes
+func (f *Fake) SetInvokeEvaluation(dApp proto.WavesAddress, function string, ev Evaluation) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.invokes[evaluationKey(dApp, function)] = ev
+}
+
+// Broadcasts returns all accepted transactions in broadcast order
+func (f *Fake) Broadcasts() []proto.Transaction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := make([]proto.Transaction, len(f.broadcasts))
+	copy(res, f.broadcasts)
+	return res
+}
+
+func (f *Fake) Scheme() proto.Scheme {
+	return f.scheme
+}
+
+func (f *Fake) Broadcast(_ context.Context, tx proto.Transaction) error {
+	// the hook is called without the lock, so it may use the fake
+	if f.BroadcastHook != nil {
+		err := f.BroadcastHook(tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, err := tx.Validate(f.scheme)
+	if err != nil {
+		return fmt.Errorf("tx.Validate: %w", err)
+	}
+
+	idBytes, err := tx.GetID(f.scheme)
+	if err != nil {
+		return fmt.Errorf("tx.GetID: %w", err)
+	}
+	id, err := crypto.NewDigestFromBytes(idBytes)
+	if err != nil {
+		return fmt.Errorf("crypto.NewDigestFromBytes: %w", err)
+	}
+	if _, ok := f.txs[id]; ok {
+		return errors.New("transaction is already in the state: " + id.String())
+	}
+
+	sender, err := tx.GetSender(f.scheme)
+	if err != nil {
+		return fmt.Errorf("tx.GetSender: %w", err)
+	}
+	senderAddr, err := sender.ToWavesAddress(f.scheme)
+	if err != nil {
+		return fmt.Errorf("sender.ToWavesAddress: %w", err)
+	}
+
+	if f.balances[senderAddr] < tx.GetFee() {
+		return fmt.Errorf("insufficient balance to pay fee: address %s", senderAddr)
+	}
+	f.balances[senderAddr] -= tx.GetFee()
+
+	switch t := tx.(type) {
+	case *proto.TransferWithProofs:
+		if t.AmountAsset.Present {
+			break
+		}
+		if f.balances[senderAddr] < t.Amount {
+			f.balances[senderAddr] += tx.GetFee()
+			return fmt.Errorf("insufficient balance to transfer: address %s", senderAddr)
+		}
+		if t.Recipient.Address() == nil {
+			f.balances[senderAddr] += tx.GetFee()
+			return errors.New("aliases are not supported")
+		}
+		f.balances[senderAddr] -= t.Amount
+		f.balances[*t.Recipient.Address()] += t.Amount
+	case *proto.DataWithProofs:
+		f.applyData(senderAddr, t.Entries)
+	case *proto.SetScriptWithProofs:
+		if len(t.Script) == 0 {
+			delete(f.scripts, senderAddr)
+		} else {
+			f.scripts[senderAddr] = "base64:" + base64.StdEncoding.EncodeToString(t.Script)
+		}
+	case *proto.IssueWithProofs:
+		f.assets[id] = &client.AssetsDetail{
+			AssetId:        id,
+			IssueHeight:    f.height + 1,
+			IssueTimestamp: t.Timestamp,
+			Issuer:         senderAddr,
+			Name:           t.Name,
+			Description:    t.Description,
+			Decimals:       uint64(t.Decimals),
+			Reissuable:     t.Reissuable,
+			Quantity:       t.Quantity,
+		}
+	}
+
+	f.height += 1
+	f.broadcasts = append(f.broadcasts, tx)
+	f.txs[id] = &fakeTxInfo{Transaction: tx, height: f.height}
+	return nil
+}
+
+func (f *Fake) applyData(addr proto.WavesAddress, entries proto.DataEntries) {
+	state, ok := f.data[addr]
+	if !ok {
+		state = map[string]proto.DataEntry{}
+		f.data[addr] = state
+	}
+	for _, e := range entries {
+		if e.GetValueType() == proto.DataDelete {
+			delete(state, e.GetKey())
+			continue
+		}
+		state[e.GetKey()] = e
+	}
+}
+
+func (f *Fake) TransactionInfo(_ context.Context, id crypto.Digest) (client.TransactionInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, ok := f.txs[id]
+	if !ok {
+		return nil, errors.New("transactions does not exist: " + id.String())
+	}
+	return info, nil
+}
+
+// InUnconfirmed is always false, broadcasted transactions are mined immediately
+func (f *Fake) InUnconfirmed(_ context.Context, _ crypto.Digest) (bool, error) {
+	return false, nil
+}
+
+func (f *Fake) Script(_ context.Context, addr proto.WavesAddress) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.scripts[addr], nil
+}
+
+func (f *Fake) DataKey(_ context.Context, addr proto.WavesAddress, key string) (proto.DataEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.data[addr][key], nil
+}
+
+func (f *Fake) Data(_ context.Context, addr proto.WavesAddress) (proto.DataEntries, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var res proto.DataEntries
+	for _, e := range f.data[addr] {
+		res = append(res, e)
+	}
+	return res, nil
+}
+
+func (f *Fake) Balance(_ context.Context, addr proto.WavesAddress) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.balances[addr], nil
+}
+
+func (f *Fake) Height(_ context.Context) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.height, nil
+}
+
+func (f *Fake) Version(_ context.Context) (string, error) {
+	return "fake", nil
+}
+
+// Compile doesn't compile anything, script bytes are compact flag followed by the source,
+// it's enough to compare scripts and decompile them back
+func (f *Fake) Compile(_ context.Context, body []byte, compact bool) (CompileResult, error) {
+	flag := byte(0)
+	if compact {
+		flag = 1
+	}
+	script := append([]byte{flag}, body...)
+	return CompileResult{
+		Script:               "base64:" + base64.StdEncoding.EncodeToString(script),
+		CallableComplexities: map[string]int{},
+	}, nil
+}
+
+func (f *Fake) Decompile(_ context.Context, base64Script string) (string, error) {
+	script, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(base64Script, "base64:"))
+	if err != nil {
+		return "", fmt.Errorf("base64.StdEncoding.DecodeString: %w", err)
+	}
+	if len(script) == 0 {
+		return "", nil
+	}
+	return string(script[1:]), nil
+}
+
+func (f *Fake) Evaluate(_ context.Context, addr proto.WavesAddress, expr string, v interface{}) error {
+	f.mu.Lock()
+	res, ok := f.evaluations[evaluationKey(addr, expr)]
+	f.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no evaluation result: address %s expr %s", addr, expr)
+	}
+
+	err := json.Unmarshal(res, v)
+	if err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return nil
+}
+
+func (f *Fake) EvaluateInvoke(_ context.Context, tx *proto.InvokeScriptWithProofs) (Evaluation, error) {
+	dApp := tx.ScriptRecipient.Address()
+	if dApp == nil {
+		return Evaluation{}, errors.New("aliases are not supported")
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.invokes[evaluationKey(*dApp, tx.FunctionCall.Name())], nil
+}
+
+// CalculateFee follows node fee rules: minimal fee by type, per started kilobyte for data and setScript,
+// plus 0.004 WAVES if the sender has a script, assets of the fake have no scripts
+func (f *Fake) CalculateFee(_ context.Context, tx proto.Transaction) (uint64, error) {
+	const (
+		minFee        = 100000
+		invokeFee     = 500000
+		issueFee      = 100000000
+		smartExtraFee = 400000
+	)
+
+	var fee uint64
+	switch t := tx.(type) {
+	case *proto.DataWithProofs, *proto.SetScriptWithProofs:
+		body, err := proto.MarshalTxBody(f.scheme, tx)
+		if err != nil {
+			return 0, fmt.Errorf("proto.MarshalTxBody: %w", err)
+		}
+		fee = minFee * uint64((len(body)+1023)/1024)
+	case *proto.InvokeScriptWithProofs:
+		fee = invokeFee
+	case *proto.IssueWithProofs:
+		fee = issueFee
+		if t.Quantity == 1 && t.Decimals == 0 && !t.Reissuable {
+			fee = minFee
+		}
+	case *proto.SponsorshipWithProofs, *proto.SetAssetScriptWithProofs:
+		fee = issueFee
+	default:
+		fee = minFee
+	}
+
+	sender, err := tx.GetSender(f.scheme)
+	if err != nil {
+		return 0, fmt.Errorf("tx.GetSender: %w", err)
+	}
+	senderAddr, err := sender.ToWavesAddress(f.scheme)
+	if err != nil {
+		return 0, fmt.Errorf("sender.ToWavesAddress: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.scripts[senderAddr] != "" {
+		fee += smartExtraFee
+	}
+	return fee, nil
+}
+
+func (f *Fake) AddressTransactions(
+	_ context.Context,
+	addr proto.WavesAddress,
+	limit int,
+	after *crypto.Digest,
+) ([]proto.Transaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var res []proto.Transaction
+	skip := after != nil
+	for i := len(f.broadcasts) - 1; i >= 0 && len(res) < limit; i-- {
+		tx := f.broadcasts[i]
+		if skip {
+			idBytes, err := tx.GetID(f.scheme)
+			if err != nil {
+				return nil, fmt.Errorf("tx.GetID: %w", err)
+			}
+			skip = !bytes.Equal(idBytes, after.Bytes())
+			continue
+		}
+
+		related, err := f.related(tx, addr)
+		if err != nil {
+			return nil, err
+		}
+		if related {
+			res = append(res, tx)
+		}
+	}
+	return res, nil
+}
+
+// related is true if the address sends, receives or is invoked by the transaction
+func (f *Fake) related(tx proto.Transaction, addr proto.WavesAddress) (bool, error) {
+	sender, err := tx.GetSender(f.scheme)
+	if err != nil {
+		return false, fmt.Errorf("tx.GetSender: %w", err)
+	}
+	senderAddr, err := sender.ToWavesAddress(f.scheme)
+	if err != nil {
+		return false, fmt.Errorf("sender.ToWavesAddress: %w", err)
+	}
+
+	var recipient *proto.WavesAddress
+	switch t := tx.(type) {
+	case *proto.TransferWithProofs:
+		recipient = t.Recipient.Address()
+	case *proto.InvokeScriptWithProofs:
+		recipient = t.ScriptRecipient.Address()
+	}
+	return senderAddr == addr || (recipient != nil && *recipient == addr), nil
+}
+
+func (f *Fake) AssetDetails(_ context.Context, id crypto.Digest) (*client.AssetsDetail, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	details, ok := f.assets[id]
+	if !ok {
+		return nil, errors.New("asset does not exist: " + id.String())
+	}
+	return details, nil
+}
+
+// compile-time check
+var _ Node = (*Fake)(nil)
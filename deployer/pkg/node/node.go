@@ -0,0 +1,56 @@
+package node
+
+import (
+	"context"
+
+	"github.com/wavesplatform/gowaves/pkg/client"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Node is the part of Waves node API deployer depends on
+type Node interface {
+	Scheme() proto.Scheme
+	Broadcast(ctx context.Context, tx proto.Transaction) error
+	TransactionInfo(ctx context.Context, id crypto.Digest) (client.TransactionInfo, error)
+	// InUnconfirmed reports whether the transaction is in the unconfirmed pool of the node
+	InUnconfirmed(ctx context.Context, id crypto.Digest) (bool, error)
+	// Script returns base64 script of the address or empty string if there is no script
+	Script(ctx context.Context, addr proto.WavesAddress) (string, error)
+	// DataKey returns nil entry if there is no data for the key
+	DataKey(ctx context.Context, addr proto.WavesAddress, key string) (proto.DataEntry, error)
+	Data(ctx context.Context, addr proto.WavesAddress) (proto.DataEntries, error)
+	Balance(ctx context.Context, addr proto.WavesAddress) (uint64, error)
+	Height(ctx context.Context) (uint64, error)
+	// Version returns node version, e.g. "Waves v1.4.17"
+	Version(ctx context.Context) (string, error)
+	Compile(ctx context.Context, body []byte, compact bool) (CompileResult, error)
+	Decompile(ctx context.Context, base64Script string) (string, error)
+	// Evaluate evaluates expr on the dApp and decodes node response to v
+	Evaluate(ctx context.Context, addr proto.WavesAddress, expr string, v interface{}) error
+	// EvaluateInvoke evaluates invoke as if tx is broadcast now, nothing is changed on chain
+	EvaluateInvoke(ctx context.Context, tx *proto.InvokeScriptWithProofs) (Evaluation, error)
+	AssetDetails(ctx context.Context, id crypto.Digest) (*client.AssetsDetail, error)
+	// CalculateFee returns minimal fee in WAVES of the transaction:
+	// by type and size, plus extra fee for scripted sender and smart assets
+	CalculateFee(ctx context.Context, tx proto.Transaction) (uint64, error)
+	// AddressTransactions returns up to limit transactions of the address, newest first,
+	// after is the last id of the previous page or nil for the first one
+	AddressTransactions(ctx context.Context, addr proto.WavesAddress, limit int, after *crypto.Digest) ([]proto.Transaction, error)
+}
+
+// Evaluation is node response of invoke evaluation, Error is non-zero if invoke fails
+type Evaluation struct {
+	Complexity   int                 `json:"complexity"`
+	StateChanges client.StateChanges `json:"stateChanges"`
+	Error        int                 `json:"error"`
+	Message      string              `json:"message"`
+}
+
+type CompileResult struct {
+	Script               string         `json:"script"`
+	Complexity           int            `json:"complexity"`
+	VerifierComplexity   int            `json:"verifierComplexity"`
+	CallableComplexities map[string]int `json:"callableComplexities"`
+	ExtraFee             int            `json:"extraFee"`
+}
@@ -0,0 +1,68 @@
+package rollback
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+var ErrNoPrevious = errors.New("no previous script")
+
+const pageSize = 100
+
+// PreviousScript returns the latest setScript transaction of addr with script different from the current one,
+// transactions of the address are read from the chain page by page, newest first
+func PreviousScript(ctx context.Context, nd node.Node, addr proto.WavesAddress) (*proto.SetScriptWithProofs, error) {
+	current, err := nd.Script(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("nd.Script: %w", err)
+	}
+
+	var after *crypto.Digest
+	for {
+		txs, e := nd.AddressTransactions(ctx, addr, pageSize, after)
+		if e != nil {
+			return nil, fmt.Errorf("nd.AddressTransactions: %w", e)
+		}
+
+		for _, tx := range txs {
+			setScript, ok := tx.(*proto.SetScriptWithProofs)
+			if !ok {
+				continue
+			}
+			sender, er := proto.NewAddressFromPublicKey(nd.Scheme(), setScript.SenderPK)
+			if er != nil {
+				return nil, fmt.Errorf("proto.NewAddressFromPublicKey: %w", er)
+			}
+			if sender == addr && Base64(setScript.Script) != current {
+				return setScript, nil
+			}
+		}
+
+		if len(txs) < pageSize {
+			return nil, ErrNoPrevious
+		}
+		idBytes, e := txs[len(txs)-1].GetID(nd.Scheme())
+		if e != nil {
+			return nil, fmt.Errorf("GetID: %w", e)
+		}
+		last, e := crypto.NewDigestFromBytes(idBytes)
+		if e != nil {
+			return nil, fmt.Errorf("crypto.NewDigestFromBytes: %w", e)
+		}
+		after = &last
+	}
+}
+
+// Base64 is script in the format of node script info, empty string if there is no script
+func Base64(script proto.Script) string {
+	if len(script) == 0 {
+		return ""
+	}
+	return "base64:" + base64.StdEncoding.EncodeToString(script)
+}
@@ -0,0 +1,89 @@
+package rollback
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+type fixture struct {
+	nd  *node.Fake
+	acc node.FakeAccount
+	ts  uint64
+}
+
+func newFixture() *fixture {
+	nd := node.NewFake(proto.TestNetScheme)
+	acc := node.NewFakeAccount(nd.Scheme(), "rollback")
+	nd.SetBalance(acc.Address, 1000_0000_0000)
+	return &fixture{nd: nd, acc: acc, ts: uint64(time.Now().UnixMilli())}
+}
+
+func (f *fixture) broadcast(t *testing.T, tx proto.Transaction) {
+	t.Helper()
+	err := tx.Sign(f.nd.Scheme(), f.acc.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.nd.Broadcast(context.Background(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f *fixture) setScript(t *testing.T, script proto.Script) {
+	t.Helper()
+	f.ts++
+	f.broadcast(t, proto.NewUnsignedSetScriptWithProofs(2, f.acc.PublicKey, script, 1000000, f.ts))
+}
+
+func (f *fixture) data(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		f.ts++
+		tx := proto.NewUnsignedDataWithProofs(2, f.acc.PublicKey, 500000, f.ts)
+		err := tx.AppendEntry(&proto.IntegerDataEntry{Key: "%s__key", Value: int64(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.broadcast(t, tx)
+	}
+}
+
+func TestPreviousScript(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	old, current := proto.Script{1, 1}, proto.Script{2, 2}
+
+	f.setScript(t, proto.Script{0, 0})
+	f.setScript(t, old)
+	f.setScript(t, current)
+	// current script set again isn't the previous one
+	f.setScript(t, current)
+	// the previous script is on the next page
+	f.data(t, pageSize+10)
+
+	tx, err := PreviousScript(ctx, f.nd, f.acc.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Base64(tx.Script) != Base64(old) {
+		t.Errorf("PreviousScript() = %s, want %s", Base64(tx.Script), Base64(old))
+	}
+}
+
+func TestPreviousScriptNone(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture()
+	f.setScript(t, proto.Script{1, 1})
+	f.data(t, pageSize)
+
+	_, err := PreviousScript(ctx, f.nd, f.acc.Address)
+	if !errors.Is(err, ErrNoPrevious) {
+		t.Errorf("PreviousScript() error = %v, want %v", err, ErrNoPrevious)
+	}
+}
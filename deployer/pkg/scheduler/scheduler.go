@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Task is run after all tasks it depends on have succeeded
+type Task struct {
+	Name      string
+	DependsOn []string
+	Run       func(ctx context.Context) error
+}
+
+// Run runs independent tasks concurrently, at most parallel at a time.
+// The first failed task cancels context of running tasks, tasks waiting for it are not started
+func Run(ctx context.Context, parallel int, tasks []Task) error {
+	if parallel < 1 {
+		return errors.New("parallel must be positive")
+	}
+
+	err := validate(tasks)
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	done := make(map[string]chan struct{}, len(tasks))
+	for _, t := range tasks {
+		done[t.Name] = make(chan struct{})
+	}
+
+	sem := make(chan struct{}, parallel)
+	eg, egCtx := errgroup.WithContext(ctx)
+	for _, t := range tasks {
+		t := t
+		eg.Go(func() error {
+			for _, dep := range t.DependsOn {
+				select {
+				case <-done[dep]:
+				case <-egCtx.Done():
+					return egCtx.Err()
+				}
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-egCtx.Done():
+				return egCtx.Err()
+			}
+			defer func() {
+				<-sem
+			}()
+
+			e := t.Run(egCtx)
+			if e != nil {
+				return fmt.Errorf("%s: %w", t.Name, e)
+			}
+			close(done[t.Name])
+			return nil
+		})
+	}
+
+	return eg.Wait()
+}
+
+// validate checks task names are unique, dependencies exist and there are no cycles
+func validate(tasks []Task) error {
+	deps := make(map[string][]string, len(tasks))
+	for _, t := range tasks {
+		if _, ok := deps[t.Name]; ok {
+			return fmt.Errorf("duplicated task: %s", t.Name)
+		}
+		deps[t.Name] = t.DependsOn
+	}
+
+	for _, t := range tasks {
+		for _, dep := range t.DependsOn {
+			if _, ok := deps[dep]; !ok {
+				return fmt.Errorf("task %s depends on unknown task %s", t.Name, dep)
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(tasks))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("dependency cycle at task %s", name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dep := range deps[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+	for _, t := range tasks {
+		if err := visit(t.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
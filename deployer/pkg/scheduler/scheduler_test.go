@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunOrder(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		finished = map[string]bool{}
+		running  int
+		peak     int
+	)
+	task := func(name string, deps ...string) Task {
+		return Task{Name: name, DependsOn: deps, Run: func(context.Context) error {
+			mu.Lock()
+			for _, d := range deps {
+				if !finished[d] {
+					t.Errorf("%s is started before %s", name, d)
+				}
+			}
+			running++
+			if running > peak {
+				peak = running
+			}
+			mu.Unlock()
+
+			// independent tasks overlap while this one runs
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+			running--
+			finished[name] = true
+			return nil
+		}}
+	}
+
+	err := Run(context.Background(), 2, []Task{
+		task("pool", "factory", "assets"),
+		task("factory"),
+		task("assets"),
+		task("staking", "factory"),
+		task("router", "pool", "staking"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(finished) != 5 {
+		t.Errorf("finished %d tasks, want 5", len(finished))
+	}
+	if peak > 2 {
+		t.Errorf("%d tasks run at once, parallel is 2", peak)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	failure := errors.New("broadcast failed")
+	var (
+		mu      sync.Mutex
+		started = map[string]bool{}
+	)
+	run := func(name string, err error) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			started[name] = true
+			return err
+		}
+	}
+
+	err := Run(context.Background(), 1, []Task{
+		{Name: "factory", Run: run("factory", failure)},
+		{Name: "pool", DependsOn: []string{"factory"}, Run: run("pool", nil)},
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("Run() error = %v, want %v", err, failure)
+	}
+	if started["pool"] {
+		t.Error("task is started after its dependency failed")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+	tests := []struct {
+		name  string
+		tasks []Task
+	}{
+		{"duplicated", []Task{{Name: "a", Run: noop}, {Name: "a", Run: noop}}},
+		{"unknown dependency", []Task{{Name: "a", DependsOn: []string{"b"}, Run: noop}}},
+		{"cycle", []Task{
+			{Name: "a", DependsOn: []string{"c"}, Run: noop},
+			{Name: "b", DependsOn: []string{"a"}, Run: noop},
+			{Name: "c", DependsOn: []string{"b"}, Run: noop},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Run(context.Background(), 1, tt.tasks); err == nil {
+				t.Error("invalid tasks are run")
+			}
+		})
+	}
+
+	if err := Run(context.Background(), 0, nil); err == nil {
+		t.Error("tasks are run with parallel 0")
+	}
+}
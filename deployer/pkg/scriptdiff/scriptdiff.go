@@ -0,0 +1,85 @@
+package scriptdiff
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+
+	"github.com/google/uuid"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+)
+
+func preparePathAndScript(ctx context.Context, nd node.Node, fileName, base64Script string) (string, error) {
+	var script string
+	if base64Script != "" {
+		scr, err := nd.Decompile(ctx, base64Script)
+		if err != nil {
+			return "", fmt.Errorf("nd.Decompile: %w", err)
+		}
+		script = scr
+	}
+
+	u, err := uuid.NewRandom()
+	if err != nil {
+		return "", fmt.Errorf("uuid.NewRandom: %w", err)
+	}
+
+	p := path.Join("tmp", fileName+" "+u.String())
+
+	f, err := os.Create(p)
+	if err != nil {
+		return "", fmt.Errorf("os.Create: %w", err)
+	}
+
+	_, err = f.Write([]byte(script))
+	if err != nil {
+		return "", fmt.Errorf("file1.Write: %w", err)
+	}
+
+	return p, nil
+}
+
+// Print prints colored diff of decompiled scripts and returns it without colors
+func Print(ctx context.Context, nd node.Node, fileName, base64Str1, base64Str2 string) (string, error) {
+	path1, err := preparePathAndScript(ctx, nd, fileName, base64Str1)
+	defer func() {
+		_ = os.Remove(path1)
+	}()
+	if err != nil {
+		return "", fmt.Errorf("preparePathAndScript: %w", err)
+	}
+
+	path2, err := preparePathAndScript(ctx, nd, fileName, base64Str2)
+	defer func() {
+		_ = os.Remove(path2)
+	}()
+	if err != nil {
+		return "", fmt.Errorf("preparePathAndScript: %w", err)
+	}
+
+	cmd := exec.Command(
+		"git",
+		"--no-pager",
+		"diff",
+		"--color",
+		"--no-index",
+		path1,
+		path2,
+	)
+	fmt.Println(cmd.String())
+	stdout, _ := cmd.Output()
+
+	fmt.Println(string(stdout))
+
+	plain, _ := exec.Command(
+		"git",
+		"--no-pager",
+		"diff",
+		"--no-index",
+		path1,
+		path2,
+	).Output()
+	return string(plain), nil
+}
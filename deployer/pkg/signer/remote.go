@@ -0,0 +1,244 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+)
+
+// Remote signer API:
+// GET  <url>/publicKeys -> {"publicKeys": ["<base58>"]}
+// POST <url>/sign {"publicKey": "<base58>", "body": "<base64>"} -> {"signature": "<base58>"}
+// requests carry 'Authorization: Bearer <token>' if token is set
+
+type signRequest struct {
+	PublicKey string `json:"publicKey"`
+	Body      string `json:"body"`
+}
+
+type signResponse struct {
+	Signature string `json:"signature"`
+}
+
+type publicKeysResponse struct {
+	PublicKeys []string `json:"publicKeys"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// remote asks signing service to sign with the key it keeps
+type remote struct {
+	url    string
+	token  string
+	pub    crypto.PublicKey
+	client *http.Client
+}
+
+func NewRemote(url, token string, pub crypto.PublicKey) Signer {
+	return remote{
+		url:    strings.TrimSuffix(url, "/"),
+		token:  token,
+		pub:    pub,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (r remote) PublicKey() crypto.PublicKey {
+	return r.pub
+}
+
+// Sign checks the signature, so a misconfigured service can't put invalid proof
+func (r remote) Sign(ctx context.Context, body []byte) (crypto.Signature, error) {
+	var res signResponse
+	err := call(ctx, r.client, http.MethodPost, r.url+"/sign", r.token, signRequest{
+		PublicKey: r.pub.String(),
+		Body:      base64.StdEncoding.EncodeToString(body),
+	}, &res)
+	if err != nil {
+		return crypto.Signature{}, err
+	}
+
+	sig, err := crypto.NewSignatureFromBase58(res.Signature)
+	if err != nil {
+		return crypto.Signature{}, fmt.Errorf("crypto.NewSignatureFromBase58: %w", err)
+	}
+	if !crypto.Verify(r.pub, sig, body) {
+		return crypto.Signature{}, fmt.Errorf("remote signer returned invalid signature of %s", r.pub.String())
+	}
+	return sig, nil
+}
+
+// RemotePublicKeys returns keys the signing service signs with
+func RemotePublicKeys(ctx context.Context, url, token string) ([]crypto.PublicKey, error) {
+	var res publicKeysResponse
+	err := call(ctx, &http.Client{Timeout: 30 * time.Second}, http.MethodGet,
+		strings.TrimSuffix(url, "/")+"/publicKeys", token, nil, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]crypto.PublicKey, 0, len(res.PublicKeys))
+	for _, k := range res.PublicKeys {
+		pub, e := crypto.NewPublicKeyFromBase58(k)
+		if e != nil {
+			return nil, fmt.Errorf("crypto.NewPublicKeyFromBase58: %w", e)
+		}
+		keys = append(keys, pub)
+	}
+	return keys, nil
+}
+
+// NewRemoteSigners returns remote signers of all keys of the signing service
+func NewRemoteSigners(ctx context.Context, url, token string) ([]Signer, error) {
+	keys, err := RemotePublicKeys(ctx, url, token)
+	if err != nil {
+		return nil, fmt.Errorf("RemotePublicKeys: %w", err)
+	}
+
+	res := make([]Signer, 0, len(keys))
+	for _, pub := range keys {
+		res = append(res, NewRemote(url, token, pub))
+	}
+	return res, nil
+}
+
+func call(ctx context.Context, client *http.Client, method, url, token string, req, res interface{}) error {
+	var body io.Reader
+	if req != nil {
+		b, err := json.Marshal(req)
+		if err != nil {
+			return fmt.Errorf("json.Marshal: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	httpRes, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("client.Do: %w", err)
+	}
+	defer func() {
+		_ = httpRes.Body.Close()
+	}()
+
+	b, err := io.ReadAll(httpRes.Body)
+	if err != nil {
+		return fmt.Errorf("io.ReadAll: %w", err)
+	}
+	if httpRes.StatusCode != http.StatusOK {
+		var e errorResponse
+		if json.Unmarshal(b, &e) == nil && e.Error != "" {
+			return fmt.Errorf("remote signer %s: %s", httpRes.Status, e.Error)
+		}
+		return fmt.Errorf("remote signer %s: %s", httpRes.Status, string(b))
+	}
+
+	err = json.Unmarshal(b, res)
+	if err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+	return nil
+}
+
+// handler serves remote signer API with local signers, it's a stand-in of signing service for local runs
+type handler struct {
+	token   string
+	signers map[crypto.PublicKey]Signer
+	keys    []string
+}
+
+func NewHandler(token string, signers ...Signer) http.Handler {
+	h := handler{
+		token:   token,
+		signers: map[crypto.PublicKey]Signer{},
+	}
+	for _, s := range signers {
+		h.signers[s.PublicKey()] = s
+		h.keys = append(h.keys, s.PublicKey().String())
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/publicKeys", h.publicKeys)
+	mux.HandleFunc("/sign", h.sign)
+	return h.auth(mux)
+}
+
+func (h handler) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := []byte("Bearer " + h.token)
+		if h.token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "invalid token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h handler) publicKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "GET expected"})
+		return
+	}
+	writeJSON(w, http.StatusOK, publicKeysResponse{PublicKeys: h.keys})
+}
+
+func (h handler) sign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "POST expected"})
+		return
+	}
+
+	var req signRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	pub, err := crypto.NewPublicKeyFromBase58(req.PublicKey)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	body, err := base64.StdEncoding.DecodeString(req.Body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	s, ok := h.signers[pub]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "unknown public key " + req.PublicKey})
+		return
+	}
+	sig, err := s.Sign(r.Context(), body)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, signResponse{Signature: sig.String()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,97 @@
+package signer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// impostor signs with another key than it reports
+type impostor struct {
+	Signer
+	pub crypto.PublicKey
+}
+
+func (i impostor) PublicKey() crypto.PublicKey {
+	return i.pub
+}
+
+func TestRemote(t *testing.T) {
+	ctx := context.Background()
+	alice := node.NewFakeAccount(proto.TestNetScheme, "alice")
+	bob := node.NewFakeAccount(proto.TestNetScheme, "bob")
+	srv := httptest.NewServer(NewHandler("token", NewKey(alice.SecretKey), NewKey(bob.SecretKey)))
+	defer srv.Close()
+
+	signers, err := NewRemoteSigners(ctx, srv.URL+"/", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signers) != 2 || signers[0].PublicKey() != alice.PublicKey || signers[1].PublicKey() != bob.PublicKey {
+		t.Fatalf("NewRemoteSigners() returned keys of other accounts")
+	}
+
+	body := []byte("body")
+	sig, err := signers[1].Sign(ctx, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !crypto.Verify(bob.PublicKey, sig, body) {
+		t.Error("signature isn't valid")
+	}
+
+	tx := proto.NewUnsignedDataWithProofs(2, alice.PublicKey, 500000, 1)
+	err = SignTx(ctx, signers[0], proto.TestNetScheme, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := tx.Verify(proto.TestNetScheme, alice.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("transaction signed remotely isn't valid")
+	}
+}
+
+func TestRemoteErrors(t *testing.T) {
+	ctx := context.Background()
+	alice := node.NewFakeAccount(proto.TestNetScheme, "alice")
+	bob := node.NewFakeAccount(proto.TestNetScheme, "bob")
+	srv := httptest.NewServer(NewHandler("token",
+		NewKey(alice.SecretKey),
+		impostor{Signer: NewKey(alice.SecretKey), pub: bob.PublicKey},
+	))
+	defer srv.Close()
+
+	_, err := RemotePublicKeys(ctx, srv.URL, "wrong")
+	if err == nil || !strings.Contains(err.Error(), "invalid token") {
+		t.Errorf("RemotePublicKeys() with wrong token error = %v, want invalid token", err)
+	}
+
+	carol := node.NewFakeAccount(proto.TestNetScheme, "carol")
+	_, err = NewRemote(srv.URL, "token", carol.PublicKey).Sign(ctx, []byte("body"))
+	if err == nil || !strings.Contains(err.Error(), "unknown public key") {
+		t.Errorf("Sign() with unknown key error = %v, want unknown public key", err)
+	}
+
+	_, err = NewRemote(srv.URL, "token", bob.PublicKey).Sign(ctx, []byte("body"))
+	if err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("Sign() of misconfigured service error = %v, want invalid signature", err)
+	}
+
+	res, err := http.Post(srv.URL+"/publicKeys", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("request without token status = %d, want %d", res.StatusCode, http.StatusUnauthorized)
+	}
+}
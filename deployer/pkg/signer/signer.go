@@ -0,0 +1,127 @@
+package signer
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/txfields"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Signer signs transaction bodies with one key, the private key may be kept outside of the deployer
+type Signer interface {
+	PublicKey() crypto.PublicKey
+	// Sign returns signature of transaction body
+	Sign(ctx context.Context, body []byte) (crypto.Signature, error)
+}
+
+// key keeps private key in memory
+type key struct {
+	prv crypto.SecretKey
+	pub crypto.PublicKey
+}
+
+func NewKey(prv crypto.SecretKey) Signer {
+	return key{
+		prv: prv,
+		pub: crypto.GeneratePublicKey(prv),
+	}
+}
+
+func (k key) PublicKey() crypto.PublicKey {
+	return k.pub
+}
+
+func (k key) Sign(_ context.Context, body []byte) (crypto.Signature, error) {
+	sig, err := crypto.Sign(k.prv, body)
+	if err != nil {
+		return crypto.Signature{}, fmt.Errorf("crypto.Sign: %w", err)
+	}
+	return sig, nil
+}
+
+// NewSeed returns signer of the account with the nonce of the seed, nonce is 0 for the first account
+func NewSeed(seed string, nonce uint32) (Signer, error) {
+	prv, _, err := KeyPair([]byte(seed), nonce)
+	if err != nil {
+		return nil, fmt.Errorf("KeyPair: %w", err)
+	}
+	return NewKey(prv), nil
+}
+
+// KeyPair derives keys of the account with the nonce of the seed the same way as Waves wallets
+func KeyPair(seed []byte, nonce uint32) (crypto.SecretKey, crypto.PublicKey, error) {
+	s := make([]byte, 4, 4+len(seed))
+	binary.BigEndian.PutUint32(s, nonce)
+	s = append(s, seed...)
+
+	accSeed, err := crypto.SecureHash(s)
+	if err != nil {
+		return crypto.SecretKey{}, crypto.PublicKey{}, fmt.Errorf("crypto.SecureHash: %w", err)
+	}
+	return crypto.GenerateKeyPair(accSeed[:])
+}
+
+// SignTx appends proof of the signer to transaction and sets its id
+func SignTx(ctx context.Context, s Signer, scheme proto.Scheme, tx proto.Transaction) error {
+	body, err := proto.MarshalTxBody(scheme, tx)
+	if err != nil {
+		return fmt.Errorf("proto.MarshalTxBody: %w", err)
+	}
+
+	sig, err := s.Sign(ctx, body)
+	if err != nil {
+		return fmt.Errorf("s.Sign: %w", err)
+	}
+
+	err = AppendProof(tx, sig)
+	if err != nil {
+		return err
+	}
+
+	// the same as tx.Sign does, id may be outdated if the body was changed after it was generated
+	id, err := crypto.FastHash(body)
+	if err != nil {
+		return fmt.Errorf("crypto.FastHash: %w", err)
+	}
+	f, err := txfields.Of(tx)
+	if err != nil {
+		return err
+	}
+	*f.ID = &id
+	return nil
+}
+
+// AppendProof adds signature after existing proofs
+func AppendProof(tx proto.Transaction, sig crypto.Signature) error {
+	f, err := txfields.Of(tx)
+	if err != nil {
+		return err
+	}
+	if *f.Proofs == nil {
+		*f.Proofs = proto.NewProofs()
+	}
+	(*f.Proofs).Proofs = append((*f.Proofs).Proofs, sig[:])
+	return nil
+}
+
+// ResetProofs removes proofs, so transaction can be signed again
+func ResetProofs(tx proto.Transaction) error {
+	f, err := txfields.Of(tx)
+	if err != nil {
+		return err
+	}
+	*f.Proofs = nil
+	return nil
+}
+
+// Sender returns public key of transaction sender
+func Sender(tx proto.Transaction) (crypto.PublicKey, error) {
+	f, err := txfields.Of(tx)
+	if err != nil {
+		return crypto.PublicKey{}, err
+	}
+	return f.Sender, nil
+}
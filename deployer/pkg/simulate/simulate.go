@@ -0,0 +1,70 @@
+package simulate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/statediff"
+	"github.com/wavesplatform/gowaves/pkg/client"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Invoke evaluates invoke on current state before it's broadcast, error if evaluation fails,
+// so broken arguments are caught before they cost fees
+func Invoke(ctx context.Context, nd node.Node, tx *proto.InvokeScriptWithProofs) (node.Evaluation, error) {
+	ev, err := nd.EvaluateInvoke(ctx, tx)
+	if err != nil {
+		return node.Evaluation{}, fmt.Errorf("nd.EvaluateInvoke: %w", err)
+	}
+	if ev.Error != 0 {
+		return ev, errors.New("invoke evaluation failed: " + ev.Message)
+	}
+	return ev, nil
+}
+
+// Describe returns complexity and expected state changes of evaluated invoke, nested invokes are indented
+func Describe(ev node.Evaluation) string {
+	lines := []string{fmt.Sprintf("complexity %d", ev.Complexity)}
+	lines = append(lines, describeChanges(ev.StateChanges, "")...)
+	return strings.Join(lines, "\n")
+}
+
+func describeChanges(sc client.StateChanges, indent string) []string {
+	var res []string
+	for _, e := range sc.Data {
+		if e.GetValueType() == proto.DataDelete {
+			res = append(res, fmt.Sprintf("%sdata %s deleted", indent, e.GetKey()))
+			continue
+		}
+		res = append(res, fmt.Sprintf("%sdata %s = %s", indent, e.GetKey(), statediff.Value(e)))
+	}
+	for _, t := range sc.Transfers {
+		res = append(res, fmt.Sprintf("%stransfer %d %s to %s", indent, t.Amount, t.Asset.String(), t.Address))
+	}
+	for _, i := range sc.Issues {
+		res = append(res, fmt.Sprintf("%sissue %s %q quantity %d decimals %d", indent, i.AssetID, i.Name, i.Quantity, i.Decimals))
+	}
+	for _, r := range sc.Reissues {
+		res = append(res, fmt.Sprintf("%sreissue %s quantity %d", indent, r.AssetID, r.Quantity))
+	}
+	for _, b := range sc.Burns {
+		res = append(res, fmt.Sprintf("%sburn %s quantity %d", indent, b.AssetID, b.Quantity))
+	}
+	for _, s := range sc.SponsorFees {
+		res = append(res, fmt.Sprintf("%ssponsor fee %s", indent, s.AssetID))
+	}
+	for _, l := range sc.Leases {
+		res = append(res, fmt.Sprintf("%slease %d to %s", indent, l.Amount, l.Recipient.String()))
+	}
+	for _, l := range sc.LeaseCancels {
+		res = append(res, fmt.Sprintf("%slease cancel %s", indent, l.ID))
+	}
+	for _, inv := range sc.Invokes {
+		res = append(res, fmt.Sprintf("%sinvoke %s.%s", indent, inv.DApp, inv.Call.Name()))
+		res = append(res, describeChanges(inv.StateChanges, indent+"  ")...)
+	}
+	return res
+}
@@ -0,0 +1,168 @@
+package statediff
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+type Kind string
+
+const (
+	KindAdded       Kind = "added"
+	KindChanged     Kind = "changed"
+	KindDeleted     Kind = "deleted"
+	KindTypeChanged Kind = "type change"
+	KindUnchanged   Kind = "unchanged"
+)
+
+// sensitiveKeys control who can sign transactions of the contract or where it reads its config from
+var sensitiveKeys = map[string]string{
+	"%s__managerPublicKey":      "manager public key signs transactions of the contract",
+	"%s__tempManagerPubKey":     "temporary manager public key signs transactions of the contract",
+	"%s__managerVaultAddress":   "manager vault holds manager public key of the contract",
+	"%s__adminPubKeys":          "admin public keys may call restricted functions",
+	"%s__adminAddressList":      "admins approve transactions of manager vault",
+	"%s__TXID":                  "approved tx id is allowed by manager vault without proofs",
+	"%s__factoryContract":       "factory holds manager vault address and config of the contract",
+	"%s__legacyFactoryContract": "factory holds manager vault address and config of the contract",
+}
+
+// Change is the difference of one key between the chain and data transaction
+type Change struct {
+	Key  string          `json:"key"`
+	Kind Kind            `json:"kind"`
+	Old  proto.DataEntry `json:"old,omitempty"`
+	New  proto.DataEntry `json:"new,omitempty"`
+	// Warning explains why the change is suspicious
+	Warning string `json:"warning,omitempty"`
+}
+
+// Diff compares every entry of data transaction with the current value at the sender address
+func Diff(ctx context.Context, nd node.Node, tx *proto.DataWithProofs) ([]Change, error) {
+	addr, err := proto.NewAddressFromPublicKey(nd.Scheme(), tx.SenderPK)
+	if err != nil {
+		return nil, fmt.Errorf("proto.NewAddressFromPublicKey: %w", err)
+	}
+	return DiffEntries(ctx, nd, addr, tx.Entries)
+}
+
+// DiffEntries compares entries with the current values at addr
+func DiffEntries(ctx context.Context, nd node.Node, addr proto.WavesAddress, entries proto.DataEntries) ([]Change, error) {
+	res := make([]Change, 0, len(entries))
+	for _, e := range entries {
+		old, err := nd.DataKey(ctx, addr, e.GetKey())
+		if err != nil {
+			return nil, fmt.Errorf("nd.DataKey: %w", err)
+		}
+
+		c := Change{Key: e.GetKey(), Old: old}
+		deleted := e.GetValueType() == proto.DataDelete
+		if !deleted {
+			c.New = e
+		}
+
+		switch {
+		case old == nil && deleted:
+			c.Kind = KindUnchanged
+		case old == nil:
+			c.Kind = KindAdded
+		case deleted:
+			c.Kind = KindDeleted
+		case old.GetValueType() != e.GetValueType():
+			c.Kind = KindTypeChanged
+		case reflect.DeepEqual(old, e):
+			c.Kind = KindUnchanged
+		default:
+			c.Kind = KindChanged
+		}
+
+		if c.Kind != KindUnchanged {
+			c.Warning = warning(c)
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+func warning(c Change) string {
+	if reason, ok := sensitiveKeys[c.Key]; ok {
+		return fmt.Sprintf("%s is %s: %s", c.Key, c.Kind, reason)
+	}
+	if c.Kind == KindTypeChanged {
+		return fmt.Sprintf("%s changes type from %s to %s, contracts reading it will fail",
+			c.Key, c.Old.GetValueType(), c.New.GetValueType())
+	}
+	return ""
+}
+
+// Suspicious returns changes with warnings
+func Suspicious(changes []Change) []Change {
+	var res []Change
+	for _, c := range changes {
+		if c.Warning != "" {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+func (c Change) String() string {
+	var res string
+	switch c.Kind {
+	case KindAdded:
+		res = fmt.Sprintf("+ %s: %s", c.Key, Value(c.New))
+	case KindDeleted:
+		res = fmt.Sprintf("- %s: %s", c.Key, Value(c.Old))
+	case KindChanged, KindTypeChanged:
+		res = fmt.Sprintf("~ %s: %s -> %s", c.Key, Value(c.Old), Value(c.New))
+	default:
+		res = fmt.Sprintf("  %s: %s", c.Key, Value(c.Old))
+	}
+	if c.Warning != "" {
+		res += "\n  ! " + c.Warning
+	}
+	return res
+}
+
+// Format returns diff of changed keys, unchanged ones are counted only
+func Format(changes []Change) string {
+	var (
+		lines     []string
+		unchanged int
+	)
+	for _, c := range changes {
+		if c.Kind == KindUnchanged {
+			unchanged++
+			continue
+		}
+		lines = append(lines, c.String())
+	}
+	if unchanged > 0 {
+		lines = append(lines, fmt.Sprintf("  %d keys unchanged", unchanged))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Value returns typed value of data entry, <none> for missing one
+func Value(e proto.DataEntry) string {
+	switch v := e.(type) {
+	case nil:
+		return "<none>"
+	case *proto.StringDataEntry:
+		return fmt.Sprintf("string %q", v.Value)
+	case *proto.IntegerDataEntry:
+		return fmt.Sprintf("integer %d", v.Value)
+	case *proto.BooleanDataEntry:
+		return fmt.Sprintf("boolean %t", v.Value)
+	case *proto.BinaryDataEntry:
+		return "binary base64:" + base64.StdEncoding.EncodeToString(v.Value)
+	default:
+		return e.GetValueType().String()
+	}
+}
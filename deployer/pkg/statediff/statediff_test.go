@@ -0,0 +1,72 @@
+package statediff
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+func TestDiffEntries(t *testing.T) {
+	ctx := context.Background()
+	nd := node.NewFake(proto.TestNetScheme)
+	addr := node.NewFakeAccount(nd.Scheme(), "statediff").Address
+	nd.SetData(addr,
+		&proto.IntegerDataEntry{Key: "%s__changed", Value: 1},
+		&proto.IntegerDataEntry{Key: "%s__unchanged", Value: 2},
+		&proto.StringDataEntry{Key: "%s__deleted", Value: "old"},
+		&proto.IntegerDataEntry{Key: "%s__typeChanged", Value: 3},
+		&proto.StringDataEntry{Key: "%s__managerPublicKey", Value: "old"},
+	)
+
+	changes, err := DiffEntries(ctx, nd, addr, proto.DataEntries{
+		&proto.IntegerDataEntry{Key: "%s__changed", Value: 10},
+		&proto.IntegerDataEntry{Key: "%s__unchanged", Value: 2},
+		&proto.DeleteDataEntry{Key: "%s__deleted"},
+		&proto.StringDataEntry{Key: "%s__typeChanged", Value: "3"},
+		&proto.BooleanDataEntry{Key: "%s__added", Value: true},
+		&proto.DeleteDataEntry{Key: "%s__missing"},
+		&proto.StringDataEntry{Key: "%s__managerPublicKey", Value: "new"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		key     string
+		kind    Kind
+		warning bool
+	}{
+		{"%s__changed", KindChanged, false},
+		{"%s__unchanged", KindUnchanged, false},
+		{"%s__deleted", KindDeleted, false},
+		{"%s__typeChanged", KindTypeChanged, true},
+		{"%s__added", KindAdded, false},
+		{"%s__missing", KindUnchanged, false},
+		{"%s__managerPublicKey", KindChanged, true},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("got %d changes, want %d", len(changes), len(want))
+	}
+	for i, c := range changes {
+		if c.Key != want[i].key || c.Kind != want[i].kind {
+			t.Errorf("changes[%d] = %s %s, want %s %s", i, c.Key, c.Kind, want[i].key, want[i].kind)
+		}
+		if (c.Warning != "") != want[i].warning {
+			t.Errorf("%s warning = %q, want warning %t", c.Key, c.Warning, want[i].warning)
+		}
+	}
+
+	if n := len(Suspicious(changes)); n != 2 {
+		t.Errorf("got %d suspicious changes, want 2", n)
+	}
+	formatted := Format(changes)
+	if !strings.Contains(formatted, "2 keys unchanged") {
+		t.Errorf("unchanged keys aren't counted:\n%s", formatted)
+	}
+	if strings.Contains(formatted, "%s__unchanged") {
+		t.Errorf("unchanged key is listed:\n%s", formatted)
+	}
+}
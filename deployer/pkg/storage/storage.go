@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/audit"
+	"github.com/waves-exchange/contracts/deployer/pkg/branch"
+	"github.com/waves-exchange/contracts/deployer/pkg/contract"
+	"github.com/waves-exchange/contracts/deployer/pkg/envelope"
+	"github.com/waves-exchange/contracts/deployer/pkg/journal"
+	"github.com/waves-exchange/contracts/deployer/pkg/migration"
+	"github.com/waves-exchange/contracts/deployer/pkg/mongo"
+)
+
+type Backend string
+
+const (
+	BackendMongo Backend = "mongo"
+	BackendFile  Backend = "file"
+)
+
+type Options struct {
+	Backend Backend
+
+	MongoURI                 string
+	MongoDatabaseName        string
+	MongoCollectionContracts string
+	MongoCollectionBranches  string
+	// MongoCollectionMigrations keeps applied migrations/<name>
+	MongoCollectionMigrations string
+	// MongoCollectionAudit keeps every transaction broadcast by deployer
+	MongoCollectionAudit string
+	// MongoCollectionJournal keeps steps applied by create-stage, so failed run can be resumed
+	MongoCollectionJournal string
+
+	// Dir is a folder with JSON files for file backend
+	Dir string
+
+	// Cipher encrypts private keys of contracts, keys are kept in plain text if it's nil
+	Cipher *envelope.Cipher
+	// Logger warns if keys are kept in plain text
+	Logger zerolog.Logger
+}
+
+// Storage holds deployer models of one network
+type Storage struct {
+	Contracts  contract.Model
+	Branches   branch.Model
+	Migrations migration.Model
+	Audit      audit.Model
+	Journal    journal.Model
+}
+
+func Open(ctx context.Context, opts Options) (Storage, error) {
+	st, err := OpenRaw(ctx, opts)
+	if err != nil {
+		return Storage{}, err
+	}
+	if opts.Cipher == nil {
+		opts.Logger.Warn().Str("backend", string(opts.Backend)).
+			Msg("NO MASTER KEY: private keys of new contracts are stored in PLAIN TEXT, encrypted ones can't be read")
+	}
+	st.Contracts = contract.NewEncryptedModel(st.Contracts, opts.Cipher)
+	return st, nil
+}
+
+// OpenRaw returns contracts with private keys as they are stored, cipher is ignored, it's used to re-encrypt them
+func OpenRaw(ctx context.Context, opts Options) (Storage, error) {
+	switch opts.Backend {
+	case BackendMongo:
+		if opts.MongoURI == "" {
+			return Storage{}, errors.New("mongo uri required")
+		}
+
+		db, err := mongo.NewConn(ctx, opts.MongoDatabaseName, opts.MongoURI)
+		if err != nil {
+			return Storage{}, fmt.Errorf("mongo.NewConn: %w", err)
+		}
+
+		return Storage{
+			Contracts:  contract.NewMongoModel(db.Collection(opts.MongoCollectionContracts)),
+			Branches:   branch.NewMongoModel(db.Collection(opts.MongoCollectionBranches)),
+			Migrations: migration.NewMongoModel(db.Collection(opts.MongoCollectionMigrations)),
+			Audit:      audit.NewMongoModel(db.Collection(opts.MongoCollectionAudit)),
+			Journal:    journal.NewMongoModel(db.Collection(opts.MongoCollectionJournal)),
+		}, nil
+	case BackendFile:
+		if opts.Dir == "" {
+			return Storage{}, errors.New("storage dir required")
+		}
+
+		return Storage{
+			Contracts:  contract.NewFileModel(filepath.Join(opts.Dir, "contracts.json")),
+			Branches:   branch.NewFileModel(filepath.Join(opts.Dir, "branches.json")),
+			Migrations: migration.NewFileModel(filepath.Join(opts.Dir, "migrations.json")),
+			Audit:      audit.NewFileModel(filepath.Join(opts.Dir, "audit.json")),
+			Journal:    journal.NewFileModel(filepath.Join(opts.Dir, "journal.json")),
+		}, nil
+	default:
+		return Storage{}, fmt.Errorf("unknown storage backend: %s", opts.Backend)
+	}
+}
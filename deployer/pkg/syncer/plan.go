@@ -0,0 +1,115 @@
+package syncer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"golang.org/x/crypto/blake2b"
+)
+
+type PlanAction string
+
+const (
+	PlanActionSkip      PlanAction = "skip"
+	PlanActionSetScript PlanAction = "setScript"
+	PlanActionData      PlanAction = "data"
+	PlanActionTransfer  PlanAction = "transfer"
+)
+
+// PlanItem is a single step ApplyChanges would take for a file/tag/address
+type PlanItem struct {
+	File              string     `json:"file"`
+	Tag               string     `json:"tag,omitempty"`
+	Stage             uint32     `json:"stage,omitempty"`
+	Address           string     `json:"address"`
+	Action            PlanAction `json:"action"`
+	Key               string     `json:"key,omitempty"`
+	OldHash           string     `json:"oldHash,omitempty"`
+	NewHash           string     `json:"newHash,omitempty"`
+	Amount            uint64     `json:"amount,omitempty"`
+	Fee               uint64     `json:"fee,omitempty"`
+	Signer            string     `json:"signer,omitempty"`
+	RequiresSignature bool       `json:"requiresSignature,omitempty"`
+	// StateDiff is the change of data entries made by the data transaction
+	StateDiff string `json:"stateDiff,omitempty"`
+}
+
+// Plan collects everything ApplyChanges would broadcast when syncer runs in dry-run mode
+type Plan struct {
+	Network config.Network `json:"network"`
+	Branch  string         `json:"branch"`
+	Items   []PlanItem     `json:"items"`
+
+	mu     *sync.Mutex
+	topUps map[proto.WavesAddress]uint64
+}
+
+func newPlan(network config.Network, branch string) *Plan {
+	return &Plan{
+		Network: network,
+		Branch:  branch,
+		Items:   []PlanItem{},
+		mu:      &sync.Mutex{},
+		topUps:  map[proto.WavesAddress]uint64{},
+	}
+}
+
+func (p *Plan) add(item PlanItem) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Items = append(p.Items, item)
+}
+
+// addTopUp remembers planned transfer, so next balance checks see it as already sent
+func (p *Plan) addTopUp(addr proto.WavesAddress, amount uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.topUps[addr] += amount
+}
+
+func (p *Plan) plannedTopUp(addr proto.WavesAddress) uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.topUps[addr]
+}
+
+func (p *Plan) WriteFile(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("json.MarshalIndent: %w", err)
+	}
+
+	err = os.WriteFile(name, b, 0644)
+	if err != nil {
+		return fmt.Errorf("os.WriteFile: %w", err)
+	}
+	return nil
+}
+
+func scriptHash(scriptBytes []byte) string {
+	if len(scriptBytes) == 0 {
+		return ""
+	}
+	h := blake2b.Sum256(scriptBytes)
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+func base64ScriptHash(base64Script string) (string, error) {
+	if base64Script == "" {
+		return "", nil
+	}
+	scriptBytes, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(base64Script, "base64:"))
+	if err != nil {
+		return "", fmt.Errorf("base64.StdEncoding.DecodeString: %w", err)
+	}
+	return scriptHash(scriptBytes), nil
+}
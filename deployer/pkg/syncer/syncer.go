@@ -1,55 +1,58 @@
 package syncer
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
-	"os/exec"
 	"path"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"github.com/waves-exchange/contracts/deployer/pkg/audit"
+	"github.com/waves-exchange/contracts/deployer/pkg/batch"
 	"github.com/waves-exchange/contracts/deployer/pkg/branch"
+	"github.com/waves-exchange/contracts/deployer/pkg/compiler"
 	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/confirm"
 	"github.com/waves-exchange/contracts/deployer/pkg/contract"
+	"github.com/waves-exchange/contracts/deployer/pkg/datawriter"
+	"github.com/waves-exchange/contracts/deployer/pkg/fee"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/scriptdiff"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
+	"github.com/waves-exchange/contracts/deployer/pkg/statediff"
 	"github.com/waves-exchange/contracts/deployer/pkg/tools"
-	"github.com/wavesplatform/gowaves/pkg/client"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 	"golang.org/x/crypto/blake2b"
 	"golang.org/x/sync/errgroup"
 )
 
-type compileCacheMap = map[string]func() (
-	base64Script string, scriptBytes []byte, setScriptFee uint64, err error,
-)
-
 type Syncer struct {
 	logger                       zerolog.Logger
 	network                      config.Network
 	networkByte                  proto.Scheme
-	rawClient                    *client.Client // TODO: make gowaves client with ratelimit as separate package
-	clientMutex                  *sync.Mutex
+	node                         node.Node
+	compiler                     compiler.Compiler
 	contractsFolder              string
 	contractModel                contract.Model
 	branch                       string
 	branchModel                  branch.Model
 	compareLpScriptAddress       proto.WavesAddress
 	compareLpStableScriptAddress proto.WavesAddress
-	compileCache                 compileCacheMap
 	mined                        *errgroup.Group
-	feePrv                       crypto.SecretKey
+	feeSigner                    signer.Signer
 	feePub                       crypto.PublicKey
+	dryRun                       bool
+	plan                         *Plan
+	batch                        *batch.Batch
+	confirmOpts                  confirm.Options
 }
 
 const (
@@ -60,12 +63,15 @@ const (
 func NewSyncer(
 	logger zerolog.Logger,
 	network config.Network,
-	node string,
+	nd node.Node,
+	comp compiler.Compiler,
 	branch string,
 	contractModel contract.Model,
 	branchModel branch.Model,
 	compareLpScriptAddress, compareLpStableScriptAddress string,
 	feeSeed string,
+	dryRun bool,
+	confirmOpts confirm.Options,
 ) (*Syncer, error) {
 	var networkByte proto.Scheme
 	switch network {
@@ -76,11 +82,8 @@ func NewSyncer(
 	default:
 		return nil, fmt.Errorf("unknown network: %s", network)
 	}
-	cl, err := client.NewClient(
-		client.Options{BaseUrl: node, Client: &http.Client{Timeout: time.Minute}, ChainID: networkByte},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("client.NewClient: %w", err)
+	if nd.Scheme() != networkByte {
+		return nil, fmt.Errorf("node scheme %d doesn't match network %s", nd.Scheme(), network)
 	}
 
 	compareLpScriptAddr, err := proto.NewAddressFromString(compareLpScriptAddress)
@@ -92,30 +95,46 @@ func NewSyncer(
 		return nil, fmt.Errorf("proto.NewAddressFromString: %w", err)
 	}
 
-	feePrv, feePub, err := tools.GetPrivateAndPublicKey([]byte(feeSeed))
+	feeSigner, err := signer.NewSeed(feeSeed, 0)
 	if err != nil {
-		return nil, fmt.Errorf("crypto.GenerateKeyPair: %w", err)
+		return nil, fmt.Errorf("signer.NewSeed: %w", err)
 	}
 
+	sLogger := logger.With().Str("pkg", "syncer").Logger()
+	confirmOpts.Logger = sLogger
+
 	return &Syncer{
-		logger:                       logger.With().Str("pkg", "syncer").Logger(),
+		logger:                       sLogger,
 		network:                      network,
 		networkByte:                  networkByte,
-		rawClient:                    cl,
-		clientMutex:                  &sync.Mutex{},
+		node:                         nd,
+		compiler:                     comp,
 		contractsFolder:              path.Join("..", "ride"),
 		contractModel:                contractModel,
 		branch:                       branch,
 		branchModel:                  branchModel,
 		compareLpScriptAddress:       compareLpScriptAddr,
 		compareLpStableScriptAddress: compareLpStableScriptAddr,
-		compileCache:                 make(compileCacheMap),
 		mined:                        &errgroup.Group{},
-		feePrv:                       feePrv,
-		feePub:                       feePub,
+		feeSigner:                    feeSigner,
+		feePub:                       feeSigner.PublicKey(),
+		dryRun:                       dryRun,
+		plan:                         newPlan(network, branch),
+		batch:                        batch.New(networkByte),
+		confirmOpts:                  confirmOpts,
 	}, nil
 }
 
+// Plan returns steps collected in dry-run mode
+func (s *Syncer) Plan() *Plan {
+	return s.plan
+}
+
+// Batch returns mainnet transactions waiting for signatures
+func (s *Syncer) Batch() *batch.Batch {
+	return s.batch
+}
+
 func intPtr(val int) *int {
 	return &val
 }
@@ -238,38 +257,44 @@ func (s *Syncer) ApplyChanges(c context.Context) error {
 		return fmt.Errorf("s.mined.Wait: %w", err)
 	}
 
+	if s.dryRun {
+		s.logger.Info().Int("items", len(s.plan.Items)).Msg("dry-run: plan computed, nothing broadcasted")
+		return nil
+	}
+
 	s.logger.Info().Msg("changes applied")
 
 	return nil
 }
 
-func (s *Syncer) ensureHasFee(ctx context.Context, to proto.WavesAddress, fee uint64, fileName string) error {
-	bal, _, err := s.client().Addresses.Balance(ctx, to)
+func (s *Syncer) ensureHasFee(ctx context.Context, to proto.WavesAddress, required uint64, fileName string) error {
+	bal, err := s.node.Balance(ctx, to)
 	if err != nil {
-		return fmt.Errorf("s.client().Addresses.Balance: %w", err)
+		return fmt.Errorf("s.node.Balance: %w", err)
 	}
 
 	const twoWaves = 2 * 100000000
-	amountToSend := twoWaves + fee
-	if bal.Balance < amountToSend {
-		e := s.sendTx(
-			ctx,
-			proto.NewUnsignedTransferWithProofs(
-				3,
-				s.feePub,
-				proto.NewOptionalAssetWaves(),
-				proto.NewOptionalAssetWaves(),
-				tools.Timestamp(),
-				amountToSend,
-				100000,
-				proto.NewRecipientFromAddress(to),
-				nil,
-			),
-			s.feePrv,
-			false,
-			false,
-			fileName,
-		)
+	amountToSend := twoWaves + required
+	if bal+s.plan.plannedTopUp(to) < amountToSend {
+		tx, e := s.transferTx(ctx, to, amountToSend)
+		if e != nil {
+			return fmt.Errorf("s.transferTx: %w", e)
+		}
+
+		if s.dryRun {
+			s.plan.addTopUp(to, amountToSend)
+			s.plan.add(PlanItem{
+				File:    fileName,
+				Address: to.String(),
+				Action:  PlanActionTransfer,
+				Amount:  amountToSend,
+				Fee:     tx.Fee,
+				Signer:  s.feePub.String(),
+			})
+			return nil
+		}
+
+		e = s.sendTx(ctx, tx, s.feeSigner, false, false, fileName)
 		if e != nil {
 			return fmt.Errorf("s.sendTx: %w", e)
 		}
@@ -277,8 +302,8 @@ func (s *Syncer) ensureHasFee(ctx context.Context, to proto.WavesAddress, fee ui
 		s.logger.Info().
 			Str("address", to.String()).
 			Uint64("amount", amountToSend).
-			Uint64("balanceBefore", bal.Balance).
-			Uint64("balanceAfter", bal.Balance+amountToSend).
+			Uint64("balanceBefore", bal).
+			Uint64("balanceAfter", bal+amountToSend).
 			Msg("WAVES to address was sent")
 	}
 
@@ -313,7 +338,7 @@ func (s *Syncer) doHash(
 		return false, fmt.Errorf("s.contractModel.IsCompact: %w", err)
 	}
 
-	scriptBase64, scriptBytes, _, err := s.compile(ctx, bodyLpRide, compact)
+	scriptBase64, scriptBytes, err := s.compile(ctx, bodyLpRide, compact)
 	if err != nil {
 		return false, fmt.Errorf("s.compile: %w", err)
 	}
@@ -344,11 +369,11 @@ func (s *Syncer) doHash(
 			return false, fmt.Errorf("crypto.NewSecretKeyFromBase58: %w", er)
 		}
 
-		dataTx := proto.NewUnsignedDataWithProofs(2, pub, 500000, tools.Timestamp())
-		er = dataTx.AppendEntry(dataTxValue)
+		dataTxs, er := datawriter.Pack(ctx, s.node, pub, proto.DataEntries{dataTxValue})
 		if er != nil {
-			return false, fmt.Errorf("dataTx.AppendEntry: %w", er)
+			return false, fmt.Errorf("datawriter.Pack: %w", er)
 		}
+		dataTx := dataTxs[0]
 
 		addr, er := proto.NewAddressFromPublicKey(proto.TestNetScheme, pub)
 		if er != nil {
@@ -360,8 +385,34 @@ func (s *Syncer) doHash(
 			return false, fmt.Errorf("s.getStringValue: %w", er)
 		}
 
+		// sendTx doesn't reach its fee check in dry-run, so the top-up is planned here
 		if actualHash != newHashStr {
-			e := s.sendTx(ctx, dataTx, prvSigner, false, true, fileName)
+			er = s.ensureHasFee(ctx, addr, dataTx.Fee, fileName)
+			if er != nil {
+				return false, fmt.Errorf("s.ensureHasFee: %w", er)
+			}
+		}
+
+		if s.dryRun {
+			item := PlanItem{
+				File:    fileName,
+				Tag:     factory.Tag,
+				Address: addr.String(),
+				Action:  PlanActionSkip,
+				Key:     key,
+				OldHash: actualHash,
+				NewHash: newHashStr,
+			}
+			if actualHash != newHashStr {
+				item.Action = PlanActionData
+				item.Fee = dataTx.Fee
+				item.Signer = crypto.GeneratePublicKey(prvSigner).String()
+			}
+			s.plan.add(item)
+		}
+
+		if actualHash != newHashStr {
+			e := s.sendTx(audit.WithSource(ctx, fileName, factory.Tag), dataTx, signer.NewKey(prvSigner), false, true, fileName)
 			if e != nil {
 				return false, fmt.Errorf("sendTx %s: %w", fileName, e)
 			}
@@ -392,12 +443,11 @@ func (s *Syncer) doHash(
 
 		hashEmpty = actualHash == ""
 
-		const fee = 500000
-		dataTx := proto.NewUnsignedDataWithProofs(2, pub, fee, tools.Timestamp())
-		er = dataTx.AppendEntry(dataTxValue)
+		dataTxs, er := datawriter.Pack(ctx, s.node, pub, proto.DataEntries{dataTxValue})
 		if er != nil {
-			return false, fmt.Errorf("dataTx.AppendEntry: %w", er)
+			return false, fmt.Errorf("datawriter.Pack: %w", er)
 		}
+		dataTx := dataTxs[0]
 
 		log := func() *zerolog.Event {
 			return s.logger.Info().
@@ -424,20 +474,59 @@ func (s *Syncer) doHash(
 				Str("left", "blockchain").
 				Str("right", "local").
 				Msg("print diff")
-			e = s.printDiff(ctx, fileName, blockchainBase64, scriptBase64)
+			diff, e := scriptdiff.Print(ctx, s.node, fileName, blockchainBase64, scriptBase64)
+			if e != nil {
+				return false, fmt.Errorf("scriptdiff.Print: %w", e)
+			}
+
+			changes, e := statediff.Diff(ctx, s.node, dataTx)
 			if e != nil {
-				return false, fmt.Errorf("s.printDiff: %w", e)
+				return false, fmt.Errorf("statediff.Diff: %w", e)
+			}
+			stateDiff := statediff.Format(changes)
+			log().Msg("state diff of factory data:\n" + stateDiff)
+			for _, c := range statediff.Suspicious(changes) {
+				log().Str("warning", c.Warning).Msg("suspicious data change")
 			}
 
-			e = s.ensureHasFee(ctx, addr, fee, fileName)
+			e = s.ensureHasFee(ctx, addr, dataTx.Fee, fileName)
 			if e != nil {
 				return false, fmt.Errorf("s.ensureHasFee: %w", e)
 			}
 
+			if s.dryRun {
+				s.plan.add(PlanItem{
+					File:              fileName,
+					Tag:               factory.Tag,
+					Address:           addr.String(),
+					Action:            PlanActionData,
+					Key:               key,
+					OldHash:           actualHash,
+					NewHash:           newHashStr,
+					Fee:               dataTx.Fee,
+					Signer:            pub.String(),
+					RequiresSignature: true,
+					StateDiff:         stateDiff,
+				})
+			}
+
 			log().RawJSON("tx", tx).
 				Msg("we are about to set script as approved. " +
 					"sign and broadcast data-tx to continue")
 
+			if !s.dryRun {
+				e = s.batch.Add(batch.Item{
+					File:       fileName,
+					Tag:        factory.Tag,
+					Diff:       diff,
+					StateDiff:  stateDiff,
+					ScriptHash: newHashStr,
+				}, dataTx)
+				if e != nil {
+					return false, fmt.Errorf("s.batch.Add: %w", e)
+				}
+			}
+
 			//for {
 			//	value, er3 := s.getStringValue(ctx, addr, key)
 			//	if er3 != nil {
@@ -457,6 +546,17 @@ func (s *Syncer) doHash(
 			//	log().RawJSON("tx", tx).Msg("sign data-tx. polling factory state...")
 			//}
 		} else {
+			if s.dryRun {
+				s.plan.add(PlanItem{
+					File:    fileName,
+					Tag:     factory.Tag,
+					Address: addr.String(),
+					Action:  PlanActionSkip,
+					Key:     key,
+					OldHash: actualHash,
+					NewHash: newHashStr,
+				})
+			}
 			s.logger.Info().Str("file", fileName).Str("key", key).Msg("content is the same, " +
 				"no need to update allowed script hash")
 		}
@@ -466,11 +566,10 @@ func (s *Syncer) doHash(
 }
 
 func (s *Syncer) waitNBlocks(ctx context.Context, blocks uint64) error {
-	currentHeight, _, err := s.client().Blocks.Height(ctx)
+	curH, err := s.node.Height(ctx)
 	if err != nil {
-		return fmt.Errorf("client.Blocks.Height: %w", err)
+		return fmt.Errorf("s.node.Height: %w", err)
 	}
-	curH := currentHeight.Height
 	desiredH := curH + blocks
 
 	for {
@@ -480,11 +579,10 @@ func (s *Syncer) waitNBlocks(ctx context.Context, blocks uint64) error {
 		default:
 			time.Sleep(5 * time.Second)
 
-			height, _, e := s.client().Blocks.Height(ctx)
+			h, e := s.node.Height(ctx)
 			if e != nil {
-				return fmt.Errorf("client.Blocks.Height: %w", e)
+				return fmt.Errorf("s.node.Height: %w", e)
 			}
-			h := height.Height
 
 			if h >= desiredH {
 				s.logger.Info().Uint64("actual", h).Uint64("desired", desiredH).Msg("height reached")
@@ -497,13 +595,12 @@ func (s *Syncer) waitNBlocks(ctx context.Context, blocks uint64) error {
 }
 
 func (s *Syncer) getStringValue(ctx context.Context, address proto.WavesAddress, key string) (string, error) {
-	data, _, err := s.client().Addresses.AddressesDataKey(ctx, address, key)
+	data, err := s.node.DataKey(ctx, address, key)
 	if err != nil {
-		if strings.Contains(err.Error(), "no data for this key") {
-			return "", nil
-		}
-
-		return "", fmt.Errorf("s.client().Addresses.AddressesDataKey: %w", err)
+		return "", fmt.Errorf("s.node.DataKey: %w", err)
+	}
+	if data == nil {
+		return "", nil
 	}
 
 	const invalidType = "data is not StringDataEntry: address: %s key: %s"
@@ -619,7 +716,7 @@ func (s *Syncer) doFile(
 				continue
 			}
 
-			base64Script, scriptBytes, setScriptFee, er2 := s.compile(ctx, body, cont.Compact)
+			base64Script, scriptBytes, er2 := s.compile(ctx, body, cont.Compact)
 			if er2 != nil {
 				return false, fmt.Errorf("s.compile: %w", er2)
 			}
@@ -634,28 +731,51 @@ func (s *Syncer) doFile(
 				return false, fmt.Errorf("s.getScript: %w", er2)
 			}
 
+			oldHash, er2 := base64ScriptHash(fromBlockchainScript)
+			if er2 != nil {
+				return false, fmt.Errorf("base64ScriptHash: %w", er2)
+			}
+			planItem := PlanItem{
+				File:    fileName,
+				Tag:     cont.Tag,
+				Stage:   cont.Stage,
+				Address: addr.String(),
+				Action:  PlanActionSkip,
+				OldHash: oldHash,
+				NewHash: scriptHash(scriptBytes),
+			}
+
 			if base64Script == fromBlockchainScript {
+				if s.dryRun {
+					s.plan.add(planItem)
+				}
 				if logSkip {
 					log.Str(action, skip).Msg(notChanged)
 				}
 				continue
 			}
 
-			er2 = s.ensureHasFee(ctx, addr, setScriptFee, fileName)
+			setScriptTx, er2 := s.setScriptTx(ctx, pub, scriptBytes)
+			if er2 != nil {
+				return false, fmt.Errorf("s.setScriptTx: %w", er2)
+			}
+
+			er2 = s.ensureHasFee(ctx, addr, setScriptTx.Fee, fileName)
 			if er2 != nil {
 				return false, fmt.Errorf("s.ensureHasFee: %w", er2)
 			}
 
+			if s.dryRun {
+				planItem.Action = PlanActionSetScript
+				planItem.Fee = setScriptTx.Fee
+				planItem.Signer = crypto.GeneratePublicKey(prvSigner).String()
+				s.plan.add(planItem)
+			}
+
 			er2 = s.sendTx(
-				ctx,
-				proto.NewUnsignedSetScriptWithProofs(
-					2,
-					pub,
-					scriptBytes,
-					setScriptFee,
-					tools.Timestamp(),
-				),
-				prvSigner,
+				audit.WithSource(ctx, fileName, cont.Tag),
+				setScriptTx,
+				signer.NewKey(prvSigner),
 				true,
 				true,
 				fileName,
@@ -674,7 +794,7 @@ func (s *Syncer) doFile(
 			continue
 
 		case config.Mainnet:
-			base64Script, scriptBytes, setScriptFee, er2 := s.compile(ctx, body, cont.Compact)
+			base64Script, scriptBytes, er2 := s.compile(ctx, body, cont.Compact)
 			if er2 != nil {
 				return false, fmt.Errorf("s.compile: %w", er2)
 			}
@@ -701,37 +821,64 @@ func (s *Syncer) doFile(
 					Str(tag, cont.Tag)
 			}
 
+			oldHash, er2 := base64ScriptHash(fromBlockchainScript)
+			if er2 != nil {
+				return false, fmt.Errorf("base64ScriptHash: %w", er2)
+			}
+			planItem := PlanItem{
+				File:    fileName,
+				Tag:     cont.Tag,
+				Stage:   cont.Stage,
+				Address: addr.String(),
+				Action:  PlanActionSkip,
+				OldHash: oldHash,
+				NewHash: scriptHash(scriptBytes),
+			}
+
 			if base64Script == fromBlockchainScript {
+				if s.dryRun {
+					s.plan.add(planItem)
+				}
 				if logSkip {
 					log().Str(action, skip).Msg(notChanged)
 				}
 				continue
 			}
 
-			unsignedSetScriptTx := proto.NewUnsignedSetScriptWithProofs(
-				2,
-				pub,
-				scriptBytes,
-				setScriptFee,
-				tools.Timestamp(),
-			)
+			unsignedSetScriptTx, er2 := s.setScriptTx(ctx, pub, scriptBytes)
+			if er2 != nil {
+				return false, fmt.Errorf("s.setScriptTx: %w", er2)
+			}
+			setScriptFee := unsignedSetScriptTx.Fee
+
+			planItem.Action = PlanActionSetScript
+			planItem.Fee = setScriptFee
+
 			doLpRide := cont.File == lpRide && !mainnetLpHashEmpty
 			doLpStableRide := cont.File == lpStableRide && !mainnetLpStableHashEmpty
 			if doLpRide || doLpStableRide {
-				er := s.sendTx(
-					ctx,
-					proto.NewUnsignedTransferWithProofs(
-						3,
-						s.feePub,
-						proto.NewOptionalAssetWaves(),
-						proto.NewOptionalAssetWaves(),
-						tools.Timestamp(),
-						setScriptFee,
-						100000,
-						proto.NewRecipientFromAddress(addr),
-						nil,
-					),
-					s.feePrv,
+				transferTx, er := s.transferTx(ctx, addr, setScriptFee)
+				if er != nil {
+					return false, fmt.Errorf("s.transferTx: %w", er)
+				}
+
+				if s.dryRun {
+					s.plan.add(PlanItem{
+						File:    fileName,
+						Tag:     cont.Tag,
+						Address: addr.String(),
+						Action:  PlanActionTransfer,
+						Amount:  setScriptFee,
+						Fee:     transferTx.Fee,
+						Signer:  s.feePub.String(),
+					})
+					s.plan.add(planItem)
+				}
+
+				er = s.sendTx(
+					audit.WithSource(ctx, fileName, cont.Tag),
+					transferTx,
+					s.feeSigner,
 					false,
 					false,
 					fileName,
@@ -746,9 +893,9 @@ func (s *Syncer) doFile(
 					Msg("WAVES to address were sent")
 
 				er = s.sendTx(
-					ctx,
+					audit.WithSource(ctx, fileName, cont.Tag),
 					unsignedSetScriptTx,
-					crypto.SecretKey{},
+					signer.NewKey(crypto.SecretKey{}),
 					true,
 					true,
 					fileName,
@@ -772,9 +919,16 @@ func (s *Syncer) doFile(
 
 				isChanged = true
 				log().Str(action, sign).RawJSON("tx", setScriptTx).Msg(changed)
-				er = s.printDiff(ctx, fileName, fromBlockchainScript, base64Script)
+				diff, er := scriptdiff.Print(ctx, s.node, fileName, fromBlockchainScript, base64Script)
 				if er != nil {
-					return false, fmt.Errorf("s.printDiff: %w", er)
+					return false, fmt.Errorf("scriptdiff.Print: %w", er)
+				}
+
+				if s.dryRun {
+					planItem.Signer = pub.String()
+					planItem.RequiresSignature = true
+					s.plan.add(planItem)
+					continue
 				}
 
 				*iTx += 1
@@ -798,6 +952,16 @@ func (s *Syncer) doFile(
 				if er != nil {
 					return false, fmt.Errorf("file.Write: %w", er)
 				}
+
+				er = s.batch.Add(batch.Item{
+					File:       fileName,
+					Tag:        cont.Tag,
+					Diff:       diff,
+					ScriptHash: scriptHash(scriptBytes),
+				}, unsignedSetScriptTx)
+				if er != nil {
+					return false, fmt.Errorf("s.batch.Add: %w", er)
+				}
 			}
 
 			continue
@@ -806,88 +970,10 @@ func (s *Syncer) doFile(
 	return isChanged, nil
 }
 
-func (s *Syncer) preparePathAndScript(ctx context.Context, fileName, base64Script string) (string, error) {
-	var script string
-	if base64Script != "" {
-		scr, err := s.apiDecompileScript(ctx, strings.NewReader(base64Script))
-		if err != nil {
-			return "", fmt.Errorf("s.apiDecompileScript: %w", err)
-		}
-		script = scr
-	}
-
-	u, err := uuid.NewRandom()
-	if err != nil {
-		return "", fmt.Errorf("uuid.NewRandom: %w", err)
-	}
-
-	p := path.Join("tmp", fileName+" "+u.String())
-
-	f, err := os.Create(p)
-	if err != nil {
-		return "", fmt.Errorf("os.Create: %w", err)
-	}
-
-	_, err = f.Write([]byte(script))
-	if err != nil {
-		return "", fmt.Errorf("file1.Write: %w", err)
-	}
-
-	return p, nil
-}
-
-func (s *Syncer) printDiff(ctx context.Context, fileName, base64Str1, base64Str2 string) error {
-	path1, err := s.preparePathAndScript(ctx, fileName, base64Str1)
-	defer func() {
-		_ = os.Remove(path1)
-	}()
-	if err != nil {
-		return fmt.Errorf("s.preparePathAndScript: %w", err)
-	}
-
-	path2, err := s.preparePathAndScript(ctx, fileName, base64Str2)
-	defer func() {
-		_ = os.Remove(path2)
-	}()
-	if err != nil {
-		return fmt.Errorf("s.preparePathAndScript: %w", err)
-	}
-
-	cmd := exec.Command(
-		"git",
-		"--no-pager",
-		"diff",
-		"--color",
-		"--no-index",
-		path1,
-		path2,
-	)
-	fmt.Println(cmd.String())
-	stdout, _ := cmd.Output()
-
-	fmt.Println(string(stdout))
-	return nil
-}
-
-func (s *Syncer) waitMined(ctx context.Context, txHash crypto.Digest) error {
-	try := 0
-	for {
-		try += 1
-		_, _, err := s.client().Transactions.Info(ctx, txHash)
-		if err == nil {
-			return nil
-		}
-		if try >= 100 {
-			return fmt.Errorf("testnetClient.Transactions.Info: %w", err)
-		}
-		time.Sleep(10 * time.Second)
-	}
-}
-
 func (s *Syncer) sendTx(
 	ctx context.Context,
 	tx proto.Transaction,
-	prv crypto.SecretKey,
+	sgn signer.Signer,
 	async bool,
 	ensureFee bool,
 	fileName string,
@@ -897,9 +983,9 @@ func (s *Syncer) sendTx(
 		return fmt.Errorf("tx.Validate: %w", err)
 	}
 
-	err = tx.Sign(s.networkByte, prv)
+	err = signer.SignTx(ctx, sgn, s.networkByte, tx)
 	if err != nil {
-		return fmt.Errorf("tx.Sign: %w", err)
+		return fmt.Errorf("signer.SignTx: %w", err)
 	}
 
 	txHashBytes, err := tx.GetID(s.networkByte)
@@ -912,9 +998,13 @@ func (s *Syncer) sendTx(
 		return fmt.Errorf("crypto.NewDigestFromBytes: %w", err)
 	}
 
-	fmt.Printf("%d\n", s.networkByte)
-	fmt.Printf("%s\n", txHash)
-	fmt.Printf("%+v\n", tx)
+	if s.dryRun {
+		s.logger.Debug().Str("txId", txHash.String()).Str("file", fileName).Msg("dry-run: tx not broadcasted")
+		return nil
+	}
+
+	s.logger.Debug().Str("txId", txHash.String()).Str("file", fileName).
+		Uint8("chainId", s.networkByte).Msgf("broadcast %+v", tx)
 
 	fn := func() error {
 		sender, e := tx.GetSender(s.networkByte)
@@ -934,14 +1024,17 @@ func (s *Syncer) sendTx(
 			}
 		}
 
-		_, e = s.client().Transactions.Broadcast(ctx, tx)
+		e = s.node.Broadcast(ctx, tx)
 		if e != nil {
-			return fmt.Errorf("s.client().Transactions.Broadcast (file: %s, sender: %s, txId: %s, chainId: %d, tx: %+v): %w", fileName, senderAddr.String(), txHash, s.networkByte, tx, e)
+			return fmt.Errorf("s.node.Broadcast (file: %s, sender: %s, txId: %s, chainId: %d, tx: %+v): %w", fileName, senderAddr.String(), txHash, s.networkByte, tx, e)
 		}
 
-		e = s.waitMined(ctx, txHash)
+		// expired tx is signed again by the same signer
+		e = confirm.Wait(ctx, s.node, tx, s.confirmOpts, func(ctx context.Context, tx proto.Transaction) error {
+			return signer.SignTx(ctx, sgn, s.networkByte, tx)
+		})
 		if e != nil {
-			return fmt.Errorf("waitMined: %w", e)
+			return fmt.Errorf("confirm.Wait (file: %s, txId: %s): %w", fileName, txHash, e)
 		}
 		return nil
 	}
@@ -958,151 +1051,63 @@ func (s *Syncer) sendTx(
 }
 
 func (s *Syncer) compileRaw(ctx context.Context, body []byte, compact bool) (string, error) {
-	if compact {
-		resp, err := s.apiCompactScript(ctx, bytes.NewReader(body))
-		if err != nil {
-			return "", fmt.Errorf("wavesApi.apiCompactScript: %w", err)
-		}
-		return resp, nil
-
-	} else {
-		sc, _, err := s.client().Utils.ScriptCompile(ctx, string(body))
-		if err != nil {
-			return "", fmt.Errorf("s.client().Utils.ScriptCompile: %w", err)
-		}
-		return sc.Script, nil
+	res, err := s.compiler.Compile(ctx, body, compact)
+	if err != nil {
+		return "", fmt.Errorf("s.compiler.Compile: %w", err)
 	}
+	return res.Script, nil
 }
 
-func (s *Syncer) compile(ctx context.Context, body []byte, compact bool) (string, []byte, uint64, error) {
-	key := base64.StdEncoding.EncodeToString(body) + strconv.FormatBool(compact)
-	val, ok := s.compileCache[key]
-	if ok {
-		return val()
-	}
-
+func (s *Syncer) compile(ctx context.Context, body []byte, compact bool) (string, []byte, error) {
 	base64Script, err := s.compileRaw(ctx, body, compact)
 	if err != nil {
-		return "", nil, 0, fmt.Errorf("s.compileRaw: %w", err)
+		return "", nil, fmt.Errorf("s.compileRaw: %w", err)
 	}
 
 	scriptBytes, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(base64Script, "base64:"))
 	if err != nil {
-		return "", nil, 0, fmt.Errorf("base64.StdEncoding.DecodeString: %w", err)
+		return "", nil, fmt.Errorf("base64.StdEncoding.DecodeString: %w", err)
 	}
-	setScriptFee := tools.CalcSetScriptFee(scriptBytes)
 
-	res := func() (string, []byte, uint64, error) {
-		return base64Script, scriptBytes, setScriptFee, nil
-	}
-	s.compileCache[key] = res
-	return res()
+	return base64Script, scriptBytes, nil
 }
 
-func (s *Syncer) getScript(ctx context.Context, addr proto.Address) (string, error) {
-	type withScript struct {
-		Script *string `json:"script"`
-	}
-
-	req, err := http.NewRequestWithContext(
-		ctx, http.MethodGet,
-		s.rawClient.GetOptions().BaseUrl+"/addresses/scriptInfo/"+addr.String(),
-		nil,
-	)
-	if err != nil {
-		return "", fmt.Errorf("http.NewRequestWithContext: %w", err)
-	}
-
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("http.DefaultClient.Do: %w", err)
-	}
-
-	body, err := io.ReadAll(res.Body)
+// setScriptTx returns unsigned setScript transaction with fee estimated by the node
+func (s *Syncer) setScriptTx(ctx context.Context, pub crypto.PublicKey, script []byte) (*proto.SetScriptWithProofs, error) {
+	tx := proto.NewUnsignedSetScriptWithProofs(2, pub, script, 0, tools.Timestamp())
+	err := fee.Set(ctx, s.node, tx)
 	if err != nil {
-		return "", fmt.Errorf("io.ReadAll: %w", err)
+		return nil, fmt.Errorf("fee.Set: %w", err)
 	}
-
-	if res.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("http.DefaultClient.Do status not 200, body: %s", string(body))
-	}
-
-	var info withScript
-	err = json.Unmarshal(body, &info)
-	if err != nil {
-		return "", fmt.Errorf("json.Unmarshal: %w", err)
-	}
-
-	if info.Script == nil {
-		return "", nil
-	}
-
-	return *info.Script, nil
+	return tx, nil
 }
 
-func (s *Syncer) apiCompactScript(c context.Context, body io.Reader) (string, error) {
-	u := fmt.Sprintf("%s/utils/script/compileCode?compact=true", s.rawClient.GetOptions().BaseUrl)
-
-	req, err := http.NewRequestWithContext(c, http.MethodPost, u, body)
-	if err != nil {
-		return "", fmt.Errorf("http.NewRequestWithContext: %w", err)
-	}
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "text/plain")
-
-	type CompileResult struct {
-		Script               string
-		Complexity           int
-		VerifierComplexity   int
-		CallableComplexities interface{}
-		ExtraFee             int
-	}
-
-	var compileResult CompileResult
-	_, err = s.client().Do(c, req, &compileResult)
-
+// transferTx returns unsigned transfer of WAVES from fee account with fee estimated by the node
+func (s *Syncer) transferTx(ctx context.Context, to proto.WavesAddress, amount uint64) (*proto.TransferWithProofs, error) {
+	tx := proto.NewUnsignedTransferWithProofs(
+		3,
+		s.feePub,
+		proto.NewOptionalAssetWaves(),
+		proto.NewOptionalAssetWaves(),
+		tools.Timestamp(),
+		amount,
+		0,
+		proto.NewRecipientFromAddress(to),
+		nil,
+	)
+	err := fee.Set(ctx, s.node, tx)
 	if err != nil {
-		return "", fmt.Errorf("s.client().Do: %w", err)
+		return nil, fmt.Errorf("fee.Set: %w", err)
 	}
-
-	return compileResult.Script, nil
+	return tx, nil
 }
 
-func (s *Syncer) apiDecompileScript(c context.Context, body io.Reader) (string, error) {
-	u := fmt.Sprintf("%s/utils/script/decompile", s.rawClient.GetOptions().BaseUrl)
-
-	req, err := http.NewRequestWithContext(c, http.MethodPost, u, body)
-	if err != nil {
-		return "", fmt.Errorf("http.NewRequestWithContext: %w", err)
-	}
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "text/plain")
-
-	type DecompileResult struct {
-		Script string
-	}
-
-	var decompileResult DecompileResult
-	_, err = s.client().Do(c, req, &decompileResult)
-
+func (s *Syncer) getScript(ctx context.Context, addr proto.WavesAddress) (string, error) {
+	script, err := s.node.Script(ctx, addr)
 	if err != nil {
-		return "", fmt.Errorf("s.client().Do: %w", err)
+		return "", fmt.Errorf("s.node.Script: %w", err)
 	}
-
-	return decompileResult.Script, nil
-}
-
-func (s *Syncer) client() *client.Client {
-	u := s.rawClient.GetOptions().BaseUrl
-	if strings.Contains(u, "wx.network") ||
-		strings.Contains(u, "waves.exchange") {
-		return s.rawClient
-	}
-
-	s.clientMutex.Lock()
-	defer s.clientMutex.Unlock()
-	time.Sleep(2 * time.Second)
-	return s.rawClient
+	return script, nil
 }
 
 func stringIndex(i int) string {
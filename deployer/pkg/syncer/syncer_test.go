@@ -0,0 +1,392 @@
+package syncer
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/waves-exchange/contracts/deployer/pkg/branch"
+	"github.com/waves-exchange/contracts/deployer/pkg/compiler"
+	"github.com/waves-exchange/contracts/deployer/pkg/config"
+	"github.com/waves-exchange/contracts/deployer/pkg/confirm"
+	"github.com/waves-exchange/contracts/deployer/pkg/contract"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+const (
+	keyAllowedLpScriptHash       = "%s__allowedLpScriptHash"
+	keyAllowedLpStableScriptHash = "%s__allowedLpStableScriptHash"
+	feeSeed                      = "fee"
+)
+
+var sources = map[string]string{
+	"factory_v2.ride": "factory",
+	lpRide:            "lp",
+	lpStableRide:      "lp stable",
+	"pool.ride":       "pool",
+}
+
+type fixture struct {
+	nd        *node.Fake
+	contracts contract.Model
+	branches  branch.Model
+	folder    string
+	accounts  map[string]node.FakeAccount
+}
+
+// newFixture writes ride files and registers contracts of every file, testnet ones on stage 1 of dev branch
+// and pool.ride on stage 2 of another branch
+func newFixture(t *testing.T, network config.Network) fixture {
+	t.Helper()
+	ctx := context.Background()
+	scheme := proto.TestNetScheme
+	if network == config.Mainnet {
+		scheme = proto.MainNetScheme
+	}
+
+	dir := t.TempDir()
+	f := fixture{
+		nd:        node.NewFake(scheme),
+		contracts: contract.NewFileModel(filepath.Join(dir, "contracts.json")),
+		branches:  branch.NewFileModel(filepath.Join(dir, "branches.json")),
+		folder:    filepath.Join(dir, "ride"),
+		accounts:  map[string]node.FakeAccount{},
+	}
+	err := os.Mkdir(f.folder, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for file, src := range sources {
+		err = os.WriteFile(filepath.Join(f.folder, file), []byte(src), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fee, err := signer.NewSeed(feeSeed, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feeAddr, err := proto.NewAddressFromPublicKey(scheme, fee.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.nd.SetBalance(feeAddr, 1000_0000_0000)
+
+	register := func(file, tag string, stage uint32) {
+		acc := node.NewFakeAccount(scheme, tag)
+		signerAcc := node.NewFakeAccount(scheme, tag+" signer")
+		f.accounts[tag] = acc
+		basePrv, signerPrv := "", ""
+		if network == config.Testnet {
+			basePrv, signerPrv = acc.SecretKey.String(), signerAcc.SecretKey.String()
+		}
+		e := f.contracts.Create(ctx, file, stage, false, tag, acc.PublicKey.String(), basePrv, signerPrv)
+		if e != nil {
+			t.Fatal(e)
+		}
+	}
+	register("factory_v2.ride", "factory_v2", 1)
+	register(lpRide, "lp", 1)
+	register(lpStableRide, "lp_stable", 1)
+	register("pool.ride", "pool", 1)
+	if network == config.Testnet {
+		register("pool.ride", "pool2", 2)
+	}
+
+	err = f.branches.Create(ctx, "dev", config.Testnet, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.branches.Create(ctx, "other", config.Testnet, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f fixture) syncer(t *testing.T, network config.Network, branchName string, dryRun bool) *Syncer {
+	t.Helper()
+	opts := confirm.DefaultOptions()
+	opts.PollInterval = time.Millisecond
+	compare := f.accounts["lp"].Address.String()
+	s, err := NewSyncer(
+		zerolog.Nop(), network, f.nd, compiler.NewNode(f.nd), branchName,
+		f.contracts, f.branches, compare, compare, feeSeed, dryRun, opts,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.contractsFolder = f.folder
+	return s
+}
+
+// inTempDir runs the test in a temporary working directory, script diffs are written to tmp folder of it
+func inTempDir(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	err = os.Mkdir(filepath.Join(dir, "tmp"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+}
+
+// deployed sets the current script of the file as if it's deployed already
+func (f fixture) deployed(t *testing.T, tag, file string) {
+	t.Helper()
+	res, err := f.nd.Compile(context.Background(), []byte(sources[file]), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.nd.SetScript(f.accounts[tag].Address, res.Script)
+}
+
+func (f fixture) hash(t *testing.T, file string) string {
+	t.Helper()
+	res, err := f.nd.Compile(context.Background(), []byte(sources[file]), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := base64ScriptHash(res.Script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func (f fixture) script(t *testing.T, tag string) string {
+	t.Helper()
+	script, err := f.nd.Script(context.Background(), f.accounts[tag].Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func (f fixture) data(t *testing.T, tag, key string) string {
+	t.Helper()
+	e, err := f.nd.DataKey(context.Background(), f.accounts[tag].Address, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e == nil {
+		return ""
+	}
+	return e.(*proto.StringDataEntry).Value
+}
+
+// setScripts returns tags of contracts setScript is broadcast for
+func (f fixture) setScripts(t *testing.T) map[string]bool {
+	t.Helper()
+	res := map[string]bool{}
+	for _, tx := range f.nd.Broadcasts() {
+		if s, ok := tx.(*proto.SetScriptWithProofs); ok {
+			for tag, acc := range f.accounts {
+				if acc.PublicKey == s.SenderPK {
+					res[tag] = true
+				}
+			}
+		}
+	}
+	return res
+}
+
+func TestApplyChangesTestnet(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, config.Testnet)
+	f.deployed(t, "factory_v2", "factory_v2.ride")
+	f.deployed(t, "pool", "pool.ride")
+	f.nd.SetData(f.accounts["factory_v2"].Address,
+		&proto.StringDataEntry{Key: keyAllowedLpStableScriptHash, Value: f.hash(t, lpStableRide)},
+	)
+
+	err := f.syncer(t, config.Testnet, "dev", false).ApplyChanges(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// changed scripts of the branch stage only, pool2 is on the stage of another branch
+	got := f.setScripts(t)
+	for _, tag := range []string{"lp", "lp_stable"} {
+		if !got[tag] {
+			t.Errorf("script of %s isn't set", tag)
+		}
+	}
+	for _, tag := range []string{"factory_v2", "pool", "pool2"} {
+		if got[tag] {
+			t.Errorf("script of %s is set", tag)
+		}
+	}
+	if f.script(t, "pool2") != "" {
+		t.Error("contract of another branch is deployed")
+	}
+
+	if v := f.data(t, "factory_v2", keyAllowedLpScriptHash); v != f.hash(t, lpRide) {
+		t.Errorf("allowed lp script hash = %q, want %q", v, f.hash(t, lpRide))
+	}
+	for _, tx := range f.nd.Broadcasts() {
+		if d, ok := tx.(*proto.DataWithProofs); ok && d.Entries[0].GetKey() == keyAllowedLpStableScriptHash {
+			t.Error("unchanged allowed lp stable script hash is written")
+		}
+	}
+}
+
+func TestApplyChangesOtherBranch(t *testing.T) {
+	f := newFixture(t, config.Testnet)
+
+	err := f.syncer(t, config.Testnet, "feature", false).ApplyChanges(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(f.nd.Broadcasts()); n != 0 {
+		t.Errorf("branch without stage broadcast %d transactions", n)
+	}
+}
+
+func TestApplyChangesDryRun(t *testing.T) {
+	f := newFixture(t, config.Testnet)
+	f.deployed(t, "factory_v2", "factory_v2.ride")
+	f.deployed(t, "pool", "pool.ride")
+
+	s := f.syncer(t, config.Testnet, "dev", true)
+	err := s.ApplyChanges(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(f.nd.Broadcasts()); n != 0 {
+		t.Fatalf("dry-run broadcast %d transactions", n)
+	}
+
+	actions := map[string]PlanAction{}
+	topUps := map[string]int{}
+	for _, it := range s.Plan().Items {
+		if it.Action == PlanActionTransfer {
+			topUps[it.Address]++
+			continue
+		}
+		key := it.Tag + it.Key
+		if _, ok := actions[key]; ok {
+			t.Errorf("%s is planned twice", key)
+		}
+		actions[key] = it.Action
+	}
+	want := map[string]PlanAction{
+		"factory_v2" + keyAllowedLpScriptHash:       PlanActionData,
+		"factory_v2" + keyAllowedLpStableScriptHash: PlanActionData,
+		"factory_v2": PlanActionSkip,
+		"lp":         PlanActionSetScript,
+		"lp_stable":  PlanActionSetScript,
+		"pool":       PlanActionSkip,
+	}
+	if len(actions) != len(want) {
+		t.Errorf("plan = %v, want %v", actions, want)
+	}
+	for key, action := range want {
+		if actions[key] != action {
+			t.Errorf("%s action = %q, want %q", key, actions[key], action)
+		}
+	}
+
+	// the factory pays for the hash data transactions only, the top-up is planned once
+	if n := topUps[f.accounts["factory_v2"].Address.String()]; n != 1 {
+		t.Errorf("factory top-up is planned %d times, want 1", n)
+	}
+}
+
+func TestApplyChangesMainnet(t *testing.T) {
+	ctx := context.Background()
+	inTempDir(t)
+	f := newFixture(t, config.Mainnet)
+	f.deployed(t, "factory_v2", "factory_v2.ride")
+	f.deployed(t, "lp_stable", lpStableRide)
+	f.deployed(t, "pool", "pool.ride")
+	f.nd.SetBalance(f.accounts["factory_v2"].Address, 10_0000_0000)
+	// lp script is approved before, so the new one is set by the deployer after the hash is approved
+	f.nd.SetData(f.accounts["factory_v2"].Address,
+		&proto.StringDataEntry{Key: keyAllowedLpScriptHash, Value: "old"},
+		&proto.StringDataEntry{Key: keyAllowedLpStableScriptHash, Value: f.hash(t, lpStableRide)},
+	)
+
+	s := f.syncer(t, config.Mainnet, "main", false)
+	err := s.ApplyChanges(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the factory hash is signed offline
+	items := s.Batch().Items
+	if len(items) != 1 || items[0].Tag != "factory_v2" || items[0].ScriptHash != f.hash(t, lpRide) {
+		t.Fatalf("batch = %+v, want allowed lp script hash of factory_v2", items)
+	}
+	if v := f.data(t, "factory_v2", keyAllowedLpScriptHash); v != "old" {
+		t.Error("factory data is written without signatures")
+	}
+
+	got := f.setScripts(t)
+	if len(got) != 1 || !got["lp"] {
+		t.Errorf("setScript is broadcast for %v, want lp only", got)
+	}
+	fee, err := signer.NewSeed(feeSeed, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	funded := false
+	for _, tx := range f.nd.Broadcasts() {
+		tr, ok := tx.(*proto.TransferWithProofs)
+		if ok && tr.SenderPK == fee.PublicKey() && *tr.Recipient.Address() == f.accounts["lp"].Address {
+			funded = true
+		}
+	}
+	if !funded {
+		t.Error("fee of lp setScript isn't transferred")
+	}
+}
+
+func TestApplyChangesMainnetDryRun(t *testing.T) {
+	inTempDir(t)
+	f := newFixture(t, config.Mainnet)
+	f.deployed(t, "factory_v2", "factory_v2.ride")
+	f.nd.SetBalance(f.accounts["factory_v2"].Address, 10_0000_0000)
+	f.nd.SetData(f.accounts["factory_v2"].Address,
+		&proto.StringDataEntry{Key: keyAllowedLpScriptHash, Value: "old"},
+	)
+
+	s := f.syncer(t, config.Mainnet, "main", true)
+	out := &bytes.Buffer{}
+	s.logger = zerolog.New(out)
+	err := s.ApplyChanges(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stateDiff string
+	for _, it := range s.Plan().Items {
+		if it.Action == PlanActionData && it.Key == keyAllowedLpScriptHash {
+			stateDiff = it.StateDiff
+		}
+	}
+	if !strings.Contains(stateDiff, keyAllowedLpScriptHash) {
+		t.Errorf("state diff of allowed lp script hash isn't planned: %q", stateDiff)
+	}
+	if !strings.Contains(out.String(), "state diff of factory data") {
+		t.Error("state diff isn't logged")
+	}
+}
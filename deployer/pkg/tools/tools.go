@@ -2,106 +2,61 @@ package tools
 
 import (
 	"context"
-	"encoding/binary"
-	"errors"
 	"fmt"
-	"io"
-	"math"
-	"net/http"
 	"time"
 
-	"github.com/wavesplatform/gowaves/pkg/client"
+	"github.com/waves-exchange/contracts/deployer/pkg/confirm"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
 	"github.com/wavesplatform/gowaves/pkg/crypto"
 	"github.com/wavesplatform/gowaves/pkg/proto"
 )
 
 func GetPrivateAndPublicKey(seed []byte) (privateKey crypto.SecretKey, publicKey crypto.PublicKey, err error) {
-	n := 0
-	s := seed
-	iv := make([]byte, 4)
-	binary.BigEndian.PutUint32(iv, uint32(n))
-	s = append(iv, s...)
-	accSeed, err := crypto.SecureHash(s)
-	if err != nil {
-		return crypto.SecretKey{}, crypto.PublicKey{}, err
-	}
-	return crypto.GenerateKeyPair(accSeed[:])
-}
-
-func CalcSetScriptFee(script []byte) uint64 {
-	const min = 1300000
-	base := uint64(100000)
-	additional := uint64(400000)
-	kb := math.Ceil(float64(len(script)) / float64(1000))
-	res := uint64(kb)*base + additional
-	if res < min {
-		return min
-	}
-	return res
+	return signer.KeyPair(seed, 0)
 }
 
 func Timestamp() uint64 {
 	return uint64(time.Now().UnixMilli())
 }
 
-func TrySignBroadcastWait(
+func SignBroadcastWait(
 	ctx context.Context,
 	networkByte proto.Scheme,
-	client *client.Client,
+	nd node.Node,
 	tx proto.Transaction,
-	prv []crypto.SecretKey,
+	s signer.Signer,
+	opts confirm.Options,
 ) error {
-	var errs string
-	var errsCount int
-	for _, p := range prv {
-		switch t := tx.(type) {
-		case *proto.DataWithProofs:
-			t.Proofs = nil
-		case *proto.InvokeScriptWithProofs:
-			t.Proofs = nil
-		case *proto.SetScriptWithProofs:
-			t.Proofs = nil
-		}
-		err := SignBroadcastWait(
-			ctx,
-			networkByte,
-			client,
-			tx,
-			p,
-		)
-		if err != nil {
-			errs += " \n" + err.Error()
-			errsCount += 1
-			continue
-		}
+	_, err := tx.Validate(networkByte)
+	if err != nil {
+		return fmt.Errorf("tx.Validate: %w", err)
 	}
-	if errsCount == len(prv) {
-		return errors.New("TrySignBroadcastWait: all privateKeys are invalid: " + errs)
+
+	err = signer.SignTx(ctx, s, networkByte, tx)
+	if err != nil {
+		return fmt.Errorf("signer.SignTx: %w", err)
 	}
-	// if errsCount > 0 {
-	// 	fmt.Printf("TrySignBroadcastWait: %s\n", errs)
-	// }
 
+	err = BroadcastWait(ctx, networkByte, nd, tx, opts, func(ctx context.Context, tx proto.Transaction) error {
+		return signer.SignTx(ctx, s, networkByte, tx)
+	})
+	if err != nil {
+		return fmt.Errorf("BroadcastWait: %w", err)
+	}
 	return nil
 }
 
-func SignBroadcastWait(
+// BroadcastWait broadcasts already signed tx and waits for its confirmations,
+// expired tx is signed again by rebuild if it isn't nil
+func BroadcastWait(
 	ctx context.Context,
 	networkByte proto.Scheme,
-	client *client.Client,
+	nd node.Node,
 	tx proto.Transaction,
-	prv crypto.SecretKey,
+	opts confirm.Options,
+	rebuild confirm.Rebuild,
 ) error {
-	_, err := tx.Validate(networkByte)
-	if err != nil {
-		return fmt.Errorf("tx.Validate: %w", err)
-	}
-
-	err = tx.Sign(networkByte, prv)
-	if err != nil {
-		return fmt.Errorf("tx.Sign: %w", err)
-	}
-
 	txHashBytes, err := tx.GetID(networkByte)
 	if err != nil {
 		return fmt.Errorf("tx.GetID: %w", err)
@@ -112,57 +67,28 @@ func SignBroadcastWait(
 		return fmt.Errorf("crypto.NewDigestFromBytes: %w", err)
 	}
 
-	_, e := client.Transactions.Broadcast(ctx, tx)
+	e := nd.Broadcast(ctx, tx)
 	if e != nil {
-		return fmt.Errorf("client.Transactions.Broadcast: %w", e)
+		return fmt.Errorf("nd.Broadcast: %w", e)
 	}
 
-	e = waitMined(ctx, client, txHash)
+	e = confirm.Wait(ctx, nd, tx, opts, rebuild)
 	if e != nil {
-		return fmt.Errorf("waitMined: %w", e)
+		return fmt.Errorf("confirm.Wait %s: %w", txHash, e)
 	}
 	return nil
 }
 
-func waitMined(ctx context.Context, client *client.Client, txHash crypto.Digest) error {
-	try := 0
-	for {
-		try += 1
-		_, _, err := client.Transactions.Info(ctx, txHash)
-		if err == nil {
-			return nil
-		}
-		if try >= 120 {
-			return fmt.Errorf("client.Transactions.Info: %w", err)
-		}
-		time.Sleep(1 * time.Second)
-	}
-}
-
-func CompactScript(ctx context.Context, client *client.Client, body io.Reader) (string, error) {
-	u := fmt.Sprintf("%s/utils/script/compileCode?compact=true", client.GetOptions().BaseUrl)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, body)
+// TxID calculates transaction id from its body, so it's known before the transaction is signed
+func TxID(networkByte proto.Scheme, tx proto.Transaction) (crypto.Digest, error) {
+	body, err := proto.MarshalTxBody(networkByte, tx)
 	if err != nil {
-		return "", fmt.Errorf("http.NewRequestWithContext: %w", err)
+		return crypto.Digest{}, fmt.Errorf("proto.MarshalTxBody: %w", err)
 	}
-	req.Header.Add("Accept", "application/json")
-	req.Header.Add("Content-Type", "text/plain")
-
-	type CompileResult struct {
-		Script               string
-		Complexity           int
-		VerifierComplexity   int
-		CallableComplexities interface{}
-		ExtraFee             int
-	}
-
-	var compileResult CompileResult
-	_, err = client.Do(ctx, req, &compileResult)
 
+	id, err := crypto.FastHash(body)
 	if err != nil {
-		return "", fmt.Errorf("client.Do: %w", err)
+		return crypto.Digest{}, fmt.Errorf("crypto.FastHash: %w", err)
 	}
-
-	return compileResult.Script, nil
+	return id, nil
 }
@@ -0,0 +1,69 @@
+package transport
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter is token bucket refilled with rps tokens per second up to burst
+type limiter struct {
+	rps   float64
+	burst float64
+
+	mu     *sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter returns nil limiter if rps is 0, it doesn't limit
+func newLimiter(rps float64, burst int) *limiter {
+	if rps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &limiter{
+		rps:    rps,
+		burst:  float64(burst),
+		mu:     &sync.Mutex{},
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		d := l.reserve()
+		if d == 0 {
+			return nil
+		}
+		err := sleep(ctx, d)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token or returns time to wait for it
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rps
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rps * float64(time.Second))
+}
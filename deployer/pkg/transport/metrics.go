@@ -0,0 +1,122 @@
+package transport
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointStats are counters of one endpoint, e.g. GET /addresses/data/{}/{}
+type EndpointStats struct {
+	Endpoint string `json:"endpoint"`
+	Requests int    `json:"requests"`
+	// Retries are repeated attempts after 429, 5xx and network errors
+	Retries int `json:"retries"`
+	// Errors are requests failed with network error or non-2xx status after all retries
+	Errors   int           `json:"errors"`
+	Total    time.Duration `json:"total"`
+	Max      time.Duration `json:"max"`
+	ByStatus map[int]int   `json:"byStatus"`
+}
+
+type Metrics struct {
+	mu        *sync.Mutex
+	endpoints map[string]*EndpointStats
+}
+
+func newMetrics() *Metrics {
+	return &Metrics{
+		mu:        &sync.Mutex{},
+		endpoints: map[string]*EndpointStats{},
+	}
+}
+
+func (m *Metrics) get(endpoint string) *EndpointStats {
+	s, ok := m.endpoints[endpoint]
+	if !ok {
+		s = &EndpointStats{Endpoint: endpoint, ByStatus: map[int]int{}}
+		m.endpoints[endpoint] = s
+	}
+	return s
+}
+
+func (m *Metrics) retry(endpoint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(endpoint).Retries++
+}
+
+func (m *Metrics) done(endpoint string, status int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.get(endpoint)
+	s.ByStatus[status]++
+	if status < 200 || status > 299 {
+		s.Errors++
+	}
+	s.add(d)
+}
+
+func (m *Metrics) failed(endpoint string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.get(endpoint)
+	s.Errors++
+	s.add(d)
+}
+
+func (s *EndpointStats) add(d time.Duration) {
+	s.Requests++
+	s.Total += d
+	if d > s.Max {
+		s.Max = d
+	}
+}
+
+// Stats returns copy of counters sorted by endpoint
+func (m *Metrics) Stats() []EndpointStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res := make([]EndpointStats, 0, len(m.endpoints))
+	for _, s := range m.endpoints {
+		c := *s
+		c.ByStatus = map[int]int{}
+		for k, v := range s.ByStatus {
+			c.ByStatus[k] = v
+		}
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Endpoint < res[j].Endpoint })
+	return res
+}
+
+func (m *Metrics) String() string {
+	var b strings.Builder
+	for _, s := range m.Stats() {
+		avg := time.Duration(0)
+		if s.Requests > 0 {
+			avg = s.Total / time.Duration(s.Requests)
+		}
+		fmt.Fprintf(&b, "%-50s requests %d retries %d errors %d avg %s max %s\n",
+			s.Endpoint, s.Requests, s.Retries, s.Errors, avg.Round(time.Millisecond), s.Max.Round(time.Millisecond))
+	}
+	return b.String()
+}
+
+var wordRegexp = regexp.MustCompile(`^[a-zA-Z]+$`)
+
+// Endpoint replaces addresses, ids, keys and numbers after the first two path segments with {},
+// so requests of different accounts are counted together
+func Endpoint(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 2; i < len(segments); i++ {
+		if !wordRegexp.MatchString(segments[i]) {
+			segments[i] = "{}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
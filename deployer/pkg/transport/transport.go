@@ -0,0 +1,264 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Options struct {
+	// URLs are node base urls, the next one is used when the current one fails
+	URLs []string
+	// RPS limits requests per second to all nodes, Burst requests may be sent at once, 0 is no limit
+	RPS   float64
+	Burst int
+	// Retries is the number of retries after the first attempt of 429, 5xx and network errors,
+	// broadcast is retried only if it wasn't sent
+	Retries int
+	// Timeout limits every attempt
+	Timeout time.Duration
+	// MinBackoff doubles with every retry up to MaxBackoff, Retry-After of 429 is used if it's longer
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func DefaultOptions(urls ...string) Options {
+	return Options{
+		URLs:       urls,
+		RPS:        10,
+		Burst:      10,
+		Retries:    5,
+		Timeout:    time.Minute,
+		MinBackoff: 500 * time.Millisecond,
+		MaxBackoff: 20 * time.Second,
+	}
+}
+
+// nonIdempotent requests aren't sent again once they may have reached the node,
+// the node may apply broadcast transaction even if the response is 5xx or lost
+var nonIdempotent = map[string]bool{
+	"POST /transactions/broadcast": true,
+}
+
+// Transport sends requests of node clients with rate limit, retries and failover,
+// requests are made to the first url and redirected to the current node
+type Transport struct {
+	opts    Options
+	nodes   []*url.URL
+	base    http.RoundTripper
+	limiter *limiter
+	metrics *Metrics
+
+	mu      *sync.Mutex
+	current int
+}
+
+func New(opts Options) (*Transport, error) {
+	if len(opts.URLs) == 0 {
+		return nil, errors.New("node url required")
+	}
+
+	nodes := make([]*url.URL, 0, len(opts.URLs))
+	for _, u := range opts.URLs {
+		parsed, err := url.Parse(strings.TrimSuffix(strings.TrimSpace(u), "/"))
+		if err != nil {
+			return nil, fmt.Errorf("url.Parse: %w", err)
+		}
+		if parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid node url %q", u)
+		}
+		nodes = append(nodes, parsed)
+	}
+
+	return &Transport{
+		opts:    opts,
+		nodes:   nodes,
+		base:    http.DefaultTransport,
+		limiter: newLimiter(opts.RPS, opts.Burst),
+		metrics: newMetrics(),
+		mu:      &sync.Mutex{},
+	}, nil
+}
+
+// BaseURL is the url requests are made to, they are sent to the current node
+func (t *Transport) BaseURL() string {
+	return t.nodes[0].String()
+}
+
+func (t *Transport) Metrics() *Metrics {
+	return t.metrics
+}
+
+func (t *Transport) node() (int, *url.URL) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.current, t.nodes[t.current]
+}
+
+// failover switches to the next node unless it was already switched by concurrent request
+func (t *Transport) failover(failed int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current == failed {
+		t.current = (t.current + 1) % len(t.nodes)
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := req.Method + " " + Endpoint(strings.TrimPrefix(req.URL.Path, t.nodes[0].Path))
+	start := time.Now()
+
+	var (
+		res     *http.Response
+		lastErr error
+	)
+	for attempt := 0; attempt <= t.opts.Retries; attempt++ {
+		if attempt > 0 {
+			t.metrics.retry(endpoint)
+			err = sleep(req.Context(), t.backoff(attempt, res))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = t.limiter.wait(req.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		i, nodeURL := t.node()
+		res, lastErr = t.do(req, body, nodeURL)
+		if lastErr == nil && !retryable(res.StatusCode) {
+			t.metrics.done(endpoint, res.StatusCode, time.Since(start))
+			return res, nil
+		}
+
+		// caller's context is done, another node won't help
+		if req.Context().Err() != nil {
+			break
+		}
+		t.failover(i)
+		if nonIdempotent[endpoint] && sent(res, lastErr) {
+			break
+		}
+		if res != nil && attempt < t.opts.Retries {
+			_, _ = io.Copy(io.Discard, res.Body)
+			_ = res.Body.Close()
+		}
+	}
+
+	if lastErr != nil {
+		t.metrics.failed(endpoint, time.Since(start))
+		return nil, lastErr
+	}
+	// the last response is returned as is, the client reports its status and message
+	t.metrics.done(endpoint, res.StatusCode, time.Since(start))
+	return res, nil
+}
+
+func (t *Transport) do(req *http.Request, body []byte, nodeURL *url.URL) (*http.Response, error) {
+	ctx := req.Context()
+	var cancel context.CancelFunc = func() {}
+	if t.opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.opts.Timeout)
+	}
+
+	r := req.Clone(ctx)
+	r.URL.Scheme = nodeURL.Scheme
+	r.URL.Host = nodeURL.Host
+	r.URL.Path = nodeURL.Path + strings.TrimPrefix(req.URL.Path, t.nodes[0].Path)
+	r.URL.RawPath = ""
+	r.Host = nodeURL.Host
+	if body != nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+	}
+
+	res, err := t.base.RoundTrip(r)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("%s: %w", nodeURL.Host, err)
+	}
+	// attempt timeout is canceled when the body is read
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+func (t *Transport) backoff(attempt int, res *http.Response) time.Duration {
+	d := t.opts.MinBackoff << (attempt - 1)
+	if d > t.opts.MaxBackoff || d <= 0 {
+		d = t.opts.MaxBackoff
+	}
+	if res != nil && res.StatusCode == http.StatusTooManyRequests {
+		if s, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+			if after := time.Duration(s) * time.Second; after > d {
+				d = after
+			}
+		}
+	}
+	// jitter spreads retries of concurrent requests
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// sent reports that the request may have reached the node, it wasn't if connection failed or the node limited it
+func sent(res *http.Response, err error) bool {
+	if err != nil {
+		var opErr *net.OpError
+		return !errors.As(err, &opErr) || opErr.Op != "dial"
+	}
+	return res.StatusCode != http.StatusTooManyRequests
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer func() {
+		_ = req.Body.Close()
+	}()
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("io.ReadAll: %w", err)
+	}
+	return b, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,184 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testOptions(urls ...string) Options {
+	return Options{
+		URLs:       urls,
+		Retries:    3,
+		Timeout:    5 * time.Second,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 2 * time.Millisecond,
+	}
+}
+
+// statuses responds with the statuses in turn and with 200 after them, it counts requests
+func statuses(calls *int32, codes ...int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(calls, 1))
+		if n <= len(codes) {
+			w.WriteHeader(codes[n-1])
+			return
+		}
+		_, _ = io.WriteString(w, r.URL.Path)
+	}
+}
+
+// closedURL is url of node refusing connections
+func closedURL() string {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	return srv.URL
+}
+
+func get(t *testing.T, tr *Transport, path string) (int, string) {
+	t.Helper()
+	res, err := (&http.Client{Transport: tr}).Get(tr.BaseURL() + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, string(b)
+}
+
+func stats(tr *Transport, endpoint string) EndpointStats {
+	for _, s := range tr.Metrics().Stats() {
+		if s.Endpoint == endpoint {
+			return s
+		}
+	}
+	return EndpointStats{}
+}
+
+func TestRetry(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(statuses(&calls, http.StatusServiceUnavailable, http.StatusTooManyRequests))
+	defer srv.Close()
+
+	tr, err := New(testOptions(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, body := get(t, tr, "/blocks/height")
+	if status != http.StatusOK || body != "/blocks/height" {
+		t.Errorf("get() = %d %s, want 200 /blocks/height", status, body)
+	}
+	if calls != 3 {
+		t.Errorf("node got %d requests, want 3", calls)
+	}
+	if s := stats(tr, "GET /blocks/height"); s.Requests != 1 || s.Retries != 2 || s.Errors != 0 {
+		t.Errorf("stats = %+v, want 1 request with 2 retries", s)
+	}
+
+	// the last response is returned after all retries
+	atomic.StoreInt32(&calls, 0)
+	srv.Config.Handler = statuses(&calls, 500, 500, 500, 500, 500)
+	status, _ = get(t, tr, "/blocks/height")
+	if status != http.StatusInternalServerError {
+		t.Errorf("status after retries = %d, want 500", status)
+	}
+	if calls != 4 {
+		t.Errorf("node got %d requests, want first attempt and 3 retries", calls)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(statuses(&calls))
+	defer srv.Close()
+
+	tr, err := New(testOptions(closedURL(), srv.URL+"/node/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, body := get(t, tr, "/addresses/balance/3Mx1")
+	if status != http.StatusOK || body != "/node/addresses/balance/3Mx1" {
+		t.Errorf("get() = %d %s, want 200 from the path of the second node", status, body)
+	}
+	// the second node stays current
+	get(t, tr, "/blocks/height")
+	if calls != 2 {
+		t.Errorf("second node got %d requests, want 2", calls)
+	}
+	if s := stats(tr, "GET /addresses/balance/{}"); s.Retries != 1 {
+		t.Errorf("stats = %+v, want 1 retry after failover", s)
+	}
+	if s := stats(tr, "GET /blocks/height"); s.Retries != 0 {
+		t.Errorf("stats = %+v, want no retries", s)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(statuses(&calls, http.StatusBadGateway))
+	defer srv.Close()
+
+	tr, err := New(testOptions(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: tr}
+	res, err := client.Post(tr.BaseURL()+"/transactions/broadcast", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusBadGateway || calls != 1 {
+		t.Errorf("broadcast = %d after %d requests, want 502 without resending", res.StatusCode, calls)
+	}
+
+	// broadcast is retried if it wasn't sent, here to another node
+	var nextCalls int32
+	next := httptest.NewServer(statuses(&nextCalls))
+	defer next.Close()
+	tr, err = New(testOptions(closedURL(), next.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = (&http.Client{Transport: tr}).Post(
+		tr.BaseURL()+"/transactions/broadcast", "application/json", strings.NewReader("{}"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK || nextCalls != 1 {
+		t.Errorf("broadcast = %d after %d requests, want 200 from the second node", res.StatusCode, nextCalls)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, urls := range [][]string{nil, {"localhost:6869"}, {"http://node", "://"}} {
+		if _, err := New(testOptions(urls...)); err == nil {
+			t.Errorf("New(%v) error = nil", urls)
+		}
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"/blocks/height":                      "/blocks/height",
+		"/addresses/data/3MxyZ/%s__key":       "/addresses/data/{}/{}",
+		"/transactions/info/9aBc":             "/transactions/info/{}",
+		"/transactions/address/3MxyZ/limit/5": "/transactions/address/{}/limit/{}",
+	}
+	for path, want := range tests {
+		if got := Endpoint(path); got != want {
+			t.Errorf("Endpoint(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
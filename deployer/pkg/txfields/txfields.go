@@ -0,0 +1,84 @@
+package txfields
+
+import (
+	"fmt"
+
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Fields are pointers to common fields of transaction with proofs, they change the transaction in place
+type Fields struct {
+	Sender    crypto.PublicKey
+	Fee       *uint64
+	Timestamp *uint64
+	Proofs    **proto.ProofsV1
+	ID        **crypto.Digest
+}
+
+// Of returns fields of tx, it's the only place transaction types are listed
+func Of(tx proto.Transaction) (Fields, error) {
+	switch t := tx.(type) {
+	case *proto.DataWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	case *proto.InvokeScriptWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	case *proto.SetScriptWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	case *proto.SetAssetScriptWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	case *proto.TransferWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	case *proto.MassTransferWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	case *proto.IssueWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	case *proto.ReissueWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	case *proto.BurnWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	case *proto.SponsorshipWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	case *proto.LeaseWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	case *proto.LeaseCancelWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	case *proto.UpdateAssetInfoWithProofs:
+		return Fields{Sender: t.SenderPK, Fee: &t.Fee, Timestamp: &t.Timestamp, Proofs: &t.Proofs, ID: &t.ID}, nil
+	default:
+		return Fields{}, fmt.Errorf("unsupported transaction type %T", tx)
+	}
+}
+
+// SetFee changes the fee of tx, cached id is generated again from the new body
+func SetFee(scheme proto.Scheme, tx proto.Transaction, fee uint64) error {
+	f, err := Of(tx)
+	if err != nil {
+		return err
+	}
+	*f.Fee = fee
+	return regenerateID(scheme, tx, f)
+}
+
+// SetTimestamp changes the timestamp of tx, cached id is generated again from the new body
+func SetTimestamp(scheme proto.Scheme, tx proto.Transaction, ts uint64) error {
+	f, err := Of(tx)
+	if err != nil {
+		return err
+	}
+	*f.Timestamp = ts
+	return regenerateID(scheme, tx, f)
+}
+
+// regenerateID keeps id nil if it wasn't generated, GetID generates it when it's needed
+func regenerateID(scheme proto.Scheme, tx proto.Transaction, f Fields) error {
+	if *f.ID == nil {
+		return nil
+	}
+	*f.ID = nil
+	err := tx.GenerateID(scheme)
+	if err != nil {
+		return fmt.Errorf("tx.GenerateID: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package verifier
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/wavesplatform/gowaves/pkg/ride/ast"
+	"github.com/wavesplatform/gowaves/pkg/ride/serialization"
+)
+
+const (
+	// instanceOf is the native function match cases are compiled to
+	instanceOf = "1"
+	// getElement is the native function of list indexing, tx.proofs[i] is compiled to it
+	getElement = "401"
+)
+
+// usage is what the verifier reads, including global declarations and functions it references
+type usage struct {
+	strings map[string]bool
+	// otherProofs is set if proofs are read at other index than 0 or the index isn't constant
+	otherProofs bool
+}
+
+// verifierUsage returns what the verifier of the script reads, false if the script has no verifier
+func verifierUsage(base64Script string) (usage, bool, error) {
+	script, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(base64Script, "base64:"))
+	if err != nil {
+		return usage{}, false, fmt.Errorf("base64.StdEncoding.DecodeString: %w", err)
+	}
+	tree, err := serialization.Parse(script)
+	if err != nil {
+		return usage{}, false, fmt.Errorf("serialization.Parse: %w", err)
+	}
+	if !tree.HasVerifier() {
+		return usage{}, false, nil
+	}
+
+	w := walker{
+		declarations: map[string]ast.Node{},
+		visited:      map[string]bool{},
+		usage:        usage{strings: map[string]bool{}},
+	}
+	for _, d := range tree.Declarations {
+		switch n := d.(type) {
+		case *ast.AssignmentNode:
+			w.declarations[n.Name] = n.Expression
+		case *ast.FunctionDeclarationNode:
+			w.declarations[n.Name] = n.Body
+		}
+	}
+	w.walk(tree.Verifier)
+	return w.usage, true, nil
+}
+
+type walker struct {
+	declarations map[string]ast.Node
+	visited      map[string]bool
+	usage        usage
+	// inOrder is set in match case of Order, proofs of orders are signed by matcher, not by the deployer
+	inOrder bool
+}
+
+func (w *walker) walk(node ast.Node) {
+	switch n := node.(type) {
+	case *ast.StringNode:
+		w.usage.strings[n.Value] = true
+	case *ast.ConditionalNode:
+		w.walk(n.Condition)
+		if isOrder(n.Condition) && !w.inOrder {
+			w.inOrder = true
+			w.walk(n.TrueExpression)
+			w.inOrder = false
+		} else {
+			w.walk(n.TrueExpression)
+		}
+		w.walk(n.FalseExpression)
+	case *ast.AssignmentNode:
+		w.walk(n.Expression)
+		w.walk(n.Block)
+	case *ast.FunctionDeclarationNode:
+		w.walk(n.Body)
+		w.walk(n.Block)
+	case *ast.PropertyNode:
+		if n.Name == "proofs" && !w.inOrder {
+			w.usage.otherProofs = true
+		}
+		w.walk(n.Object)
+	case *ast.ReferenceNode:
+		w.declaration(n.Name)
+	case *ast.FunctionCallNode:
+		if firstProof(n) {
+			w.walk(n.Arguments[0].(*ast.PropertyNode).Object)
+			return
+		}
+		if f, ok := n.Function.(ast.UserFunction); ok {
+			w.declaration(f.Name())
+		}
+		for _, arg := range n.Arguments {
+			w.walk(arg)
+		}
+	}
+}
+
+// firstProof reports whether the call is tx.proofs[0]
+func firstProof(n *ast.FunctionCallNode) bool {
+	if n.Function != ast.NativeFunction(getElement) || len(n.Arguments) != 2 {
+		return false
+	}
+	p, ok := n.Arguments[0].(*ast.PropertyNode)
+	if !ok || p.Name != "proofs" {
+		return false
+	}
+	i, ok := n.Arguments[1].(*ast.LongNode)
+	return ok && i.Value == 0
+}
+
+// isOrder reports whether the condition is match case of Order
+func isOrder(n ast.Node) bool {
+	call, ok := n.(*ast.FunctionCallNode)
+	if !ok || call.Function != ast.NativeFunction(instanceOf) || len(call.Arguments) != 2 {
+		return false
+	}
+	t, ok := call.Arguments[1].(*ast.StringNode)
+	return ok && t.Value == "Order"
+}
+
+// declaration walks global declaration once, local ones are walked where they are declared.
+// Declarations referenced from match case of Order are walked once more outside of it to check proofs
+func (w *walker) declaration(name string) {
+	key := name
+	if w.inOrder {
+		key = "order " + name
+	}
+	if w.visited[key] {
+		return
+	}
+	w.visited[key] = true
+	if d, ok := w.declarations[name]; ok {
+		w.walk(d)
+	}
+}
+
+// uses reports whether any constant contains s, keys are built either as one constant or joined by __
+func uses(strs map[string]bool, s string) bool {
+	for str := range strs {
+		if strings.Contains(str, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/waves-exchange/contracts/deployer/pkg/signer"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Select returns signers the requirement needs in the order of proofs,
+// approved transaction id needs no proofs
+func (r Requirement) Select(signers []signer.Signer) ([]signer.Signer, error) {
+	if r.Kind == KindApprovedTxID {
+		return nil, nil
+	}
+
+	available := map[string]signer.Signer{}
+	for _, s := range signers {
+		available[s.PublicKey().String()] = s
+	}
+
+	var res []signer.Signer
+	for _, pub := range r.Signers {
+		s, ok := available[pub]
+		if !ok {
+			continue
+		}
+		res = append(res, s)
+		if len(res) == r.Threshold {
+			return res, nil
+		}
+	}
+
+	keys := make([]string, 0, len(signers))
+	for _, s := range signers {
+		keys = append(keys, s.PublicKey().String())
+	}
+	return nil, fmt.Errorf(
+		"%s verifier needs %d of [%s], available keys: [%s]",
+		r.Kind, r.Threshold, strings.Join(r.Signers, ", "), strings.Join(keys, ", "),
+	)
+}
+
+// Sign resolves the sender verifier and replaces proofs of unsigned transaction with proofs
+// of the signers it needs, nothing is signed if available keys aren't enough
+func Sign(
+	ctx context.Context,
+	logger zerolog.Logger,
+	nd node.Node,
+	tx proto.Transaction,
+	signers []signer.Signer,
+) error {
+	sender, err := signer.Sender(tx)
+	if err != nil {
+		return fmt.Errorf("signer.Sender: %w", err)
+	}
+
+	req, err := Resolve(ctx, nd, sender)
+	if err != nil {
+		return fmt.Errorf("Resolve: %w", err)
+	}
+	l := logger.Info().
+		Str("sender", sender.String()).
+		Str("verifier", string(req.Kind)).
+		Strs("accepted", req.Signers).
+		Int("threshold", req.Threshold)
+	if req.Vault != "" {
+		l = l.Str("vault", req.Vault)
+	}
+	l.Msg("verifier resolved")
+
+	chosen, err := req.Select(signers)
+	if err != nil {
+		logger.Error().Err(err).Str("sender", sender.String()).Msg("no keys to sign with")
+		return fmt.Errorf("req.Select: %w", err)
+	}
+
+	err = signer.ResetProofs(tx)
+	if err != nil {
+		return fmt.Errorf("signer.ResetProofs: %w", err)
+	}
+	if len(chosen) == 0 {
+		logger.Info().Str("sender", sender.String()).Msg("transaction id must be approved, no proofs added")
+		return nil
+	}
+
+	for i, s := range chosen {
+		err = signer.SignTx(ctx, s, nd.Scheme(), tx)
+		if err != nil {
+			return fmt.Errorf("signer.SignTx %s: %w", s.PublicKey().String(), err)
+		}
+		logger.Info().Int("proof", i).Str("publicKey", s.PublicKey().String()).Msg("signed")
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+)
+
+// Kind is the way account verifier authorizes transactions
+type Kind string
+
+const (
+	// KindSender is account without script or verifier, or verifier checking the first proof is sender signature:
+	// factory.ride, manager_vault.ride and l2mp contracts with fewer admins than quorum, contracts with manager not set
+	KindSender Kind = "sender"
+	// KindManager is contract verifier checking the first proof is signed by manager public key
+	// stored in manager_vault.ride or in the contract itself
+	KindManager Kind = "manager"
+	// KindApprovedTxID is verifier of manager_vault.ride or l2mp contracts with quorum of admins,
+	// transaction id must be approved by admins votes and no proofs are needed
+	KindApprovedTxID Kind = "approvedTxId"
+)
+
+const (
+	sep                    = "__"
+	keyManagerPublicKey    = "%s__managerPublicKey"
+	keyManagerVaultAddress = "%s__managerVaultAddress"
+	keyFactoryContract     = "%s__factoryContract"
+	keyAdminAddressList    = "%s__adminAddressList"
+)
+
+// approvals are verifiers accepting transaction id voted by admins, they are recognized by the key of the id
+var approvals = []struct {
+	// txIDKey is the key the verifier reads, it's built from %s and the constant
+	txIDKey  string
+	constant string
+	// quorum is admin list size the approval is required from, sender signature is checked with fewer admins
+	quorum int
+}{
+	// manager_vault.ride, MIN_ADMIN_LIST_SIZE
+	{txIDKey: "%s__TXID", constant: "TXID", quorum: 2},
+	// l2mp_staking.ride, l2mp_leasing.ride, l2mp_swap.ride and wxdao_factory.ride, QUORUM
+	{txIDKey: "%s__txId", constant: "txId", quorum: 3},
+}
+
+// Requirement describes proofs the account verifier needs
+type Requirement struct {
+	Kind Kind `json:"kind"`
+	// Signers are base58 public keys, Threshold of them must sign the transaction.
+	// Threshold is 1, verifiers reading more proofs than the first one are rejected by Resolve
+	Signers   []string `json:"signers,omitempty"`
+	Threshold int      `json:"threshold"`
+	// Vault is the address manager public key or approved transaction id is read from
+	Vault string `json:"vault,omitempty"`
+	// TxIDKey is the key of approved transaction id at Vault
+	TxIDKey string `json:"txIdKey,omitempty"`
+}
+
+// Resolve reads the sender script and state and finds out what its verifier requires.
+// Verifiers of contracts in ride folder are recognized by data keys they read
+func Resolve(ctx context.Context, nd node.Node, sender crypto.PublicKey) (Requirement, error) {
+	addr, err := proto.NewAddressFromPublicKey(nd.Scheme(), sender)
+	if err != nil {
+		return Requirement{}, fmt.Errorf("proto.NewAddressFromPublicKey: %w", err)
+	}
+
+	senderOnly := Requirement{
+		Kind:      KindSender,
+		Signers:   []string{sender.String()},
+		Threshold: 1,
+	}
+
+	script, err := nd.Script(ctx, addr)
+	if err != nil {
+		return Requirement{}, fmt.Errorf("nd.Script: %w", err)
+	}
+	if script == "" {
+		return senderOnly, nil
+	}
+
+	u, ok, err := verifierUsage(script)
+	if err != nil {
+		return Requirement{}, fmt.Errorf("verifierUsage: %w", err)
+	}
+	if !ok {
+		return senderOnly, nil
+	}
+	if u.otherProofs {
+		return Requirement{}, fmt.Errorf(
+			"verifier of %s reads proofs other than tx.proofs[0], multisig verifiers aren't supported", addr.String(),
+		)
+	}
+	strs := u.strings
+
+	if uses(strs, "adminAddressList") {
+		for _, a := range approvals {
+			if !strs[a.constant] && !strs[a.txIDKey] {
+				continue
+			}
+			admins, e := getString(ctx, nd, addr, keyAdminAddressList)
+			if e != nil {
+				return Requirement{}, fmt.Errorf("getString: %w", e)
+			}
+			if admins != "" && len(strings.Split(admins, sep)) >= a.quorum {
+				return Requirement{
+					Kind:    KindApprovedTxID,
+					Vault:   addr.String(),
+					TxIDKey: a.txIDKey,
+				}, nil
+			}
+			return senderOnly, nil
+		}
+	}
+
+	// verifier doesn't read manager, so it checks sender signature or doesn't check proofs at all
+	if !uses(strs, "managerPublicKey") {
+		return senderOnly, nil
+	}
+
+	vault, err := managerVault(ctx, nd, addr)
+	if err != nil {
+		return Requirement{}, fmt.Errorf("managerVault: %w", err)
+	}
+
+	manager, err := getString(ctx, nd, vault, keyManagerPublicKey)
+	if err != nil {
+		return Requirement{}, fmt.Errorf("getString: %w", err)
+	}
+	if manager == "" {
+		return senderOnly, nil
+	}
+
+	managerPub, err := crypto.NewPublicKeyFromBase58(manager)
+	if err != nil {
+		return Requirement{}, fmt.Errorf("crypto.NewPublicKeyFromBase58: %w", err)
+	}
+	return Requirement{
+		Kind:      KindManager,
+		Signers:   []string{managerPub.String()},
+		Threshold: 1,
+		Vault:     vault.String(),
+	}, nil
+}
+
+// managerVault is the address from the contract or its factory, the contract itself if not set
+func managerVault(ctx context.Context, nd node.Node, addr proto.WavesAddress) (proto.WavesAddress, error) {
+	holder := addr
+	factory, err := getString(ctx, nd, addr, keyFactoryContract)
+	if err != nil {
+		return proto.WavesAddress{}, fmt.Errorf("getString: %w", err)
+	}
+	if factory != "" {
+		factoryAddr, e := proto.NewAddressFromString(factory)
+		if e == nil {
+			holder = factoryAddr
+		}
+	}
+
+	for _, a := range []proto.WavesAddress{addr, holder} {
+		vault, e := getString(ctx, nd, a, keyManagerVaultAddress)
+		if e != nil {
+			return proto.WavesAddress{}, fmt.Errorf("getString: %w", e)
+		}
+		if vault == "" {
+			continue
+		}
+		vaultAddr, e := proto.NewAddressFromString(vault)
+		if e != nil {
+			return proto.WavesAddress{}, fmt.Errorf("proto.NewAddressFromString: %w", e)
+		}
+		return vaultAddr, nil
+	}
+	return addr, nil
+}
+
+// Collected counts signers of the requirement with a proof of tx at any position,
+// Arrange moves the proof of the signer first before broadcast
+func (r Requirement) Collected(
+	ctx context.Context,
+	nd node.Node,
+	tx proto.Transaction,
+	proofs *proto.ProofsV1,
+) (int, bool, error) {
+	switch r.Kind {
+	case KindApprovedTxID:
+		vault, err := proto.NewAddressFromString(r.Vault)
+		if err != nil {
+			return 0, false, fmt.Errorf("proto.NewAddressFromString: %w", err)
+		}
+		approved, err := getString(ctx, nd, vault, r.TxIDKey)
+		if err != nil {
+			return 0, false, fmt.Errorf("getString: %w", err)
+		}
+		id, err := tx.GetID(nd.Scheme())
+		if err != nil {
+			return 0, false, fmt.Errorf("tx.GetID: %w", err)
+		}
+		return 0, approved == proto.B58Bytes(id).String(), nil
+
+	case KindSender, KindManager:
+		found, err := r.signed(nd.Scheme(), tx, proofs)
+		if err != nil {
+			return 0, false, err
+		}
+		return len(found), len(found) >= r.Threshold, nil
+
+	default:
+		return 0, false, errors.New("unknown verifier kind: " + string(r.Kind))
+	}
+}
+
+// Arrange moves the proof of the first signer found to the first position,
+// supported verifiers check tx.proofs[0] only, Resolve rejects ones reading other proofs.
+// Proofs aren't a part of the body, so the transaction id doesn't change
+func (r Requirement) Arrange(scheme proto.Scheme, tx proto.Transaction, proofs *proto.ProofsV1) error {
+	if r.Kind != KindSender && r.Kind != KindManager {
+		return nil
+	}
+	found, err := r.signed(scheme, tx, proofs)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 || found[0] == 0 {
+		return nil
+	}
+
+	i := found[0]
+	arranged := append([]proto.B58Bytes{proofs.Proofs[i]}, proofs.Proofs[:i]...)
+	proofs.Proofs = append(arranged, proofs.Proofs[i+1:]...)
+	return nil
+}
+
+// signed returns positions of proofs made by signers of the requirement, one per signer in the order of signers
+func (r Requirement) signed(scheme proto.Scheme, tx proto.Transaction, proofs *proto.ProofsV1) ([]int, error) {
+	if proofs == nil {
+		return nil, nil
+	}
+	body, err := proto.MarshalTxBody(scheme, tx)
+	if err != nil {
+		return nil, fmt.Errorf("proto.MarshalTxBody: %w", err)
+	}
+
+	var res []int
+	for _, s := range r.Signers {
+		pub, e := crypto.NewPublicKeyFromBase58(s)
+		if e != nil {
+			return nil, fmt.Errorf("crypto.NewPublicKeyFromBase58: %w", e)
+		}
+		for i, p := range proofs.Proofs {
+			sig, e := crypto.NewSignatureFromBytes(p)
+			if e != nil {
+				continue
+			}
+			if crypto.Verify(pub, sig, body) {
+				res = append(res, i)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+func getString(ctx context.Context, nd node.Node, addr proto.WavesAddress, key string) (string, error) {
+	entry, err := nd.DataKey(ctx, addr, key)
+	if err != nil {
+		return "", fmt.Errorf("nd.DataKey: %w", err)
+	}
+	if entry == nil {
+		return "", nil
+	}
+	s, ok := entry.(*proto.StringDataEntry)
+	if !ok {
+		return "", fmt.Errorf("unexpected value of %s at %s", key, addr.String())
+	}
+	return s.Value, nil
+}
@@ -0,0 +1,366 @@
+package verifier
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/waves-exchange/contracts/deployer/pkg/node"
+	"github.com/wavesplatform/gowaves/pkg/crypto"
+	"github.com/wavesplatform/gowaves/pkg/proto"
+	"github.com/wavesplatform/gowaves/pkg/ride/compiler"
+)
+
+const header = `{-# STDLIB_VERSION 6 #-}
+{-# CONTENT_TYPE DAPP #-}
+{-# SCRIPT_TYPE ACCOUNT #-}
+`
+
+// managerScript reads manager key through functions the way contracts of ride folder do
+const managerScript = header + `
+func keyManagerPublicKey() = makeString(["%s", "managerPublicKey"], "__")
+
+func managerPublicKeyOrUnit() = match getString(keyManagerPublicKey()) {
+  case s: String => fromBase58String(s)
+  case _: Unit => unit
+}
+
+@Verifier(tx)
+func verify() = {
+  let targetPublicKey = match managerPublicKeyOrUnit() {
+    case pk: ByteVector => pk
+    case _: Unit => tx.senderPublicKey
+  }
+  sigVerify(tx.bodyBytes, tx.proofs[0], targetPublicKey)
+}
+`
+
+// approvalScript is verifier of l2mp contracts, sender signature is checked until there is a quorum of admins
+const approvalScript = header + `
+let QUORUM = 3
+let SEP = "__"
+
+func keyAdminAddressList() = makeString(["%s", "adminAddressList"], SEP)
+func keyAllowTxId() = makeString(["%s", "txId"], SEP)
+
+@Verifier(tx)
+func verify() = {
+  let admins = getString(keyAdminAddressList()).valueOrElse("")
+  if (admins == "" || size(split(admins, SEP)) < QUORUM)
+    then sigVerify(tx.bodyBytes, tx.proofs[0], tx.senderPublicKey)
+    else getString(keyAllowTxId()).valueOrElse("") == toBase58String(tx.id)
+}
+`
+
+// vaultScript is verifier of manager_vault.ride, it reads manager key too
+const vaultScript = header + `
+let MIN_ADMIN_LIST_SIZE = 2
+
+func keyManagerPublicKey() = "%s__managerPublicKey"
+func keyAdminAddressList() = "%s__adminAddressList"
+func keyAllowedTxId() = "%s__TXID"
+
+@Verifier(tx)
+func verify() = {
+  let admins = getString(keyAdminAddressList()).valueOrElse("")
+  if (admins != "" && size(split(admins, "__")) >= MIN_ADMIN_LIST_SIZE)
+    then getString(keyAllowedTxId()).valueOrElse("") == toBase58String(tx.id)
+    else match getString(keyManagerPublicKey()) {
+      case pk: String => sigVerify(tx.bodyBytes, tx.proofs[0], fromBase58String(pk))
+      case _ => sigVerify(tx.bodyBytes, tx.proofs[0], tx.senderPublicKey)
+    }
+}
+`
+
+// multisigScript checks proofs of two owners
+const multisigScript = header + `
+let owners = [base58'%s', base58'%s']
+
+@Verifier(tx)
+func verify() = {
+  let first = if (sigVerify(tx.bodyBytes, tx.proofs[0], owners[0])) then 1 else 0
+  let second = if (sigVerify(tx.bodyBytes, tx.proofs[1], owners[1])) then 1 else 0
+  first + second == 2
+}
+`
+
+// orderScript checks the matcher proof of orders the way lp.ride does, proofs of transactions are checked as usual
+const orderScript = header + `
+let matcher = base58'%s'
+
+@Verifier(tx)
+func verify() = match tx {
+  case order: Order => sigVerify(order.bodyBytes, order.proofs[1], matcher)
+  case _ => sigVerify(tx.bodyBytes, tx.proofs[0], tx.senderPublicKey)
+}
+`
+
+// anyProofScript reads proofs at index that isn't constant
+const anyProofScript = header + `
+func signed(proofs: List[ByteVector], i: Int, body: ByteVector, pk: ByteVector) = sigVerify(body, proofs[i], pk)
+
+@Verifier(tx)
+func verify() = signed(tx.proofs, 0, tx.bodyBytes, tx.senderPublicKey)
+  || signed(tx.proofs, 1, tx.bodyBytes, tx.senderPublicKey)
+`
+
+// callableScript has no verifier, the sender signature is checked by the node
+const callableScript = header + `
+@Callable(i)
+func call() = [StringEntry("%s__key", "value")]
+`
+
+func setScript(t *testing.T, nd *node.Fake, addr proto.WavesAddress, src string) {
+	t.Helper()
+	script, errs := compiler.Compile(src, false, false)
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	nd.SetScript(addr, "base64:"+base64.StdEncoding.EncodeToString(script))
+}
+
+func TestResolve(t *testing.T) {
+	ctx := context.Background()
+	scheme := proto.TestNetScheme
+	contract := node.NewFakeAccount(scheme, "contract")
+	manager := node.NewFakeAccount(scheme, "manager")
+	vault := node.NewFakeAccount(scheme, "vault")
+
+	admins := func(n int) string {
+		list := ""
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				list += sep
+			}
+			list += node.NewFakeAccount(scheme, string(rune('a'+i))).Address.String()
+		}
+		return list
+	}
+
+	tests := []struct {
+		name   string
+		script string
+		data   []proto.DataEntry
+		vault  []proto.DataEntry
+		want   Requirement
+	}{
+		{
+			name: "no script",
+			want: Requirement{Kind: KindSender, Signers: []string{contract.PublicKey.String()}, Threshold: 1},
+		},
+		{
+			name:   "no verifier",
+			script: callableScript,
+			data:   []proto.DataEntry{&proto.StringDataEntry{Key: keyManagerPublicKey, Value: manager.PublicKey.String()}},
+			want:   Requirement{Kind: KindSender, Signers: []string{contract.PublicKey.String()}, Threshold: 1},
+		},
+		{
+			name:   "manager not set",
+			script: managerScript,
+			want:   Requirement{Kind: KindSender, Signers: []string{contract.PublicKey.String()}, Threshold: 1},
+		},
+		{
+			name:   "own manager",
+			script: managerScript,
+			data:   []proto.DataEntry{&proto.StringDataEntry{Key: keyManagerPublicKey, Value: manager.PublicKey.String()}},
+			want: Requirement{
+				Kind:      KindManager,
+				Signers:   []string{manager.PublicKey.String()},
+				Threshold: 1,
+				Vault:     contract.Address.String(),
+			},
+		},
+		{
+			name:   "manager vault",
+			script: managerScript,
+			data:   []proto.DataEntry{&proto.StringDataEntry{Key: keyManagerVaultAddress, Value: vault.Address.String()}},
+			vault:  []proto.DataEntry{&proto.StringDataEntry{Key: keyManagerPublicKey, Value: manager.PublicKey.String()}},
+			want: Requirement{
+				Kind:      KindManager,
+				Signers:   []string{manager.PublicKey.String()},
+				Threshold: 1,
+				Vault:     vault.Address.String(),
+			},
+		},
+		{
+			name:   "fewer admins than quorum",
+			script: approvalScript,
+			data:   []proto.DataEntry{&proto.StringDataEntry{Key: keyAdminAddressList, Value: admins(2)}},
+			want:   Requirement{Kind: KindSender, Signers: []string{contract.PublicKey.String()}, Threshold: 1},
+		},
+		{
+			name:   "admins quorum",
+			script: approvalScript,
+			data:   []proto.DataEntry{&proto.StringDataEntry{Key: keyAdminAddressList, Value: admins(3)}},
+			want:   Requirement{Kind: KindApprovedTxID, Vault: contract.Address.String(), TxIDKey: "%s__txId"},
+		},
+		{
+			name:   "manager vault with admins",
+			script: vaultScript,
+			data: []proto.DataEntry{
+				&proto.StringDataEntry{Key: keyManagerPublicKey, Value: manager.PublicKey.String()},
+				&proto.StringDataEntry{Key: keyAdminAddressList, Value: admins(2)},
+			},
+			want: Requirement{Kind: KindApprovedTxID, Vault: contract.Address.String(), TxIDKey: "%s__TXID"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nd := node.NewFake(scheme)
+			if tt.script != "" {
+				setScript(t, nd, contract.Address, tt.script)
+			}
+			nd.SetData(contract.Address, tt.data...)
+			nd.SetData(vault.Address, tt.vault...)
+
+			got, err := Resolve(ctx, nd, contract.PublicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Kind != tt.want.Kind || got.Threshold != tt.want.Threshold ||
+				got.Vault != tt.want.Vault || got.TxIDKey != tt.want.TxIDKey ||
+				len(got.Signers) != len(tt.want.Signers) {
+				t.Fatalf("Resolve() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got.Signers {
+				if got.Signers[i] != tt.want.Signers[i] {
+					t.Errorf("Signers[%d] = %s, want %s", i, got.Signers[i], tt.want.Signers[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCollected(t *testing.T) {
+	ctx := context.Background()
+	nd := node.NewFake(proto.TestNetScheme)
+	contract := node.NewFakeAccount(nd.Scheme(), "contract")
+	manager := node.NewFakeAccount(nd.Scheme(), "manager")
+
+	tx := proto.NewUnsignedDataWithProofs(2, contract.PublicKey, 500000, 1)
+	err := tx.AppendEntry(&proto.IntegerDataEntry{Key: "%s__key", Value: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := proto.MarshalTxBody(nd.Scheme(), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(a node.FakeAccount) proto.B58Bytes {
+		sig, e := crypto.Sign(a.SecretKey, body)
+		if e != nil {
+			t.Fatal(e)
+		}
+		return sig.Bytes()
+	}
+
+	senderProof, managerProof := sign(contract), sign(manager)
+
+	req := Requirement{Kind: KindManager, Signers: []string{manager.PublicKey.String()}, Threshold: 1}
+	proofs := proto.NewProofs()
+	proofs.Proofs = []proto.B58Bytes{senderProof}
+	n, ok, err := req.Collected(ctx, nd, tx, proofs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 || ok {
+		t.Errorf("Collected() = %d, %t with sender proof only, want 0, false", n, ok)
+	}
+
+	// the manager proof isn't the first one, e.g. sender signed the batch first
+	proofs.Proofs = append(proofs.Proofs, managerProof)
+	n, ok, err = req.Collected(ctx, nd, tx, proofs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || !ok {
+		t.Errorf("Collected() = %d, %t, want 1, true", n, ok)
+	}
+
+	err = req.Arrange(nd.Scheme(), tx, proofs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proofs.Proofs) != 2 || proofs.Proofs[0].String() != managerProof.String() {
+		t.Error("manager proof isn't moved first")
+	}
+	if proofs.Proofs[1].String() != senderProof.String() {
+		t.Error("sender proof is lost")
+	}
+}
+
+func TestCollectedApproved(t *testing.T) {
+	ctx := context.Background()
+	nd := node.NewFake(proto.TestNetScheme)
+	contract := node.NewFakeAccount(nd.Scheme(), "contract")
+
+	tx := proto.NewUnsignedDataWithProofs(2, contract.PublicKey, 500000, 1)
+	err := tx.AppendEntry(&proto.IntegerDataEntry{Key: "%s__key", Value: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := tx.GetID(nd.Scheme())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := Requirement{Kind: KindApprovedTxID, Vault: contract.Address.String(), TxIDKey: "%s__txId"}
+	_, ok, err := req.Collected(ctx, nd, tx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("transaction isn't approved yet")
+	}
+
+	// manager_vault.ride key isn't read for l2mp contracts
+	nd.SetData(contract.Address, &proto.StringDataEntry{Key: "%s__TXID", Value: proto.B58Bytes(id).String()})
+	_, ok, err = req.Collected(ctx, nd, tx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("transaction is approved by another key")
+	}
+
+	nd.SetData(contract.Address, &proto.StringDataEntry{Key: "%s__txId", Value: proto.B58Bytes(id).String()})
+	_, ok, err = req.Collected(ctx, nd, tx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("approved transaction isn't collected")
+	}
+}
+
+func TestResolveMultisig(t *testing.T) {
+	ctx := context.Background()
+	nd := node.NewFake(proto.TestNetScheme)
+	contract := node.NewFakeAccount(nd.Scheme(), "contract")
+	alice := node.NewFakeAccount(nd.Scheme(), "alice")
+	bob := node.NewFakeAccount(nd.Scheme(), "bob")
+
+	setScript(t, nd, contract.Address, fmt.Sprintf(orderScript, alice.PublicKey.String()))
+	req, err := Resolve(ctx, nd, contract.PublicKey)
+	if err != nil {
+		t.Fatalf("verifier checking order proofs is rejected: %v", err)
+	}
+	if req.Kind != KindSender {
+		t.Errorf("Resolve() kind = %s, want %s", req.Kind, KindSender)
+	}
+
+	for name, src := range map[string]string{
+		"second proof": fmt.Sprintf(multisigScript, alice.PublicKey.String(), bob.PublicKey.String()),
+		"any proof":    anyProofScript,
+	} {
+		t.Run(name, func(t *testing.T) {
+			setScript(t, nd, contract.Address, src)
+			_, err := Resolve(ctx, nd, contract.PublicKey)
+			if err == nil || !strings.Contains(err.Error(), "multisig") {
+				t.Errorf("Resolve() error = %v, want multisig verifier rejected", err)
+			}
+		})
+	}
+}